@@ -4,9 +4,10 @@ type Headers map[string]string
 
 // Link represents a link to a resource
 type Link struct {
-	Href  string `json:"href"`
-	Title string `json:"title"`
-	Name  string `json:"name"`
+	Href      string `json:"href"`
+	Title     string `json:"title"`
+	Name      string `json:"name"`
+	Templated bool   `json:"templated,omitempty"`
 }
 
 // HalLinks represents the _links key in a HAL document.
@@ -0,0 +1,58 @@
+package broker
+
+// Page contains the links to navigate a paginated list
+type Page struct {
+	Links struct {
+		Next *Link `json:"next,omitempty"`
+	} `json:"_links"`
+}
+
+// PacticipantsResponse is the response body for listing pacticipants
+type PacticipantsResponse struct {
+	Embedded struct {
+		Pacticipants []Pacticipant `json:"pacticipants"`
+	} `json:"_embedded"`
+	Page
+}
+
+// TeamsListResponse is the response body for listing teams
+type TeamsListResponse struct {
+	Teams []Team `json:"teams"`
+	Page
+}
+
+// UsersListResponse is the response body for listing users
+type UsersListResponse struct {
+	Users
+	Page
+}
+
+// RolesResponse is the response body for listing roles
+type RolesResponse struct {
+	Roles []Role `json:"roles"`
+	Page
+}
+
+// EnvironmentsResponse is the response body for listing environments
+type EnvironmentsResponse struct {
+	Embedded struct {
+		Environments []Environment `json:"environments"`
+	} `json:"_embedded"`
+	Page
+}
+
+// SecretsResponse is the response body for listing secrets. The UUID of each secret is only available via its self link
+type SecretsResponse struct {
+	Embedded struct {
+		Secrets []SecretResponse `json:"secrets"`
+	} `json:"_embedded"`
+	Page
+}
+
+// WebhooksResponse is the response body for listing webhooks, which only contains a link to each webhook
+type WebhooksResponse struct {
+	Links struct {
+		Webhooks []Link `json:"pb:webhooks"`
+		Next     *Link  `json:"next,omitempty"`
+	} `json:"_links"`
+}
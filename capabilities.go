@@ -0,0 +1,37 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/pactflow/terraform/client"
+)
+
+// requirePactflow fails the plan for resources that are only available on the Pactflow platform,
+// rather than waiting for the broker to reject the request during apply
+func requirePactflow(resource string) schema.CustomizeDiffFunc {
+	return func(ctx context.Context, d *schema.ResourceDiff, meta interface{}) error {
+		c, ok := meta.(client.BrokerAPI)
+		if !ok {
+			return nil
+		}
+
+		return checkPactflow(resource, c)
+	}
+}
+
+// checkPactflow returns an error if the broker was detected to be an OSS Pact Broker
+func checkPactflow(resource string, c client.BrokerAPI) error {
+	info := c.BrokerInfo()
+	if info.Flavour != client.OSSBroker {
+		return nil
+	}
+
+	version := info.Version
+	if version == "" {
+		version = "unknown"
+	}
+
+	return fmt.Errorf("%s is not supported by an OSS Pact Broker (detected version: %s). This resource is only available on the Pactflow platform", resource, version)
+}
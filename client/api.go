@@ -0,0 +1,185 @@
+package client
+
+import (
+	"context"
+	"iter"
+	"net/url"
+	"time"
+
+	"github.com/pactflow/terraform/broker"
+)
+
+// BrokerAPI is the full set of operations supported by Client. Resources depend on this interface rather than
+// on Client itself, so that they can be tested without a broker (see the clienttest package for an in-memory fake)
+type BrokerAPI interface {
+	WebhookAPI
+	PacticipantAPI
+	TeamAPI
+	RoleAPI
+	UserAPI
+	SecretAPI
+	TokenAPI
+	AuthenticationAPI
+	EnvironmentAPI
+	DiscoveryAPI
+
+	// BaseURL is the root of the broker
+	BaseURL() *url.URL
+	// BaseContext is the parent context for requests made via methods that don't accept a context
+	BaseContext() context.Context
+	// RotateAccessToken replaces the static access token, after it has been regenerated. It returns false
+	// (and has no effect) if a static access token isn't used, e.g. the token is obtained from a TokenSource
+	RotateAccessToken(token string) bool
+	// ClearCache discards any cached responses
+	ClearCache()
+}
+
+var _ BrokerAPI = (*Client)(nil)
+
+// WebhookAPI manages webhooks
+type WebhookAPI interface {
+	ReadWebhook(id string) (*broker.Webhook, error)
+	ReadWebhookWithContext(ctx context.Context, id string) (*broker.Webhook, error)
+	CreateWebhook(w broker.Webhook) (*broker.WebhookResponse, error)
+	CreateWebhookWithContext(ctx context.Context, w broker.Webhook) (*broker.WebhookResponse, error)
+	UpdateWebhook(w broker.Webhook) (*broker.WebhookResponse, error)
+	UpdateWebhookWithContext(ctx context.Context, w broker.Webhook) (*broker.WebhookResponse, error)
+	DeleteWebhook(w broker.Webhook) error
+	DeleteWebhookWithContext(ctx context.Context, w broker.Webhook) error
+	ListWebhooks(opts ListOptions) iter.Seq2[broker.Webhook, error]
+	ListWebhooksWithContext(ctx context.Context, opts ListOptions) iter.Seq2[broker.Webhook, error]
+}
+
+// PacticipantAPI manages pacticipants (applications)
+type PacticipantAPI interface {
+	ReadPacticipant(name string) (*broker.Pacticipant, error)
+	ReadPacticipantWithContext(ctx context.Context, name string) (*broker.Pacticipant, error)
+	CreatePacticipant(p broker.Pacticipant) (*broker.Pacticipant, error)
+	CreatePacticipantWithContext(ctx context.Context, p broker.Pacticipant) (*broker.Pacticipant, error)
+	UpdatePacticipant(p broker.Pacticipant) (*broker.Pacticipant, error)
+	UpdatePacticipantWithContext(ctx context.Context, p broker.Pacticipant) (*broker.Pacticipant, error)
+	DeletePacticipant(p broker.Pacticipant) error
+	DeletePacticipantWithContext(ctx context.Context, p broker.Pacticipant) error
+	ListPacticipants(opts ListOptions) iter.Seq2[broker.Pacticipant, error]
+	ListPacticipantsWithContext(ctx context.Context, opts ListOptions) iter.Seq2[broker.Pacticipant, error]
+}
+
+// TeamAPI manages teams and their members
+type TeamAPI interface {
+	ReadTeam(t broker.Team) (*broker.Team, error)
+	ReadTeamWithContext(ctx context.Context, t broker.Team) (*broker.Team, error)
+	CreateTeam(t broker.TeamCreateOrUpdateRequest) (*broker.Team, error)
+	CreateTeamWithContext(ctx context.Context, t broker.TeamCreateOrUpdateRequest) (*broker.Team, error)
+	ReadTeamAssignments(t broker.Team) (*broker.TeamsAssignmentResponse, error)
+	ReadTeamAssignmentsWithContext(ctx context.Context, t broker.Team) (*broker.TeamsAssignmentResponse, error)
+	UpdateTeamAssignments(r broker.TeamsAssignmentRequest) (*broker.TeamsAssignmentResponse, error)
+	UpdateTeamAssignmentsWithContext(ctx context.Context, r broker.TeamsAssignmentRequest) (*broker.TeamsAssignmentResponse, error)
+	AppendTeamAssignments(r broker.TeamsAssignmentRequest) (*broker.TeamsAssignmentResponse, error)
+	AppendTeamAssignmentsWithContext(ctx context.Context, r broker.TeamsAssignmentRequest) (*broker.TeamsAssignmentResponse, error)
+	DeleteTeamAssignment(t broker.Team, u broker.User) error
+	DeleteTeamAssignmentWithContext(ctx context.Context, t broker.Team, u broker.User) error
+	DeleteTeamAssignments(t broker.TeamsAssignmentRequest) error
+	DeleteTeamAssignmentsWithContext(ctx context.Context, t broker.TeamsAssignmentRequest) error
+	UpdateTeam(t broker.TeamCreateOrUpdateRequest) (*broker.Team, error)
+	UpdateTeamWithContext(ctx context.Context, t broker.TeamCreateOrUpdateRequest) (*broker.Team, error)
+	DeleteTeam(t broker.Team) error
+	DeleteTeamWithContext(ctx context.Context, t broker.Team) error
+	ListTeams(opts ListOptions) iter.Seq2[broker.Team, error]
+	ListTeamsWithContext(ctx context.Context, opts ListOptions) iter.Seq2[broker.Team, error]
+}
+
+// RoleAPI manages roles
+type RoleAPI interface {
+	ReadRole(uuid string) (*broker.Role, error)
+	ReadRoleWithContext(ctx context.Context, uuid string) (*broker.Role, error)
+	CreateRole(p broker.Role) (*broker.Role, error)
+	CreateRoleWithContext(ctx context.Context, p broker.Role) (*broker.Role, error)
+	UpdateRole(p broker.Role) (*broker.Role, error)
+	UpdateRoleWithContext(ctx context.Context, p broker.Role) (*broker.Role, error)
+	DeleteRole(p broker.Role) error
+	DeleteRoleWithContext(ctx context.Context, p broker.Role) error
+	ListRoles(opts ListOptions) iter.Seq2[broker.Role, error]
+	ListRolesWithContext(ctx context.Context, opts ListOptions) iter.Seq2[broker.Role, error]
+}
+
+// UserAPI manages users, system accounts and their roles
+type UserAPI interface {
+	ReadUser(uuid string) (*broker.User, error)
+	ReadUserWithContext(ctx context.Context, uuid string) (*broker.User, error)
+	CreateUser(u broker.User) (*broker.User, error)
+	CreateUserWithContext(ctx context.Context, u broker.User) (*broker.User, error)
+	CreateSystemAccount(u broker.User) (*broker.User, error)
+	CreateSystemAccountWithContext(ctx context.Context, u broker.User) (*broker.User, error)
+	UpdateUser(p broker.User) (*broker.User, error)
+	UpdateUserWithContext(ctx context.Context, p broker.User) (*broker.User, error)
+	DeleteUser(p broker.User) error
+	DeleteUserWithContext(ctx context.Context, p broker.User) error
+	AddAdminRoleToUser(p broker.User) (*broker.User, error)
+	AddAdminRoleToUserWithContext(ctx context.Context, p broker.User) (*broker.User, error)
+	RemoveAdminRoleFromUser(p broker.User) (*broker.User, error)
+	RemoveAdminRoleFromUserWithContext(ctx context.Context, p broker.User) (*broker.User, error)
+	SetUserRoles(uuid string, r broker.SetUserRolesRequest) error
+	SetUserRolesWithContext(ctx context.Context, uuid string, r broker.SetUserRolesRequest) error
+	ListUsers(opts ListOptions) iter.Seq2[broker.User, error]
+	ListUsersWithContext(ctx context.Context, opts ListOptions) iter.Seq2[broker.User, error]
+}
+
+// SecretAPI manages secrets
+type SecretAPI interface {
+	ReadSecret(uuid string) (*broker.SecretResponse, error)
+	ReadSecretWithContext(ctx context.Context, uuid string) (*broker.SecretResponse, error)
+	CreateSecret(s broker.Secret) (*broker.SecretResponse, error)
+	CreateSecretWithContext(ctx context.Context, s broker.Secret) (*broker.SecretResponse, error)
+	UpdateSecret(s broker.Secret) (*broker.SecretResponse, error)
+	UpdateSecretWithContext(ctx context.Context, s broker.Secret) (*broker.SecretResponse, error)
+	DeleteSecret(s broker.Secret) error
+	DeleteSecretWithContext(ctx context.Context, s broker.Secret) error
+	ListSecrets(opts ListOptions) iter.Seq2[broker.Secret, error]
+	ListSecretsWithContext(ctx context.Context, opts ListOptions) iter.Seq2[broker.Secret, error]
+}
+
+// TokenAPI manages the API tokens of the authenticated user, and reads the tokens of system accounts
+type TokenAPI interface {
+	ReadTokens() (*broker.APITokensResponse, error)
+	ReadTokensWithContext(ctx context.Context) (*broker.APITokensResponse, error)
+	ReadToken(uuid string) (*broker.APIToken, error)
+	ReadTokenWithContext(ctx context.Context, uuid string) (*broker.APIToken, error)
+	FindTokenByType(tokenType string) (*broker.APIToken, error)
+	FindTokenByTypeWithContext(ctx context.Context, tokenType string) (*broker.APIToken, error)
+	RegenerateToken(t broker.APIToken) (*broker.APITokenResponse, error)
+	RegenerateTokenWithContext(ctx context.Context, t broker.APIToken) (*broker.APITokenResponse, error)
+	ReadSystemAccountTokens(uuid string) (*broker.APITokensResponse, error)
+	ReadSystemAccountTokensWithContext(ctx context.Context, uuid string) (*broker.APITokensResponse, error)
+}
+
+// AuthenticationAPI manages the authentication settings of the tenant
+type AuthenticationAPI interface {
+	ReadTenantAuthenticationSettings() (*broker.AuthenticationSettings, error)
+	ReadTenantAuthenticationSettingsWithContext(ctx context.Context) (*broker.AuthenticationSettings, error)
+	SetTenantAuthenticationSettings(r broker.AuthenticationSettings) (*broker.AuthenticationSettings, error)
+	SetTenantAuthenticationSettingsWithContext(ctx context.Context, r broker.AuthenticationSettings) (*broker.AuthenticationSettings, error)
+}
+
+// EnvironmentAPI manages environments
+type EnvironmentAPI interface {
+	ReadEnvironment(uuid string) (*broker.Environment, error)
+	ReadEnvironmentWithContext(ctx context.Context, uuid string) (*broker.Environment, error)
+	CreateEnvironment(p broker.EnvironmentCreateOrUpdateRequest) (*broker.EnvironmentCreateOrUpdateResponse, error)
+	CreateEnvironmentWithContext(ctx context.Context, p broker.EnvironmentCreateOrUpdateRequest) (*broker.EnvironmentCreateOrUpdateResponse, error)
+	UpdateEnvironment(p broker.EnvironmentCreateOrUpdateRequest) (*broker.EnvironmentCreateOrUpdateResponse, error)
+	UpdateEnvironmentWithContext(ctx context.Context, p broker.EnvironmentCreateOrUpdateRequest) (*broker.EnvironmentCreateOrUpdateResponse, error)
+	DeleteEnvironment(p broker.Environment) error
+	DeleteEnvironmentWithContext(ctx context.Context, p broker.Environment) error
+	ListEnvironments(opts ListOptions) iter.Seq2[broker.Environment, error]
+	ListEnvironmentsWithContext(ctx context.Context, opts ListOptions) iter.Seq2[broker.Environment, error]
+}
+
+// DiscoveryAPI describes the broker and its capabilities
+type DiscoveryAPI interface {
+	Discover() error
+	DiscoverWithContext(ctx context.Context) error
+	BrokerInfo() BrokerInfo
+	HasRelation(rel string) bool
+	WaitForReady(timeout time.Duration) error
+	WaitForReadyWithContext(ctx context.Context) error
+}
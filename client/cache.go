@@ -0,0 +1,147 @@
+package client
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// readCache holds the responses to GET requests for the lifetime of a client (i.e. a single plan or apply),
+// so that refreshing many resources that share data (e.g. the list of API tokens) only fetches it once.
+// Entries are invalidated by any request that modifies the same family of resources, or one that they embed
+type readCache struct {
+	mutex   sync.Mutex
+	entries map[string]cacheEntry
+	// generation is incremented on every invalidation, so that responses to GETs that were in flight
+	// at the time of a modification are not cached
+	generation uint64
+}
+
+type cacheEntry struct {
+	family string
+	header http.Header
+	body   []byte
+}
+
+// response decodes a fresh copy of the cached body into v, so that callers can't modify the cached data
+func (e cacheEntry) response(req *http.Request, v interface{}) (*http.Response, error) {
+	resp := &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     e.header.Clone(),
+		Body:       io.NopCloser(bytes.NewReader(e.body)),
+		Request:    req,
+	}
+
+	return resp, json.Unmarshal(e.body, v)
+}
+
+func newReadCache() *readCache {
+	return &readCache{entries: make(map[string]cacheEntry)}
+}
+
+func cacheKey(method, path string) string {
+	return method + " " + path
+}
+
+// resourceFamily groups the paths of a collection of resources, e.g. /admin/users/2 and /admin/users/2/roles, by the
+// first segment of the path relative to the broker root, or the first two for the collections under /admin
+func resourceFamily(path string) string {
+	path = strings.TrimPrefix(path, "/")
+	if i := strings.IndexByte(path, '?'); i >= 0 {
+		path = path[:i]
+	}
+
+	segments := 1
+	if strings.HasPrefix(path, "admin/") {
+		segments = 2
+	}
+	parts := strings.SplitN(path, "/", segments+1)
+	if len(parts) > segments {
+		parts = parts[:segments]
+	}
+
+	return "/" + strings.Join(parts, "/")
+}
+
+// embeddedIn lists the families whose responses embed the data of another family, e.g. a webhook embeds its
+// pacticipants and a user embeds their roles and teams, so they are also invalidated when it is modified
+var embeddedIn = map[string][]string{
+	"/pacticipants":          {"/webhooks", "/admin/teams"},
+	"/environments":          {"/admin/teams"},
+	"/admin/teams":           {"/admin/users", "/admin/system-accounts"},
+	"/admin/users":           {"/admin/teams", "/admin/system-accounts"},
+	"/admin/system-accounts": {"/admin/teams", "/admin/users"},
+	"/admin/roles":           {"/admin/users", "/admin/system-accounts"},
+	"/settings":              {"/admin/system-accounts"},
+}
+
+// invalidatedFamilies returns the families invalidated by a modification to the given family
+func invalidatedFamilies(family string) []string {
+	return append([]string{family}, embeddedIn[family]...)
+}
+
+func (r *readCache) get(key string) (cacheEntry, bool) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	e, ok := r.entries[key]
+	return e, ok
+}
+
+// begin returns the current generation, to be given to put once the response is available
+func (r *readCache) begin() uint64 {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	return r.generation
+}
+
+func (r *readCache) put(key, family string, generation uint64, header http.Header, body []byte) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if generation != r.generation {
+		return
+	}
+	r.entries[key] = cacheEntry{family: family, header: header.Clone(), body: body}
+}
+
+func (r *readCache) invalidate(families ...string) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.generation++
+	for k, e := range r.entries {
+		if slices.Contains(families, e.family) {
+			delete(r.entries, k)
+		}
+	}
+}
+
+func (r *readCache) clear() {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.generation++
+	r.entries = make(map[string]cacheEntry)
+}
+
+// ClearCache discards all cached responses. It has no effect if Config.CacheReads is not enabled
+func (c *Client) ClearCache() {
+	if c.cache != nil {
+		c.cache.clear()
+	}
+}
+
+// cachePath returns the path of the request relative to the broker root, used to key and group cache entries
+func (c *Client) cachePath(req *http.Request) string {
+	if path, err := c.relativePath(req.URL.String()); err == nil {
+		return path
+	}
+
+	return req.URL.RequestURI()
+}
@@ -0,0 +1,153 @@
+package client
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/pactflow/terraform/broker"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReadCache(t *testing.T) {
+	t.Run("fetches each path once", func(t *testing.T) {
+		requests := map[string]int{}
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requests[r.Method+" "+r.URL.Path]++
+			w.Header().Set("Content-Type", "application/hal+json")
+			w.Write([]byte(`{"_embedded":{"items":[{"uuid":"1","description":"Read/write token"},{"uuid":"2","description":"Read only token (developer)"}]}}`))
+		}))
+		defer server.Close()
+
+		c := clientForTest(server, 0)
+		c.cache = newReadCache()
+
+		t1, err := c.ReadToken("1")
+		assert.NoError(t, err)
+		t2, err := c.ReadToken("2")
+		assert.NoError(t, err)
+		_, err = c.FindTokenByType("read-only")
+		assert.NoError(t, err)
+
+		assert.Equal(t, "1", t1.UUID)
+		assert.Equal(t, "2", t2.UUID)
+		assert.Equal(t, 1, requests["GET /settings/tokens"])
+	})
+
+	t.Run("invalidates the cache when the same family of resources is modified", func(t *testing.T) {
+		requests := map[string]int{}
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requests[r.Method+" "+r.URL.Path]++
+			w.Header().Set("Content-Type", "application/hal+json")
+			w.Write([]byte(`{"uuid":"1234","name":"my-team"}`))
+		}))
+		defer server.Close()
+
+		c := clientForTest(server, 0)
+		c.cache = newReadCache()
+
+		_, err := c.ReadTeam(broker.Team{UUID: "1234"})
+		assert.NoError(t, err)
+		_, err = c.ReadPacticipant("terraform-client")
+		assert.NoError(t, err)
+
+		err = c.DeleteUser(broker.User{UUID: "5678"})
+		assert.NoError(t, err)
+
+		_, err = c.ReadTeam(broker.Team{UUID: "1234"})
+		assert.NoError(t, err)
+		_, err = c.ReadPacticipant("terraform-client")
+		assert.NoError(t, err)
+
+		assert.Equal(t, 2, requests["GET /admin/teams/1234"])
+		assert.Equal(t, 1, requests["GET /pacticipants/terraform-client"])
+	})
+
+	t.Run("keeps other collections under /admin when one is modified", func(t *testing.T) {
+		requests := map[string]int{}
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requests[r.Method+" "+r.URL.Path]++
+			w.Header().Set("Content-Type", "application/hal+json")
+			w.Write([]byte(`{"uuid":"1234","name":"CI"}`))
+		}))
+		defer server.Close()
+
+		c := clientForTest(server, 0)
+		c.cache = newReadCache()
+
+		_, err := c.ReadRole("1234")
+		assert.NoError(t, err)
+
+		_, err = c.UpdateTeam(broker.TeamCreateOrUpdateRequest{UUID: "5678", Name: "Platform"})
+		assert.NoError(t, err)
+
+		_, err = c.ReadRole("1234")
+		assert.NoError(t, err)
+
+		assert.Equal(t, 1, requests["GET /admin/roles/1234"])
+	})
+
+	t.Run("invalidates webhooks when a pacticipant is modified", func(t *testing.T) {
+		requests := map[string]int{}
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requests[r.Method+" "+r.URL.Path]++
+			w.Header().Set("Content-Type", "application/hal+json")
+			w.Write([]byte(`{"name":"terraform-client","description":"Trigger build"}`))
+		}))
+		defer server.Close()
+
+		c := clientForTest(server, 0)
+		c.cache = newReadCache()
+
+		_, err := c.ReadWebhook("1234")
+		assert.NoError(t, err)
+
+		err = c.DeletePacticipant(broker.Pacticipant{Name: "terraform-client"})
+		assert.NoError(t, err)
+
+		_, err = c.ReadWebhook("1234")
+		assert.NoError(t, err)
+
+		assert.Equal(t, 2, requests["GET /webhooks/1234"])
+	})
+
+	t.Run("is disabled by default", func(t *testing.T) {
+		requests := 0
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requests++
+			w.Write([]byte(`{"name":"terraform-client"}`))
+		}))
+		defer server.Close()
+
+		c := clientForTest(server, 0)
+		c.ReadPacticipant("terraform-client")
+		c.ReadPacticipant("terraform-client")
+
+		assert.Equal(t, 2, requests)
+	})
+
+	t.Run("returns copies of the cached responses", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(`{"name":"terraform-client"}`))
+		}))
+		defer server.Close()
+
+		c := clientForTest(server, 0)
+		c.cache = newReadCache()
+
+		first, _ := c.ReadPacticipant("terraform-client")
+		first.Name = "modified"
+		second, _ := c.ReadPacticipant("terraform-client")
+
+		assert.Equal(t, "terraform-client", second.Name)
+	})
+}
+
+func TestResourceFamily(t *testing.T) {
+	assert.Equal(t, "/admin/teams", resourceFamily("/admin/teams/1234/users"))
+	assert.Equal(t, "/admin/users", resourceFamily("/admin/users?pageSize=10"))
+	assert.Equal(t, "/admin", resourceFamily("/admin"))
+	assert.Equal(t, "/pacticipants", resourceFamily("/pacticipants/foo"))
+	assert.Equal(t, "/secrets", resourceFamily("/secrets?pageSize=10"))
+	assert.Equal(t, "/", resourceFamily("/"))
+}
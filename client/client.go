@@ -2,6 +2,7 @@ package client
 
 import (
 	"bytes"
+	"context"
 	"crypto/tls"
 	"encoding/json"
 	"fmt"
@@ -11,6 +12,8 @@ import (
 	"net/http"
 	"net/url"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/pactflow/terraform/broker"
 	"github.com/pactflow/terraform/version"
@@ -40,9 +43,12 @@ const (
 	secretCreateTemplate                = "/secrets"
 	listTokensTemplate                  = "/settings/tokens"
 	tokenRegenerateTemplate             = "/settings/tokens/%s/regenerate"
+	systemAccountTokensTemplate         = "/admin/system-accounts/%s/tokens"
 	metadataTemplate                    = "/"
+	heartbeatTemplate                   = "/diagnostic/status/heartbeat"
 	environmentCreateTemplate           = "/environments"
 	environmentReadUpdateDeleteTemplate = "/environments/%s"
+	badgeTemplate                       = "/pacts/provider/%s/consumer/%s/latest/badge"
 )
 
 const (
@@ -57,11 +63,45 @@ var tokenTypes = map[string]string{
 
 // Config is the primary means to modify the Pact Broker http client
 type Config struct {
+	// AccessToken is a static bearer token. Use TokenSource for tokens that are rotated
 	AccessToken       string
 	BasicAuthUsername string
 	BasicAuthPassword string
 	BaseURL           *url.URL
-	CustomTLSConfig   *tls.Config
+	// TokenSource supplies the bearer token for each request, and takes precedence over AccessToken
+	TokenSource TokenSource
+	// ReadAccessToken is an optional bearer token used for requests that only read from the broker (e.g. GET)
+	ReadAccessToken string
+	// ReadOnly rejects any request that could modify the broker before it is sent
+	ReadOnly bool
+
+	// CustomTLSConfig configures TLS for the broker connection. See NewTLSConfig
+	CustomTLSConfig *tls.Config
+
+	// ProxyURL is the HTTP proxy to send requests via. Defaults to the HTTP_PROXY/HTTPS_PROXY environment variables
+	ProxyURL *url.URL
+	// NoProxy is a comma separated list of hosts that should not be accessed via ProxyURL, e.g. ".example.com,10.0.0.0/8"
+	NoProxy string
+
+	// MaxRetries is the number of times a failed request will be retried. Zero disables retries
+	MaxRetries int
+	// RetryWaitMin is the initial time to wait between retries, doubled for each subsequent attempt
+	RetryWaitMin time.Duration
+	// RetryWaitMax caps the time to wait between retries
+	RetryWaitMax time.Duration
+
+	// RequestsPerSecond limits the rate of requests to the broker, across all resources. Zero disables the limit
+	RequestsPerSecond float64
+	// MaxConcurrentRequests limits the number of requests in flight at once, across all resources. Zero disables the limit
+	MaxConcurrentRequests int
+
+	// CacheReads caches the responses to GET requests for the lifetime of the client, until a request that modifies
+	// the same type of resource is made. Use a new client (or ClearCache) for each plan or apply
+	CacheReads bool
+
+	// BaseContext is the parent context for requests made via methods that don't accept a context.
+	// Defaults to context.Background()
+	BaseContext context.Context
 }
 
 // Client is the main Broker API interface.
@@ -70,98 +110,204 @@ type Client struct {
 	client    http.Client
 	Config    Config
 	UserAgent string
+
+	limiter    *limiter
+	tokenMutex sync.RWMutex
+	cache      *readCache
+
+	index      *broker.HalDoc
+	info       BrokerInfo
+	indexMutex sync.RWMutex
 }
 
 // NewClient creates a new Broker API client with sensible but overridable defaults
 func NewClient(httpClient *http.Client, config Config) *Client {
 	if httpClient == nil {
-		httpClient = http.DefaultClient
+		httpClient = &http.Client{}
 	}
 
-	if config.CustomTLSConfig != nil && config.CustomTLSConfig.InsecureSkipVerify {
-		httpClient.Transport = &http.Transport{
-			TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
-		}
+	// The http.Client is copied, so that the transport created from the TLS and proxy settings belongs to this client alone.
+	// A client given with its own transport is used as is
+	c := *httpClient
+	if c.Transport == nil {
+		c.Transport = newTransport(config)
 	}
 
 	client := Client{
-		client:    *httpClient,
+		client:    c,
 		Config:    config,
 		UserAgent: userAgent,
+		limiter:   newLimiter(config.RequestsPerSecond, config.MaxConcurrentRequests),
+	}
+	if config.CacheReads {
+		client.cache = newReadCache()
 	}
 
 	return &client
 }
 
+// BaseContext is used for requests made without an explicit context
+func (c *Client) BaseContext() context.Context {
+	if c.Config.BaseContext != nil {
+		return c.Config.BaseContext
+	}
+
+	return context.Background()
+}
+
+// BaseURL is the root of the broker
+func (c *Client) BaseURL() *url.URL {
+	if c.Config.BaseURL == nil {
+		u, _ := url.Parse(defaultBaseURL)
+		return u
+	}
+
+	return c.Config.BaseURL
+}
+
+// RotateAccessToken replaces the static access token, such as after the token has been regenerated.
+// It returns false (and has no effect) if the client doesn't use a static access token, e.g. tokens are obtained from a TokenSource
+func (c *Client) RotateAccessToken(token string) bool {
+	c.tokenMutex.Lock()
+	defer c.tokenMutex.Unlock()
+
+	if c.Config.TokenSource != nil || c.Config.AccessToken == "" {
+		return false
+	}
+	c.Config.AccessToken = token
+
+	return true
+}
+
 // ReadWebhook returns a Webhook or an error for a given ID
 func (c *Client) ReadWebhook(id string) (*broker.Webhook, error) {
-	res, err := c.doCrud("GET", urlEncodeTemplate(webhookReadUpdateDeleteTemplate, id), nil, new(broker.Webhook))
+	return c.ReadWebhookWithContext(c.BaseContext(), id)
+}
+
+// ReadWebhookWithContext is the same as ReadWebhook, with the given context controlling cancellation and deadlines
+func (c *Client) ReadWebhookWithContext(ctx context.Context, id string) (*broker.Webhook, error) {
+	res, err := c.doCrud(ctx, "GET", c.path(webhookReadUpdateDeleteTemplate, id), nil, new(broker.Webhook))
 	return res.(*broker.Webhook), err
 }
 
 // CreateWebhook creates a new webhook
 func (c *Client) CreateWebhook(w broker.Webhook) (*broker.WebhookResponse, error) {
-	res, err := c.doCrud("POST", webhookCreateTemplate, w, new(broker.WebhookResponse))
+	return c.CreateWebhookWithContext(c.BaseContext(), w)
+}
+
+// CreateWebhookWithContext is the same as CreateWebhook, with the given context controlling cancellation and deadlines
+func (c *Client) CreateWebhookWithContext(ctx context.Context, w broker.Webhook) (*broker.WebhookResponse, error) {
+	res, err := c.doCrud(ctx, "POST", c.path(webhookCreateTemplate), w, new(broker.WebhookResponse))
 	return res.(*broker.WebhookResponse), err
 }
 
 // UpdateWebhook updates an existing webhook. Not all properties are mutable
 func (c *Client) UpdateWebhook(w broker.Webhook) (*broker.WebhookResponse, error) {
-	res, err := c.doCrud("PUT", urlEncodeTemplate(webhookReadUpdateDeleteTemplate, w.ID), w, new(broker.WebhookResponse))
+	return c.UpdateWebhookWithContext(c.BaseContext(), w)
+}
+
+// UpdateWebhookWithContext is the same as UpdateWebhook, with the given context controlling cancellation and deadlines
+func (c *Client) UpdateWebhookWithContext(ctx context.Context, w broker.Webhook) (*broker.WebhookResponse, error) {
+	res, err := c.doCrud(ctx, "PUT", c.path(webhookReadUpdateDeleteTemplate, w.ID), w, new(broker.WebhookResponse))
 	return res.(*broker.WebhookResponse), err
 }
 
 // DeleteWebhook removes an existing webhook
 func (c *Client) DeleteWebhook(w broker.Webhook) error {
-	_, err := c.doCrud("DELETE", urlEncodeTemplate(webhookReadUpdateDeleteTemplate, w.ID), nil, nil)
+	return c.DeleteWebhookWithContext(c.BaseContext(), w)
+}
+
+// DeleteWebhookWithContext is the same as DeleteWebhook, with the given context controlling cancellation and deadlines
+func (c *Client) DeleteWebhookWithContext(ctx context.Context, w broker.Webhook) error {
+	_, err := c.doCrud(ctx, "DELETE", c.path(webhookReadUpdateDeleteTemplate, w.ID), nil, nil)
 	return err
 }
 
 // ReadPacticipant gets a pacticipant
 func (c *Client) ReadPacticipant(name string) (*broker.Pacticipant, error) {
-	res, err := c.doCrud("GET", urlEncodeTemplate(pacticipantReadUpdateDeleteTemplate, name), nil, new(broker.Pacticipant))
+	return c.ReadPacticipantWithContext(c.BaseContext(), name)
+}
+
+// ReadPacticipantWithContext is the same as ReadPacticipant, with the given context controlling cancellation and deadlines
+func (c *Client) ReadPacticipantWithContext(ctx context.Context, name string) (*broker.Pacticipant, error) {
+	res, err := c.doCrud(ctx, "GET", c.path(pacticipantReadUpdateDeleteTemplate, name), nil, new(broker.Pacticipant))
 	return res.(*broker.Pacticipant), err
 }
 
 // CreatePacticipant creates a new Pacticipant
 func (c *Client) CreatePacticipant(p broker.Pacticipant) (*broker.Pacticipant, error) {
-	res, err := c.doCrud("POST", pacticipantCreateTemplate, p, new(broker.Pacticipant))
+	return c.CreatePacticipantWithContext(c.BaseContext(), p)
+}
+
+// CreatePacticipantWithContext is the same as CreatePacticipant, with the given context controlling cancellation and deadlines
+func (c *Client) CreatePacticipantWithContext(ctx context.Context, p broker.Pacticipant) (*broker.Pacticipant, error) {
+	res, err := c.doCrud(ctx, "POST", c.path(pacticipantCreateTemplate), p, new(broker.Pacticipant))
 	return res.(*broker.Pacticipant), err
 }
 
 // UpdatePacticipant updates an existing Pacticipant
 func (c *Client) UpdatePacticipant(p broker.Pacticipant) (*broker.Pacticipant, error) {
-	res, err := c.doCrud("PATCH", urlEncodeTemplate(pacticipantReadUpdateDeleteTemplate, p.Name), p, new(broker.Pacticipant))
+	return c.UpdatePacticipantWithContext(c.BaseContext(), p)
+}
+
+// UpdatePacticipantWithContext is the same as UpdatePacticipant, with the given context controlling cancellation and deadlines
+func (c *Client) UpdatePacticipantWithContext(ctx context.Context, p broker.Pacticipant) (*broker.Pacticipant, error) {
+	res, err := c.doCrud(ctx, "PATCH", c.path(pacticipantReadUpdateDeleteTemplate, p.Name), p, new(broker.Pacticipant))
 	return res.(*broker.Pacticipant), err
 }
 
 // DeletePacticipant removes an existing Pacticipant
 func (c *Client) DeletePacticipant(p broker.Pacticipant) error {
-	_, err := c.doCrud("DELETE", urlEncodeTemplate(pacticipantReadUpdateDeleteTemplate, p.Name), nil, nil)
+	return c.DeletePacticipantWithContext(c.BaseContext(), p)
+}
+
+// DeletePacticipantWithContext is the same as DeletePacticipant, with the given context controlling cancellation and deadlines
+func (c *Client) DeletePacticipantWithContext(ctx context.Context, p broker.Pacticipant) error {
+	_, err := c.doCrud(ctx, "DELETE", c.path(pacticipantReadUpdateDeleteTemplate, p.Name), nil, nil)
 	return err
 }
 
 // ReadTeam gets a Team
 func (c *Client) ReadTeam(t broker.Team) (*broker.Team, error) {
-	res, err := c.doCrud("GET", urlEncodeTemplate(teamReadUpdateDeleteTemplate, t.UUID), nil, new(broker.Team))
+	return c.ReadTeamWithContext(c.BaseContext(), t)
+}
+
+// ReadTeamWithContext is the same as ReadTeam, with the given context controlling cancellation and deadlines
+func (c *Client) ReadTeamWithContext(ctx context.Context, t broker.Team) (*broker.Team, error) {
+	res, err := c.doCrud(ctx, "GET", c.path(teamReadUpdateDeleteTemplate, t.UUID), nil, new(broker.Team))
 	return res.(*broker.Team), err
 }
 
 // CreateTeam creates a Team
 func (c *Client) CreateTeam(t broker.TeamCreateOrUpdateRequest) (*broker.Team, error) {
-	res, err := c.doCrud("POST", teamCreateTemplate, t, new(broker.Team))
+	return c.CreateTeamWithContext(c.BaseContext(), t)
+}
+
+// CreateTeamWithContext is the same as CreateTeam, with the given context controlling cancellation and deadlines
+func (c *Client) CreateTeamWithContext(ctx context.Context, t broker.TeamCreateOrUpdateRequest) (*broker.Team, error) {
+	res, err := c.doCrud(ctx, "POST", c.path(teamCreateTemplate), t, new(broker.Team))
 	return res.(*broker.Team), err
 }
 
 // ReadTeamAssignments finds all users currently in a team
 func (c *Client) ReadTeamAssignments(t broker.Team) (*broker.TeamsAssignmentResponse, error) {
-	res, err := c.doCrud("GET", urlEncodeTemplate(teamAssignmentTemplate, t.UUID), t, new(broker.TeamsAssignmentResponse))
+	return c.ReadTeamAssignmentsWithContext(c.BaseContext(), t)
+}
+
+// ReadTeamAssignmentsWithContext is the same as ReadTeamAssignments, with the given context controlling cancellation and deadlines
+func (c *Client) ReadTeamAssignmentsWithContext(ctx context.Context, t broker.Team) (*broker.TeamsAssignmentResponse, error) {
+	res, err := c.doCrud(ctx, "GET", c.path(teamAssignmentTemplate, t.UUID), t, new(broker.TeamsAssignmentResponse))
 	return res.(*broker.TeamsAssignmentResponse), err
 }
 
 // UpdateTeamAssignments sets the users for a given team, removing any existing users not in the specified request
 func (c *Client) UpdateTeamAssignments(r broker.TeamsAssignmentRequest) (*broker.TeamsAssignmentResponse, error) {
-	res, err := c.doCrud("PUT", urlEncodeTemplate(teamAssignmentTemplate, r.UUID), r, new(broker.TeamsAssignmentResponse))
+	return c.UpdateTeamAssignmentsWithContext(c.BaseContext(), r)
+}
+
+// UpdateTeamAssignmentsWithContext is the same as UpdateTeamAssignments, with the given context controlling cancellation and deadlines
+func (c *Client) UpdateTeamAssignmentsWithContext(ctx context.Context, r broker.TeamsAssignmentRequest) (*broker.TeamsAssignmentResponse, error) {
+	res, err := c.doCrud(ctx, "PUT", c.path(teamAssignmentTemplate, r.UUID), r, new(broker.TeamsAssignmentResponse))
 
 	if err != nil {
 		return nil, err
@@ -178,7 +324,12 @@ func (c *Client) UpdateTeamAssignments(r broker.TeamsAssignmentRequest) (*broker
 
 // AppendTeamAssignments adds users to an existing Team (does not remove absent ones)
 func (c *Client) AppendTeamAssignments(r broker.TeamsAssignmentRequest) (*broker.TeamsAssignmentResponse, error) {
-	res, err := c.doCrud("POST", urlEncodeTemplate(teamAssignmentTemplate, r.UUID), r, new(broker.TeamsAssignmentResponse))
+	return c.AppendTeamAssignmentsWithContext(c.BaseContext(), r)
+}
+
+// AppendTeamAssignmentsWithContext is the same as AppendTeamAssignments, with the given context controlling cancellation and deadlines
+func (c *Client) AppendTeamAssignmentsWithContext(ctx context.Context, r broker.TeamsAssignmentRequest) (*broker.TeamsAssignmentResponse, error) {
+	res, err := c.doCrud(ctx, "POST", c.path(teamAssignmentTemplate, r.UUID), r, new(broker.TeamsAssignmentResponse))
 
 	if err != nil {
 		return nil, err
@@ -195,15 +346,25 @@ func (c *Client) AppendTeamAssignments(r broker.TeamsAssignmentRequest) (*broker
 
 // DeleteTeamAssignment removes a single user from a team
 func (c *Client) DeleteTeamAssignment(t broker.Team, u broker.User) error {
-	_, err := c.doCrud("DELETE", urlEncodeTemplate(teamUserTemplate, t.UUID, u.UUID), nil, nil)
+	return c.DeleteTeamAssignmentWithContext(c.BaseContext(), t, u)
+}
+
+// DeleteTeamAssignmentWithContext is the same as DeleteTeamAssignment, with the given context controlling cancellation and deadlines
+func (c *Client) DeleteTeamAssignmentWithContext(ctx context.Context, t broker.Team, u broker.User) error {
+	_, err := c.doCrud(ctx, "DELETE", c.path(teamUserTemplate, t.UUID, u.UUID), nil, nil)
 
 	return err
 }
 
 // DeleteTeamAssignments removes specified users from the team
 func (c *Client) DeleteTeamAssignments(t broker.TeamsAssignmentRequest) error {
+	return c.DeleteTeamAssignmentsWithContext(c.BaseContext(), t)
+}
+
+// DeleteTeamAssignmentsWithContext is the same as DeleteTeamAssignments, with the given context controlling cancellation and deadlines
+func (c *Client) DeleteTeamAssignmentsWithContext(ctx context.Context, t broker.TeamsAssignmentRequest) error {
 	if len(t.Users) > 0 {
-		_, err := c.doCrud("DELETE", urlEncodeTemplate(teamAssignmentTemplate, t.UUID), t, nil)
+		_, err := c.doCrud(ctx, "DELETE", c.path(teamAssignmentTemplate, t.UUID), t, nil)
 		return err
 	}
 	return nil
@@ -211,61 +372,106 @@ func (c *Client) DeleteTeamAssignments(t broker.TeamsAssignmentRequest) error {
 
 // UpdateTeam updates the team
 func (c *Client) UpdateTeam(t broker.TeamCreateOrUpdateRequest) (*broker.Team, error) {
-	res, err := c.doCrud("PUT", urlEncodeTemplate(teamReadUpdateDeleteTemplate, t.UUID), t, new(broker.Team))
+	return c.UpdateTeamWithContext(c.BaseContext(), t)
+}
+
+// UpdateTeamWithContext is the same as UpdateTeam, with the given context controlling cancellation and deadlines
+func (c *Client) UpdateTeamWithContext(ctx context.Context, t broker.TeamCreateOrUpdateRequest) (*broker.Team, error) {
+	res, err := c.doCrud(ctx, "PUT", c.path(teamReadUpdateDeleteTemplate, t.UUID), t, new(broker.Team))
 	return res.(*broker.Team), err
 }
 
 // DeleteTeam deletes the Team
 func (c *Client) DeleteTeam(t broker.Team) error {
-	_, err := c.doCrud("DELETE", urlEncodeTemplate(teamReadUpdateDeleteTemplate, t.UUID), nil, nil)
+	return c.DeleteTeamWithContext(c.BaseContext(), t)
+}
+
+// DeleteTeamWithContext is the same as DeleteTeam, with the given context controlling cancellation and deadlines
+func (c *Client) DeleteTeamWithContext(ctx context.Context, t broker.Team) error {
+	_, err := c.doCrud(ctx, "DELETE", c.path(teamReadUpdateDeleteTemplate, t.UUID), nil, nil)
 
 	return err
 }
 
 // ReadRole gets a Role
 func (c *Client) ReadRole(uuid string) (*broker.Role, error) {
-	res, err := c.doCrud("GET", urlEncodeTemplate(roleReadUpdateDeleteTemplate, uuid), nil, new(broker.Role))
+	return c.ReadRoleWithContext(c.BaseContext(), uuid)
+}
+
+// ReadRoleWithContext is the same as ReadRole, with the given context controlling cancellation and deadlines
+func (c *Client) ReadRoleWithContext(ctx context.Context, uuid string) (*broker.Role, error) {
+	res, err := c.doCrud(ctx, "GET", c.path(roleReadUpdateDeleteTemplate, uuid), nil, new(broker.Role))
 	return res.(*broker.Role), err
 }
 
 // CreateRole creates a Role
 func (c *Client) CreateRole(p broker.Role) (*broker.Role, error) {
-	res, err := c.doCrud("POST", roleCreateTemplate, p, new(broker.Role))
+	return c.CreateRoleWithContext(c.BaseContext(), p)
+}
+
+// CreateRoleWithContext is the same as CreateRole, with the given context controlling cancellation and deadlines
+func (c *Client) CreateRoleWithContext(ctx context.Context, p broker.Role) (*broker.Role, error) {
+	res, err := c.doCrud(ctx, "POST", c.path(roleCreateTemplate), p, new(broker.Role))
 	return res.(*broker.Role), err
 }
 
 // UpdateRole updates an existing Role
 func (c *Client) UpdateRole(p broker.Role) (*broker.Role, error) {
-	res, err := c.doCrud("PUT", urlEncodeTemplate(roleReadUpdateDeleteTemplate, p.UUID), p, new(broker.Role))
+	return c.UpdateRoleWithContext(c.BaseContext(), p)
+}
+
+// UpdateRoleWithContext is the same as UpdateRole, with the given context controlling cancellation and deadlines
+func (c *Client) UpdateRoleWithContext(ctx context.Context, p broker.Role) (*broker.Role, error) {
+	res, err := c.doCrud(ctx, "PUT", c.path(roleReadUpdateDeleteTemplate, p.UUID), p, new(broker.Role))
 	return res.(*broker.Role), err
 }
 
 // DeleteRole removes a role
 func (c *Client) DeleteRole(p broker.Role) error {
-	_, err := c.doCrud("DELETE", urlEncodeTemplate(roleReadUpdateDeleteTemplate, p.UUID), nil, nil)
+	return c.DeleteRoleWithContext(c.BaseContext(), p)
+}
+
+// DeleteRoleWithContext is the same as DeleteRole, with the given context controlling cancellation and deadlines
+func (c *Client) DeleteRoleWithContext(ctx context.Context, p broker.Role) error {
+	_, err := c.doCrud(ctx, "DELETE", c.path(roleReadUpdateDeleteTemplate, p.UUID), nil, nil)
 
 	return err
 }
 
 // ReadUser gets a User
 func (c *Client) ReadUser(uuid string) (*broker.User, error) {
-	res, err := c.doCrud("GET", urlEncodeTemplate(userReadUpdateDeleteTemplate, uuid), nil, new(broker.User))
+	return c.ReadUserWithContext(c.BaseContext(), uuid)
+}
+
+// ReadUserWithContext is the same as ReadUser, with the given context controlling cancellation and deadlines
+func (c *Client) ReadUserWithContext(ctx context.Context, uuid string) (*broker.User, error) {
+	res, err := c.doCrud(ctx, "GET", c.path(userReadUpdateDeleteTemplate, uuid), nil, new(broker.User))
 	return res.(*broker.User), err
 }
 
 // CreateUser creates a user or a system account
 func (c *Client) CreateUser(u broker.User) (*broker.User, error) {
+	return c.CreateUserWithContext(c.BaseContext(), u)
+}
+
+// CreateUserWithContext is the same as CreateUser, with the given context controlling cancellation and deadlines
+func (c *Client) CreateUserWithContext(ctx context.Context, u broker.User) (*broker.User, error) {
 	template := userCreateTemplate
 	if u.Type == broker.SystemAccount {
-		return c.CreateSystemAccount(u)
+		return c.CreateSystemAccountWithContext(ctx, u)
 	}
-	res, err := c.doCrud("POST", template, u, new(broker.User))
+	res, err := c.doCrud(ctx, "POST", c.path(template), u, new(broker.User))
 	return res.(*broker.User), err
 }
 
 // CreateUser creates a user or a system account
 func (c *Client) CreateSystemAccount(u broker.User) (*broker.User, error) {
-	res, err := c.doCrud("POST", systemAccountCreateTemplate, u, nil)
+	return c.CreateSystemAccountWithContext(c.BaseContext(), u)
+}
+
+// CreateSystemAccountWithContext is the same as CreateSystemAccount, with the given context controlling cancellation and deadlines
+func (c *Client) CreateSystemAccountWithContext(ctx context.Context, u broker.User) (*broker.User, error) {
+	res, err := c.doCrud(ctx, "POST", c.path(systemAccountCreateTemplate), u, nil)
 
 	if err != nil {
 		return nil, err
@@ -284,94 +490,143 @@ func (c *Client) CreateSystemAccount(u broker.User) (*broker.User, error) {
 // UpdateUser updates an existing User
 // currently only supports modifying the "active" property
 func (c *Client) UpdateUser(p broker.User) (*broker.User, error) {
-	res, err := c.doCrud("PUT", urlEncodeTemplate(userReadUpdateDeleteTemplate, p.UUID), p, new(broker.User))
+	return c.UpdateUserWithContext(c.BaseContext(), p)
+}
+
+// UpdateUserWithContext is the same as UpdateUser, with the given context controlling cancellation and deadlines
+func (c *Client) UpdateUserWithContext(ctx context.Context, p broker.User) (*broker.User, error) {
+	res, err := c.doCrud(ctx, "PUT", c.path(userReadUpdateDeleteTemplate, p.UUID), p, new(broker.User))
 	return res.(*broker.User), err
 }
 
 // DeleteUser simply de-activates an existing user. Users are global on the platform,
 // but can be enabled/disabled at the tenant level
 func (c *Client) DeleteUser(p broker.User) error {
+	return c.DeleteUserWithContext(c.BaseContext(), p)
+}
+
+// DeleteUserWithContext is the same as DeleteUser, with the given context controlling cancellation and deadlines
+func (c *Client) DeleteUserWithContext(ctx context.Context, p broker.User) error {
 	p.Active = false
-	_, err := c.UpdateUser(p)
+	_, err := c.UpdateUserWithContext(ctx, p)
 
 	return err
 }
 
 // AddAdminRoleToUser converts a user to an administrator
 func (c *Client) AddAdminRoleToUser(p broker.User) (*broker.User, error) {
-	res, err := c.doCrud("PUT", urlEncodeTemplate(userAdminUpdateTemplate, p.UUID), p, new(broker.User))
+	return c.AddAdminRoleToUserWithContext(c.BaseContext(), p)
+}
+
+// AddAdminRoleToUserWithContext is the same as AddAdminRoleToUser, with the given context controlling cancellation and deadlines
+func (c *Client) AddAdminRoleToUserWithContext(ctx context.Context, p broker.User) (*broker.User, error) {
+	res, err := c.doCrud(ctx, "PUT", c.path(userAdminUpdateTemplate, p.UUID), p, new(broker.User))
 	return res.(*broker.User), err
 }
 
 // RemoveAdminRoleFromUser removes the administrator role from a user
 func (c *Client) RemoveAdminRoleFromUser(p broker.User) (*broker.User, error) {
-	res, err := c.doCrud("DELETE", urlEncodeTemplate(userAdminUpdateTemplate, p.UUID), p, new(broker.User))
+	return c.RemoveAdminRoleFromUserWithContext(c.BaseContext(), p)
+}
+
+// RemoveAdminRoleFromUserWithContext is the same as RemoveAdminRoleFromUser, with the given context controlling cancellation and deadlines
+func (c *Client) RemoveAdminRoleFromUserWithContext(ctx context.Context, p broker.User) (*broker.User, error) {
+	res, err := c.doCrud(ctx, "DELETE", c.path(userAdminUpdateTemplate, p.UUID), p, new(broker.User))
 	return res.(*broker.User), err
 }
 
 // ReadSecret gets the current Secret information (the actual secret is not returned)
 func (c *Client) ReadSecret(uuid string) (*broker.SecretResponse, error) {
-	res, err := c.doCrud("GET", urlEncodeTemplate(secretReadUpdateDeleteTemplate, uuid), nil, new(broker.SecretResponse))
+	return c.ReadSecretWithContext(c.BaseContext(), uuid)
+}
+
+// ReadSecretWithContext is the same as ReadSecret, with the given context controlling cancellation and deadlines
+func (c *Client) ReadSecretWithContext(ctx context.Context, uuid string) (*broker.SecretResponse, error) {
+	res, err := c.doCrud(ctx, "GET", c.path(secretReadUpdateDeleteTemplate, uuid), nil, new(broker.SecretResponse))
 	return res.(*broker.SecretResponse), err
 }
 
 // CreateSecret creates a new secret
 // TODO: better response message for OSS broker vs Pactflow
 func (c *Client) CreateSecret(s broker.Secret) (*broker.SecretResponse, error) {
-	res, err := c.doCrud("POST", secretCreateTemplate, s, new(broker.SecretResponse))
+	return c.CreateSecretWithContext(c.BaseContext(), s)
+}
+
+// CreateSecretWithContext is the same as CreateSecret, with the given context controlling cancellation and deadlines
+func (c *Client) CreateSecretWithContext(ctx context.Context, s broker.Secret) (*broker.SecretResponse, error) {
+	res, err := c.doCrud(ctx, "POST", c.path(secretCreateTemplate), s, new(broker.SecretResponse))
 	return res.(*broker.SecretResponse), err
 }
 
 // UpdateSecret updates an existing secret. All values may be changed
 func (c *Client) UpdateSecret(s broker.Secret) (*broker.SecretResponse, error) {
-	res, err := c.doCrud("PUT", urlEncodeTemplate(secretReadUpdateDeleteTemplate, s.UUID), s, new(broker.SecretResponse))
+	return c.UpdateSecretWithContext(c.BaseContext(), s)
+}
+
+// UpdateSecretWithContext is the same as UpdateSecret, with the given context controlling cancellation and deadlines
+func (c *Client) UpdateSecretWithContext(ctx context.Context, s broker.Secret) (*broker.SecretResponse, error) {
+	res, err := c.doCrud(ctx, "PUT", c.path(secretReadUpdateDeleteTemplate, s.UUID), s, new(broker.SecretResponse))
 	return res.(*broker.SecretResponse), err
 }
 
 // DeleteSecret removes an existing secret
 func (c *Client) DeleteSecret(s broker.Secret) error {
-	_, err := c.doCrud("DELETE", urlEncodeTemplate(secretReadUpdateDeleteTemplate, s.UUID), nil, nil)
+	return c.DeleteSecretWithContext(c.BaseContext(), s)
+}
+
+// DeleteSecretWithContext is the same as DeleteSecret, with the given context controlling cancellation and deadlines
+func (c *Client) DeleteSecretWithContext(ctx context.Context, s broker.Secret) error {
+	_, err := c.doCrud(ctx, "DELETE", c.path(secretReadUpdateDeleteTemplate, s.UUID), nil, nil)
 	return err
 }
 
 // ReadTokens lists all tokens for the given user principal
 func (c *Client) ReadTokens() (*broker.APITokensResponse, error) {
-	res, err := c.doCrud("GET", listTokensTemplate, nil, new(broker.APITokensResponse))
+	return c.ReadTokensWithContext(c.BaseContext())
+}
+
+// ReadTokensWithContext is the same as ReadTokens, with the given context controlling cancellation and deadlines
+func (c *Client) ReadTokensWithContext(ctx context.Context) (*broker.APITokensResponse, error) {
+	res, err := c.doCrud(ctx, "GET", c.path(listTokensTemplate), nil, new(broker.APITokensResponse))
 	return res.(*broker.APITokensResponse), err
 }
 
 // ReadToken finds an API token given a UUID
 func (c *Client) ReadToken(uuid string) (*broker.APIToken, error) {
-	tokens, err := c.ReadTokens()
-	log.Println("[DEBUG] have tokens", tokens)
+	return c.ReadTokenWithContext(c.BaseContext(), uuid)
+}
 
+// ReadTokenWithContext is the same as ReadToken, with the given context controlling cancellation and deadlines
+func (c *Client) ReadTokenWithContext(ctx context.Context, uuid string) (*broker.APIToken, error) {
+	tokens, err := c.ReadTokensWithContext(ctx)
 	if err != nil {
 		return nil, err
 	}
 	for _, t := range tokens.Embedded.Items {
-		log.Println("[DEBUG] have token", t)
 		if t.UUID == uuid {
 			return &t, nil
 		}
 	}
-	return nil, fmt.Errorf("token with uuid '%s' not found", uuid)
+	return nil, fmt.Errorf("token with uuid '%s' %w", uuid, ErrNotFound)
 }
 
 // FindTokenByType finds a token given it's s
 // NOTE: this API will be deprecated once a full CRUD API is available
 func (c *Client) FindTokenByType(tokenType string) (*broker.APIToken, error) {
+	return c.FindTokenByTypeWithContext(c.BaseContext(), tokenType)
+}
+
+// FindTokenByTypeWithContext is the same as FindTokenByType, with the given context controlling cancellation and deadlines
+func (c *Client) FindTokenByTypeWithContext(ctx context.Context, tokenType string) (*broker.APIToken, error) {
 	if _, ok := tokenTypes[tokenType]; !ok {
 		return nil, fmt.Errorf("invalid token type specified, need one of %v, got %s", tokenTypes, tokenType)
 	}
 
-	tokens, err := c.ReadTokens()
-	log.Println("[DEBUG] have tokens", tokens)
-
+	tokens, err := c.ReadTokensWithContext(ctx)
 	if err != nil {
 		return nil, err
 	}
 	for _, t := range tokens.Embedded.Items {
-		log.Println("[DEBUG] have token", t)
 		if t.Description == tokenTypes[tokenType] {
 			return &t, nil
 		}
@@ -381,58 +636,111 @@ func (c *Client) FindTokenByType(tokenType string) (*broker.APIToken, error) {
 
 // RegenerateToken generates a new API Token for the given UUID
 func (c *Client) RegenerateToken(t broker.APIToken) (*broker.APITokenResponse, error) {
-	res, err := c.doCrud("POST", urlEncodeTemplate(tokenRegenerateTemplate, t.UUID), nil, new(broker.APITokenResponse))
+	return c.RegenerateTokenWithContext(c.BaseContext(), t)
+}
+
+// RegenerateTokenWithContext is the same as RegenerateToken, with the given context controlling cancellation and deadlines
+func (c *Client) RegenerateTokenWithContext(ctx context.Context, t broker.APIToken) (*broker.APITokenResponse, error) {
+	res, err := c.doCrud(ctx, "POST", c.path(tokenRegenerateTemplate, t.UUID), nil, new(broker.APITokenResponse))
 	return res.(*broker.APITokenResponse), err
 }
 
+// ReadSystemAccountTokens lists the tokens of the given system account
+func (c *Client) ReadSystemAccountTokens(uuid string) (*broker.APITokensResponse, error) {
+	return c.ReadSystemAccountTokensWithContext(c.BaseContext(), uuid)
+}
+
+// ReadSystemAccountTokensWithContext is the same as ReadSystemAccountTokens, with the given context controlling cancellation and deadlines
+func (c *Client) ReadSystemAccountTokensWithContext(ctx context.Context, uuid string) (*broker.APITokensResponse, error) {
+	res, err := c.doCrud(ctx, "GET", c.path(systemAccountTokensTemplate, uuid), nil, new(broker.APITokensResponse))
+	return res.(*broker.APITokensResponse), err
+}
+
 // SetUserRoles sets the roles for a given user, removing any not given and adding those that were provided
 func (c *Client) SetUserRoles(uuid string, r broker.SetUserRolesRequest) error {
-	_, err := c.doCrud("PUT", urlEncodeTemplate(userRolesUpdateTemplate, uuid), r, nil)
+	return c.SetUserRolesWithContext(c.BaseContext(), uuid, r)
+}
+
+// SetUserRolesWithContext is the same as SetUserRoles, with the given context controlling cancellation and deadlines
+func (c *Client) SetUserRolesWithContext(ctx context.Context, uuid string, r broker.SetUserRolesRequest) error {
+	_, err := c.doCrud(ctx, "PUT", c.path(userRolesUpdateTemplate, uuid), r, nil)
 	return err
 }
 
 // ReadTenantAuthenticationSettings configures the authentication settings on a given Pactflow account
 func (c *Client) ReadTenantAuthenticationSettings() (*broker.AuthenticationSettings, error) {
-	res, err := c.doCrud("GET", tenantAuthenticationTemplate, nil, new(broker.AuthenticationSettings))
+	return c.ReadTenantAuthenticationSettingsWithContext(c.BaseContext())
+}
+
+// ReadTenantAuthenticationSettingsWithContext is the same as ReadTenantAuthenticationSettings, with the given context controlling cancellation and deadlines
+func (c *Client) ReadTenantAuthenticationSettingsWithContext(ctx context.Context) (*broker.AuthenticationSettings, error) {
+	res, err := c.doCrud(ctx, "GET", c.path(tenantAuthenticationTemplate), nil, new(broker.AuthenticationSettings))
 
 	return res.(*broker.AuthenticationSettings), err
 }
 
 // SetTenantAuthenticationSettings configures the authentication settings on a given Pactflow account
 func (c *Client) SetTenantAuthenticationSettings(r broker.AuthenticationSettings) (*broker.AuthenticationSettings, error) {
-	res, err := c.doCrud("PUT", tenantAuthenticationTemplate, r, new(broker.AuthenticationSettings))
+	return c.SetTenantAuthenticationSettingsWithContext(c.BaseContext(), r)
+}
+
+// SetTenantAuthenticationSettingsWithContext is the same as SetTenantAuthenticationSettings, with the given context controlling cancellation and deadlines
+func (c *Client) SetTenantAuthenticationSettingsWithContext(ctx context.Context, r broker.AuthenticationSettings) (*broker.AuthenticationSettings, error) {
+	res, err := c.doCrud(ctx, "PUT", c.path(tenantAuthenticationTemplate), r, new(broker.AuthenticationSettings))
 
 	return res.(*broker.AuthenticationSettings), err
 }
 
 // ReadEnvironment gets an Environment
 func (c *Client) ReadEnvironment(uuid string) (*broker.Environment, error) {
-	res, err := c.doCrud("GET", urlEncodeTemplate(environmentReadUpdateDeleteTemplate, uuid), nil, new(broker.Environment))
+	return c.ReadEnvironmentWithContext(c.BaseContext(), uuid)
+}
+
+// ReadEnvironmentWithContext is the same as ReadEnvironment, with the given context controlling cancellation and deadlines
+func (c *Client) ReadEnvironmentWithContext(ctx context.Context, uuid string) (*broker.Environment, error) {
+	res, err := c.doCrud(ctx, "GET", c.path(environmentReadUpdateDeleteTemplate, uuid), nil, new(broker.Environment))
 	return res.(*broker.Environment), err
 }
 
 // CreateEnvironment creates an Environment
 func (c *Client) CreateEnvironment(p broker.EnvironmentCreateOrUpdateRequest) (*broker.EnvironmentCreateOrUpdateResponse, error) {
-	res, err := c.doCrud("POST", environmentCreateTemplate, p, new(broker.EnvironmentCreateOrUpdateResponse))
+	return c.CreateEnvironmentWithContext(c.BaseContext(), p)
+}
+
+// CreateEnvironmentWithContext is the same as CreateEnvironment, with the given context controlling cancellation and deadlines
+func (c *Client) CreateEnvironmentWithContext(ctx context.Context, p broker.EnvironmentCreateOrUpdateRequest) (*broker.EnvironmentCreateOrUpdateResponse, error) {
+	res, err := c.doCrud(ctx, "POST", c.path(environmentCreateTemplate), p, new(broker.EnvironmentCreateOrUpdateResponse))
 	return res.(*broker.EnvironmentCreateOrUpdateResponse), err
 }
 
 // UpdateEnvironment updates an Environment
 func (c *Client) UpdateEnvironment(p broker.EnvironmentCreateOrUpdateRequest) (*broker.EnvironmentCreateOrUpdateResponse, error) {
-	res, err := c.doCrud("PUT", urlEncodeTemplate(environmentReadUpdateDeleteTemplate, p.UUID), p, new(broker.EnvironmentCreateOrUpdateResponse))
+	return c.UpdateEnvironmentWithContext(c.BaseContext(), p)
+}
+
+// UpdateEnvironmentWithContext is the same as UpdateEnvironment, with the given context controlling cancellation and deadlines
+func (c *Client) UpdateEnvironmentWithContext(ctx context.Context, p broker.EnvironmentCreateOrUpdateRequest) (*broker.EnvironmentCreateOrUpdateResponse, error) {
+	res, err := c.doCrud(ctx, "PUT", c.path(environmentReadUpdateDeleteTemplate, p.UUID), p, new(broker.EnvironmentCreateOrUpdateResponse))
 	return res.(*broker.EnvironmentCreateOrUpdateResponse), err
 }
 
 // DeleteEnvironment removes an Environment
 func (c *Client) DeleteEnvironment(p broker.Environment) error {
-	_, err := c.doCrud("DELETE", urlEncodeTemplate(environmentReadUpdateDeleteTemplate, p.UUID), nil, nil)
+	return c.DeleteEnvironmentWithContext(c.BaseContext(), p)
+}
+
+// DeleteEnvironmentWithContext is the same as DeleteEnvironment, with the given context controlling cancellation and deadlines
+func (c *Client) DeleteEnvironmentWithContext(ctx context.Context, p broker.Environment) error {
+	_, err := c.doCrud(ctx, "DELETE", c.path(environmentReadUpdateDeleteTemplate, p.UUID), nil, nil)
 
 	return err
 }
 
-func (c *Client) newRequest(method, path string, body interface{}) (*http.Request, error) {
-	rel := &url.URL{Path: path}
-	u := c.Config.BaseURL.ResolveReference(rel)
+func (c *Client) newRequest(ctx context.Context, method, path string, body interface{}) (*http.Request, error) {
+	u, err := c.resolve(path)
+	if err != nil {
+		return nil, err
+	}
 	var buf = new(bytes.Buffer)
 	if body != nil {
 		err := json.NewEncoder(buf).Encode(body)
@@ -440,9 +748,13 @@ func (c *Client) newRequest(method, path string, body interface{}) (*http.Reques
 			return nil, err
 		}
 
-		log.Printf("[INFO] raw body to be sent over wire: '%s'", buf.String())
+		log.Printf("[DEBUG] broker request body: %s body=%s", fields("method", method, "path", u.Path), redactBody(buf.Bytes()))
 	}
-	req, err := http.NewRequest(method, u.String(), buf)
+	if c.Config.ReadOnly && !safeMethods[method] {
+		return nil, fmt.Errorf("%w: refusing to send %s %s, as it would modify the broker", ErrReadOnly, method, u.Path)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), buf)
 	if err != nil {
 		return nil, err
 	}
@@ -450,49 +762,173 @@ func (c *Client) newRequest(method, path string, body interface{}) (*http.Reques
 		req.Header.Set("Content-Type", "application/json")
 	}
 
-	if c.Config.AccessToken != "" {
-		req.Header.Add("Authorization", fmt.Sprintf("Bearer %s", c.Config.AccessToken))
-	} else if c.Config.BasicAuthUsername != "" {
-		req.SetBasicAuth(c.Config.BasicAuthUsername, c.Config.BasicAuthPassword)
+	if err := c.authenticate(req); err != nil {
+		return nil, err
 	}
 
 	req.Header.Set("Accept", "application/hal+json, application/json")
 	req.Header.Set("User-Agent", c.UserAgent)
 
-	log.Println("[DEBUG] creating new request", req)
 	return req, nil
 }
 
+// resolve builds the URL for an (escaped) path relative to the root of the broker,
+// retaining any sub-path the broker is hosted under (e.g. https://tools.example.com/pact-broker)
+func (c *Client) resolve(path string) (*url.URL, error) {
+	rel, err := url.Parse(path)
+	if err != nil {
+		return nil, err
+	}
+
+	base := c.Config.BaseURL
+	if base == nil {
+		base, _ = url.Parse(defaultBaseURL)
+	}
+
+	u := *base
+	basePath := strings.TrimRight(base.EscapedPath(), "/")
+	u.Path = strings.TrimRight(base.Path, "/") + "/" + strings.TrimLeft(rel.Path, "/")
+	u.RawPath = basePath + "/" + strings.TrimLeft(rel.EscapedPath(), "/")
+	u.RawQuery = rel.RawQuery
+	u.Fragment = ""
+
+	return &u, nil
+}
+
+// safeMethods only read from the broker
+var safeMethods = map[string]bool{
+	http.MethodGet:     true,
+	http.MethodHead:    true,
+	http.MethodOptions: true,
+}
+
+// authenticate sets the credentials for the request, fetching a token from the token source if there is one.
+// The read access token (if any) is preferred for requests that only read from the broker
+func (c *Client) authenticate(req *http.Request) error {
+	if c.Config.ReadAccessToken != "" && safeMethods[req.Method] {
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", c.Config.ReadAccessToken))
+	} else if source := c.tokenSource(); source != nil {
+		token, err := source.Token(req.Context())
+		if err != nil {
+			return fmt.Errorf("unable to obtain an access token: %w", err)
+		}
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", token))
+	} else if c.Config.BasicAuthUsername != "" {
+		req.SetBasicAuth(c.Config.BasicAuthUsername, c.Config.BasicAuthPassword)
+	}
+
+	return nil
+}
+
+// tokenSource returns the configured token source, or a static one for the AccessToken
+func (c *Client) tokenSource() TokenSource {
+	if c.Config.TokenSource != nil {
+		return c.Config.TokenSource
+	}
+	c.tokenMutex.RLock()
+	defer c.tokenMutex.RUnlock()
+	if c.Config.AccessToken != "" {
+		return StaticTokenSource(c.Config.AccessToken)
+	}
+
+	return nil
+}
+
 func handleError(err error, req *http.Request, resp *http.Response) (*http.Response, error) {
 	bodyBytes, _ := ioutil.ReadAll(resp.Body)
 	resp.Body.Close() //  must close
-	log.Println("[DEBUG] handling error response:", string(bodyBytes))
+	log.Printf("[DEBUG] broker error response: %s body=%s", fields("method", req.Method, "path", req.URL.Path, "status", resp.StatusCode, "request_id", requestID(resp)), redactBody(bodyBytes))
+
+	e := newAPIError(err, req, resp)
+	e.decode(bodyBytes)
 
-	// TODO: decode the multiple concrete error types here
-	var e error
+	return resp, e
+}
 
-	e = &apiErrorResponse{
-		err: err,
+func (c *Client) do(req *http.Request, v interface{}) (*http.Response, error) {
+	var resp *http.Response
+	var err error
+	reauthenticated := false
+
+	var key string
+	var generation uint64
+	if c.cache != nil {
+		path := c.cachePath(req)
+		family := resourceFamily(path)
+		if req.Method == http.MethodGet && v != nil {
+			key = cacheKey(req.Method, path)
+			if e, ok := c.cache.get(key); ok {
+				log.Printf("[DEBUG] broker response (cached): %s", fields("method", req.Method, "path", req.URL.Path))
+				return e.response(req, v)
+			}
+			generation = c.cache.begin()
+		} else if !safeMethods[req.Method] {
+			// Invalidate both before and after the change, so that responses to reads made in the meantime aren't kept
+			families := invalidatedFamilies(family)
+			c.cache.invalidate(families...)
+			defer c.cache.invalidate(families...)
+		}
 	}
-	decodingErr := json.NewDecoder(bytes.NewBuffer(bodyBytes)).Decode(e)
-	if decodingErr != nil {
-		log.Println("[DEBUG] error decoding APIErrorResponse from response for", req.Method, req.URL.Path, ". Error", decodingErr)
 
-		e = &apiArrayErrorResponse{
-			err: err,
+	for attempt := 0; ; attempt++ {
+		log.Printf("[DEBUG] broker request: %s headers=%s", fields("method", req.Method, "path", req.URL.Path, "attempt", attempt+1), formatHeaders(req.Header))
+		var release func()
+		release, err = c.limiter.acquire(req.Context())
+		if err != nil {
+			return nil, err
 		}
-		decodingErr = json.NewDecoder(bytes.NewBuffer(bodyBytes)).Decode(e)
-		if decodingErr != nil {
-			log.Println("[DEBUG] error decoding APIArrayErrorResponse from response for", req.Method, req.URL.Path, ". Error", decodingErr)
+		start := time.Now()
+		resp, err = c.client.Do(req)
+		release()
+		if err != nil {
+			log.Printf("[DEBUG] broker request failed: %s error=%q", fields("method", req.Method, "path", req.URL.Path, "attempt", attempt+1, "latency", latency(start)), err)
+		} else {
+			log.Printf("[DEBUG] broker response: %s headers=%s", fields("method", req.Method, "path", req.URL.Path, "status", resp.StatusCode, "latency", latency(start), "request_id", requestID(resp)), formatHeaders(resp.Header))
 		}
-	}
 
-	return resp, e
-}
+		// A cached token may have expired or been revoked, so discard it and try again with a fresh one.
+		// This is only done once per request, and doesn't count as a retry
+		if invalidator, ok := c.Config.TokenSource.(tokenInvalidator); ok && err == nil && resp.StatusCode == http.StatusUnauthorized && !reauthenticated {
+			log.Println("[INFO] access token was rejected, fetching a new token")
+			io.Copy(ioutil.Discard, resp.Body)
+			resp.Body.Close()
+
+			reauthenticated = true
+			attempt--
+			invalidator.Invalidate()
+			if err = c.authenticate(req); err != nil {
+				return nil, err
+			}
+			if err = rewindBody(req); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		wait, retry := c.shouldRetry(req, resp, err, attempt)
+		if !retry {
+			break
+		}
+
+		if err != nil {
+			log.Printf("[WARN] request %s %s failed, retrying in %s (attempt %d of %d): %v", req.Method, req.URL.Path, wait, attempt+1, c.Config.MaxRetries, err)
+		} else {
+			log.Printf("[WARN] request %s %s returned %d, retrying in %s (attempt %d of %d)", req.Method, req.URL.Path, resp.StatusCode, wait, attempt+1, c.Config.MaxRetries)
+			io.Copy(ioutil.Discard, resp.Body)
+			resp.Body.Close()
+		}
+
+		select {
+		case <-time.After(wait):
+		case <-req.Context().Done():
+			return nil, req.Context().Err()
+		}
+
+		if err = rewindBody(req); err != nil {
+			return nil, err
+		}
+	}
 
-func (c *Client) do(req *http.Request, v interface{}) (*http.Response, error) {
-	log.Println("[DEBUG] sending body for request", req)
-	resp, err := c.client.Do(req)
 	if err != nil {
 		return nil, err
 	}
@@ -503,8 +939,6 @@ func (c *Client) do(req *http.Request, v interface{}) (*http.Response, error) {
 		io.Copy(ioutil.Discard, resp.Body)
 		resp.Body.Close()
 	}()
-	log.Println("[DEBUG] response for request:", req, "resp:", resp)
-
 	if resp.StatusCode >= 500 {
 		return handleError(ErrSystemUnavailable, req, resp)
 	}
@@ -517,24 +951,44 @@ func (c *Client) do(req *http.Request, v interface{}) (*http.Response, error) {
 		return handleError(ErrForbidden, req, resp)
 	}
 
+	if resp.StatusCode == 404 {
+		return handleError(ErrNotFound, req, resp)
+	}
+
+	if resp.StatusCode == 409 {
+		return handleError(ErrConflict, req, resp)
+	}
+
+	if resp.StatusCode == 429 {
+		return handleError(ErrTooManyRequests, req, resp)
+	}
+
 	if resp.StatusCode >= 400 && resp.StatusCode < 500 {
 		return handleError(ErrBadRequest, req, resp)
 	}
 
 	if v != nil {
-		err = json.NewDecoder(resp.Body).Decode(v)
+		var body []byte
+		body, err = ioutil.ReadAll(resp.Body)
+		if err == nil {
+			err = json.Unmarshal(body, v)
+		}
 		if err != nil {
 			log.Println("[DEBUG] error decoding response for", req.URL.Path, ". Error", err)
 			return resp, err
 		}
-		log.Printf("[DEBUG] Response body: %+v \n", v)
+		log.Printf("[DEBUG] broker response body: %s body=%s", fields("method", req.Method, "path", req.URL.Path), redactEntity(v))
+
+		if key != "" {
+			c.cache.put(key, resourceFamily(c.cachePath(req)), generation, resp.Header, body)
+		}
 	}
 
 	return resp, err
 }
 
-func (c *Client) doCrud(method string, path string, requestEntity interface{}, responseEntity interface{}) (interface{}, error) {
-	req, err := c.newRequest(method, path, requestEntity)
+func (c *Client) doCrud(ctx context.Context, method string, path string, requestEntity interface{}, responseEntity interface{}) (interface{}, error) {
+	req, err := c.newRequest(ctx, method, path, requestEntity)
 	var resp *http.Response
 
 	if err != nil {
@@ -544,8 +998,8 @@ func (c *Client) doCrud(method string, path string, requestEntity interface{}, r
 		resp, err = c.do(req, nil)
 
 		// 201 -> extract the location header if the expectation is a string value
-		if resp.StatusCode == 201 {
-			log.Println("[DEBUG] have 201, returning Location header", resp.Header)
+		if resp != nil && resp.StatusCode == 201 {
+			log.Printf("[DEBUG] broker created resource: %s", fields("method", method, "path", req.URL.Path, "location", resp.Header.Get("Location")))
 			return resp.Header.Get("Location"), err
 		}
 	} else {
@@ -555,11 +1009,27 @@ func (c *Client) doCrud(method string, path string, requestEntity interface{}, r
 	return responseEntity, err
 }
 
+// PacticipantPath is the path of the named pacticipant's resource, relative to the broker's base URL
+func PacticipantPath(name string) string {
+	return urlEncodeTemplate(pacticipantReadUpdateDeleteTemplate, name)
+}
+
+// BadgePath is the path of the verification status badge for the latest pact between a consumer and provider,
+// relative to the broker's base URL
+func BadgePath(provider, consumer string) string {
+	return urlEncodeTemplate(badgeTemplate, provider, consumer)
+}
+
+// EscapeName escapes a name (e.g. of a pacticipant) for use as a segment of a broker URL
+func EscapeName(name string) string {
+	return url.PathEscape(name)
+}
+
 func urlEncodeTemplate(template string, parameters ...string) string {
 	encodedParams := make([]interface{}, len(parameters))
 
 	for i, p := range parameters {
-		encodedParams[i] = url.PathEscape(p)
+		encodedParams[i] = EscapeName(p)
 	}
 
 	return fmt.Sprintf(template, encodedParams...)
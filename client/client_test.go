@@ -1 +1,294 @@
 package client
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/pactflow/terraform/broker"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClientRetries(t *testing.T) {
+	t.Run("retries idempotent requests after a transient server error", func(t *testing.T) {
+		attempts := 0
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			attempts++
+			if attempts < 3 {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			w.Header().Set("Content-Type", "application/hal+json")
+			w.Write([]byte(`{"name":"terraform-client"}`))
+		}))
+		defer server.Close()
+
+		res, err := clientForTest(server, 3).ReadPacticipant("terraform-client")
+
+		assert.NoError(t, err)
+		assert.Equal(t, "terraform-client", res.Name)
+		assert.Equal(t, 3, attempts)
+	})
+
+	t.Run("gives up once the maximum number of retries is reached", func(t *testing.T) {
+		attempts := 0
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			attempts++
+			w.WriteHeader(http.StatusBadGateway)
+		}))
+		defer server.Close()
+
+		_, err := clientForTest(server, 2).ReadPacticipant("terraform-client")
+
+		assert.ErrorIs(t, err, ErrSystemUnavailable)
+		assert.Equal(t, 3, attempts)
+	})
+
+	t.Run("does not retry non-idempotent requests after a server error", func(t *testing.T) {
+		attempts := 0
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			attempts++
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}))
+		defer server.Close()
+
+		_, err := clientForTest(server, 3).CreatePacticipant(broker.Pacticipant{Name: "terraform-client"})
+
+		assert.ErrorIs(t, err, ErrSystemUnavailable)
+		assert.Equal(t, 1, attempts)
+	})
+
+	t.Run("retries non-idempotent requests that were rate limited, resending the body", func(t *testing.T) {
+		attempts := 0
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			attempts++
+			if attempts == 1 {
+				w.Header().Set("Retry-After", "0")
+				w.WriteHeader(http.StatusTooManyRequests)
+				return
+			}
+			body := new(broker.Pacticipant)
+			assert.NoError(t, json.NewDecoder(r.Body).Decode(body))
+			assert.Equal(t, "terraform-client", body.Name)
+
+			w.Header().Set("Content-Type", "application/hal+json")
+			w.WriteHeader(http.StatusCreated)
+			w.Write([]byte(`{"name":"terraform-client"}`))
+		}))
+		defer server.Close()
+
+		res, err := clientForTest(server, 3).CreatePacticipant(broker.Pacticipant{Name: "terraform-client"})
+
+		assert.NoError(t, err)
+		assert.Equal(t, "terraform-client", res.Name)
+		assert.Equal(t, 2, attempts)
+	})
+
+	t.Run("does not retry client errors", func(t *testing.T) {
+		attempts := 0
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			attempts++
+			w.WriteHeader(http.StatusBadRequest)
+		}))
+		defer server.Close()
+
+		_, err := clientForTest(server, 3).ReadPacticipant("terraform-client")
+
+		assert.ErrorIs(t, err, ErrBadRequest)
+		assert.Equal(t, 1, attempts)
+	})
+}
+
+func TestClientContext(t *testing.T) {
+	t.Run("stops retrying once the context is cancelled", func(t *testing.T) {
+		attempts := 0
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			attempts++
+			w.Header().Set("Retry-After", "60")
+			w.WriteHeader(http.StatusTooManyRequests)
+		}))
+		defer server.Close()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		time.AfterFunc(50*time.Millisecond, cancel)
+
+		c := clientForTest(server, 3)
+		c.Config.RetryWaitMax = time.Minute
+		_, err := c.ReadPacticipantWithContext(ctx, "terraform-client")
+
+		assert.ErrorIs(t, err, context.Canceled)
+		assert.Equal(t, 1, attempts)
+	})
+
+	t.Run("caps the Retry-After header at the maximum wait", func(t *testing.T) {
+		attempts := 0
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			attempts++
+			if attempts == 1 {
+				w.Header().Set("Retry-After", "86400")
+				w.WriteHeader(http.StatusTooManyRequests)
+				return
+			}
+			w.Write([]byte(`{"name":"terraform-client"}`))
+		}))
+		defer server.Close()
+
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		res, err := clientForTest(server, 3).ReadPacticipantWithContext(ctx, "terraform-client")
+
+		assert.NoError(t, err)
+		assert.Equal(t, "terraform-client", res.Name)
+		assert.Equal(t, 2, attempts)
+	})
+
+	t.Run("returns the response if the wait would exceed the deadline", func(t *testing.T) {
+		attempts := 0
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			attempts++
+			w.Header().Set("Retry-After", "60")
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}))
+		defer server.Close()
+
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		c := clientForTest(server, 3)
+		c.Config.RetryWaitMax = time.Minute
+		start := time.Now()
+		_, err := c.ReadPacticipantWithContext(ctx, "terraform-client")
+
+		assert.ErrorIs(t, err, ErrSystemUnavailable)
+		assert.Equal(t, 1, attempts)
+		assert.Less(t, time.Since(start), time.Second)
+	})
+
+	t.Run("uses the base context for requests without an explicit context", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(`{"name":"terraform-client"}`))
+		}))
+		defer server.Close()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		c := clientForTest(server, 0)
+		c.Config.BaseContext = ctx
+		_, err := c.ReadPacticipant("terraform-client")
+
+		assert.ErrorIs(t, err, context.Canceled)
+	})
+}
+
+func TestClientReadOnly(t *testing.T) {
+	t.Run("rejects requests that would modify the broker without sending them", func(t *testing.T) {
+		attempts := 0
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			attempts++
+		}))
+		defer server.Close()
+
+		c := clientForTest(server, 0)
+		c.Config.ReadOnly = true
+
+		_, err := c.CreatePacticipant(broker.Pacticipant{Name: "terraform-client"})
+		assert.ErrorIs(t, err, ErrReadOnly)
+		assert.ErrorContains(t, err, "POST /pacticipants")
+
+		err = c.DeletePacticipant(broker.Pacticipant{Name: "terraform-client"})
+		assert.ErrorIs(t, err, ErrReadOnly)
+
+		assert.Equal(t, 0, attempts)
+	})
+
+	t.Run("uses the read access token for requests that only read from the broker", func(t *testing.T) {
+		var tokens []string
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			tokens = append(tokens, r.Method+" "+r.Header.Get("Authorization"))
+			w.Header().Set("Content-Type", "application/hal+json")
+			w.Write([]byte(`{"name":"terraform-client"}`))
+		}))
+		defer server.Close()
+
+		c := clientForTest(server, 0)
+		c.Config.ReadAccessToken = "read"
+
+		_, err := c.ReadPacticipant("terraform-client")
+		assert.NoError(t, err)
+		_, err = c.UpdatePacticipant(broker.Pacticipant{Name: "terraform-client"})
+		assert.NoError(t, err)
+
+		assert.Equal(t, []string{"GET Bearer read", "PATCH Bearer 1234"}, tokens)
+	})
+}
+
+func TestClientResolve(t *testing.T) {
+	for _, tc := range []struct {
+		base     string
+		path     string
+		expected string
+	}{
+		{"https://broker.example.com", "/webhooks", "https://broker.example.com/webhooks"},
+		{"https://broker.example.com/", "/webhooks", "https://broker.example.com/webhooks"},
+		{"https://tools.example.com/pact-broker", "/webhooks", "https://tools.example.com/pact-broker/webhooks"},
+		{"https://tools.example.com/pact-broker/", "/admin/teams/1234/users", "https://tools.example.com/pact-broker/admin/teams/1234/users"},
+		{"https://tools.example.com/pact-broker", metadataTemplate, "https://tools.example.com/pact-broker/"},
+		{"https://tools.example.com/pact-broker", urlEncodeTemplate(pacticipantReadUpdateDeleteTemplate, "my app/v1"), "https://tools.example.com/pact-broker/pacticipants/my%20app%2Fv1"},
+	} {
+		base, _ := url.Parse(tc.base)
+		c := NewClient(nil, Config{BaseURL: base})
+
+		u, err := c.resolve(tc.path)
+
+		assert.NoError(t, err)
+		assert.Equal(t, tc.expected, u.String())
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	wait, ok := parseRetryAfter("5")
+	assert.True(t, ok)
+	assert.Equal(t, 5*time.Second, wait)
+
+	wait, ok = parseRetryAfter(time.Now().Add(-1 * time.Minute).UTC().Format(http.TimeFormat))
+	assert.True(t, ok)
+	assert.Equal(t, time.Duration(0), wait)
+
+	_, ok = parseRetryAfter("")
+	assert.False(t, ok)
+
+	_, ok = parseRetryAfter("soon")
+	assert.False(t, ok)
+}
+
+func TestBackoff(t *testing.T) {
+	c := NewClient(nil, Config{
+		RetryWaitMin: 100 * time.Millisecond,
+		RetryWaitMax: 300 * time.Millisecond,
+	})
+
+	for attempt := 0; attempt < 5; attempt++ {
+		wait := c.backoff(attempt)
+		assert.GreaterOrEqual(t, wait, 50*time.Millisecond)
+		assert.LessOrEqual(t, wait, 300*time.Millisecond)
+	}
+}
+
+func clientForTest(server *httptest.Server, maxRetries int) *Client {
+	u, _ := url.Parse(server.URL)
+
+	return NewClient(nil, Config{
+		BaseURL:      u,
+		AccessToken:  "1234",
+		MaxRetries:   maxRetries,
+		RetryWaitMin: time.Millisecond,
+		RetryWaitMax: 5 * time.Millisecond,
+	})
+}
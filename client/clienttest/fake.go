@@ -0,0 +1,1529 @@
+// Package clienttest provides an in-memory implementation of client.BrokerAPI, so that code built on the client
+// (such as the provider's resources) can be tested without a broker
+package clienttest
+
+import (
+	"context"
+	"fmt"
+	"iter"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pactflow/terraform/broker"
+	"github.com/pactflow/terraform/client"
+)
+
+const (
+	// AdministratorRoleUUID is the UUID of the built-in administrator role, as managed via AddAdminRoleToUser
+	AdministratorRoleUUID = "cf75d7c2-416b-11ea-af5e-53c3b1a4efd8"
+
+	defaultBaseURL = "http://broker.test"
+)
+
+// tokenDescriptions are the descriptions the broker gives each type of token, as used by FindTokenByType
+var tokenDescriptions = map[string]string{
+	"read-only":  "Read only token (developer)",
+	"read-write": "Read/write token (CI)",
+}
+
+// Fake is an in-memory broker. The zero value is not usable, use NewFake.
+//
+// Resources are stored as given, with server-generated fields (UUIDs, IDs, embedded relations) populated the same
+// way as the broker. It is safe for concurrent use
+type Fake struct {
+	// Info is returned by BrokerInfo, and determines the relations supported by HasRelation
+	Info client.BrokerInfo
+	// AccessToken is the static token replaced by RotateAccessToken. Leave it empty to behave like a client
+	// using basic authentication or a token source
+	AccessToken string
+	// Errors injects failures, keyed by the name of the method without the WithContext suffix (e.g. "SetUserRoles").
+	// The error is returned every time the method is called, until it is removed
+	Errors map[string]error
+	// Calls records the name of every method called, without the WithContext suffix, in order
+	Calls []string
+
+	mutex        sync.Mutex
+	baseURL      *url.URL
+	sequence     int
+	pacticipants map[string]broker.Pacticipant
+	webhooks     map[string]broker.Webhook
+	teams        map[string]*fakeTeam
+	roles        map[string]broker.Role
+	users        map[string]*fakeUser
+	secrets      map[string]broker.Secret
+	tokens       map[string]broker.APIToken
+	environments map[string]*fakeEnvironment
+	auth         broker.AuthenticationSettings
+}
+
+type fakeTeam struct {
+	team    broker.Team
+	members []string
+}
+
+type fakeUser struct {
+	user   broker.User
+	roles  []string
+	tokens []broker.APIToken
+}
+
+type fakeEnvironment struct {
+	environment broker.Environment
+	teams       []string
+}
+
+// OSSBrokerInfo describes the open source broker, which doesn't advertise any of the Pactflow relations.
+// Assign it to Fake.Info to emulate an OSS broker
+func OSSBrokerInfo() client.BrokerInfo {
+	return client.BrokerInfo{
+		Flavour:   client.OSSBroker,
+		Relations: []string{"pb:pacticipants", "pb:pacticipant", "pb:webhooks", "pb:environments", "pb:environment"},
+	}
+}
+
+// NewFake creates an empty Pactflow broker, with the built-in administrator role and a read-only and read/write token
+func NewFake() *Fake {
+	base, _ := url.Parse(defaultBaseURL)
+	f := &Fake{
+		Info: client.BrokerInfo{
+			Flavour:   client.PactflowBroker,
+			Relations: []string{"pb:pacticipants", "pb:pacticipant", "pb:webhooks", "pb:environments", "pb:environment", "pf:admin-users", "pf:admin-teams", "pf:admin-roles"},
+		},
+		Errors:       map[string]error{},
+		baseURL:      base,
+		pacticipants: map[string]broker.Pacticipant{},
+		webhooks:     map[string]broker.Webhook{},
+		teams:        map[string]*fakeTeam{},
+		roles:        map[string]broker.Role{},
+		users:        map[string]*fakeUser{},
+		secrets:      map[string]broker.Secret{},
+		tokens:       map[string]broker.APIToken{},
+		environments: map[string]*fakeEnvironment{},
+	}
+
+	f.roles[AdministratorRoleUUID] = broker.Role{UUID: AdministratorRoleUUID, Name: "Administrator"}
+	for _, tokenType := range []string{"read-only", "read-write"} {
+		uuid := f.newUUID()
+		f.tokens[uuid] = broker.APIToken{UUID: uuid, Description: tokenDescriptions[tokenType], Value: f.newUUID()}
+	}
+
+	return f
+}
+
+var _ client.BrokerAPI = (*Fake)(nil)
+
+// call records the call, and returns the injected error for the method (or the context's error), if any.
+// The mutex must be held
+func (f *Fake) call(ctx context.Context, method string) error {
+	f.Calls = append(f.Calls, method)
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	return f.Errors[method]
+}
+
+// newUUID generates a unique, UUID shaped identifier. The mutex must be held
+func (f *Fake) newUUID() string {
+	f.sequence++
+	return fmt.Sprintf("00000000-0000-4000-8000-%012d", f.sequence)
+}
+
+func (f *Fake) self(path string) broker.HalDoc {
+	return broker.HalDoc{Links: broker.HalLinks{"self": broker.Link{Href: f.baseURL.String() + path}}}
+}
+
+func notFound(kind, id string) error {
+	return fmt.Errorf("%s '%s' %w", kind, id, client.ErrNotFound)
+}
+
+func matches(opts client.ListOptions, name string, teams []string) bool {
+	if opts.Name != "" && opts.Name != name {
+		return false
+	}
+	if !strings.HasPrefix(name, opts.NamePrefix) {
+		return false
+	}
+	if opts.TeamUUID == "" || teams == nil {
+		return true
+	}
+	for _, t := range teams {
+		if t == opts.TeamUUID {
+			return true
+		}
+	}
+
+	return false
+}
+
+// seq yields the items sorted by key, or just the error
+func seq[T any](items map[string]T, err error) iter.Seq2[T, error] {
+	keys := make([]string, 0, len(items))
+	for k := range items {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	return func(yield func(T, error) bool) {
+		var zero T
+		if err != nil {
+			yield(zero, err)
+			return
+		}
+		for _, k := range keys {
+			if !yield(items[k], nil) {
+				return
+			}
+		}
+	}
+}
+
+// BaseURL is the root of the fake broker
+func (f *Fake) BaseURL() *url.URL {
+	u := *f.baseURL
+	return &u
+}
+
+// BaseContext always returns context.Background
+func (f *Fake) BaseContext() context.Context {
+	return context.Background()
+}
+
+// RotateAccessToken replaces AccessToken, if set
+func (f *Fake) RotateAccessToken(token string) bool {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if f.AccessToken == "" {
+		return false
+	}
+	f.AccessToken = token
+
+	return true
+}
+
+// ClearCache does nothing, as nothing is cached
+func (f *Fake) ClearCache() {}
+
+// Discover does nothing, other than record the call and return any injected error
+func (f *Fake) Discover() error {
+	return f.DiscoverWithContext(f.BaseContext())
+}
+
+// DiscoverWithContext is the same as Discover, with the given context controlling cancellation and deadlines
+func (f *Fake) DiscoverWithContext(ctx context.Context) error {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	return f.call(ctx, "Discover")
+}
+
+// BrokerInfo returns Info
+func (f *Fake) BrokerInfo() client.BrokerInfo {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	return f.Info
+}
+
+// HasRelation checks whether the relation is one of Info.Relations
+func (f *Fake) HasRelation(rel string) bool {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	for _, r := range f.Info.Relations {
+		if r == rel {
+			return true
+		}
+	}
+
+	return false
+}
+
+// WaitForReady returns immediately, as the fake is always ready
+func (f *Fake) WaitForReady(timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(f.BaseContext(), timeout)
+	defer cancel()
+
+	return f.WaitForReadyWithContext(ctx)
+}
+
+// WaitForReadyWithContext is the same as WaitForReady, with the given context controlling cancellation and deadlines
+func (f *Fake) WaitForReadyWithContext(ctx context.Context) error {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	return f.call(ctx, "WaitForReady")
+}
+
+// ReadPacticipant gets a pacticipant by name
+func (f *Fake) ReadPacticipant(name string) (*broker.Pacticipant, error) {
+	return f.ReadPacticipantWithContext(f.BaseContext(), name)
+}
+
+// ReadPacticipantWithContext is the same as ReadPacticipant, with the given context controlling cancellation and deadlines
+func (f *Fake) ReadPacticipantWithContext(ctx context.Context, name string) (*broker.Pacticipant, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if err := f.call(ctx, "ReadPacticipant"); err != nil {
+		return nil, err
+	}
+	p, ok := f.pacticipants[name]
+	if !ok {
+		return nil, notFound("pacticipant", name)
+	}
+
+	return &p, nil
+}
+
+// CreatePacticipant stores a new pacticipant
+func (f *Fake) CreatePacticipant(p broker.Pacticipant) (*broker.Pacticipant, error) {
+	return f.CreatePacticipantWithContext(f.BaseContext(), p)
+}
+
+// CreatePacticipantWithContext is the same as CreatePacticipant, with the given context controlling cancellation and deadlines
+func (f *Fake) CreatePacticipantWithContext(ctx context.Context, p broker.Pacticipant) (*broker.Pacticipant, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if err := f.call(ctx, "CreatePacticipant"); err != nil {
+		return nil, err
+	}
+	f.pacticipants[p.Name] = p
+
+	return &p, nil
+}
+
+// UpdatePacticipant replaces an existing pacticipant
+func (f *Fake) UpdatePacticipant(p broker.Pacticipant) (*broker.Pacticipant, error) {
+	return f.UpdatePacticipantWithContext(f.BaseContext(), p)
+}
+
+// UpdatePacticipantWithContext is the same as UpdatePacticipant, with the given context controlling cancellation and deadlines
+func (f *Fake) UpdatePacticipantWithContext(ctx context.Context, p broker.Pacticipant) (*broker.Pacticipant, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if err := f.call(ctx, "UpdatePacticipant"); err != nil {
+		return nil, err
+	}
+	if _, ok := f.pacticipants[p.Name]; !ok {
+		return nil, notFound("pacticipant", p.Name)
+	}
+	f.pacticipants[p.Name] = p
+
+	return &p, nil
+}
+
+// DeletePacticipant removes a pacticipant
+func (f *Fake) DeletePacticipant(p broker.Pacticipant) error {
+	return f.DeletePacticipantWithContext(f.BaseContext(), p)
+}
+
+// DeletePacticipantWithContext is the same as DeletePacticipant, with the given context controlling cancellation and deadlines
+func (f *Fake) DeletePacticipantWithContext(ctx context.Context, p broker.Pacticipant) error {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if err := f.call(ctx, "DeletePacticipant"); err != nil {
+		return err
+	}
+	if _, ok := f.pacticipants[p.Name]; !ok {
+		return notFound("pacticipant", p.Name)
+	}
+	delete(f.pacticipants, p.Name)
+
+	return nil
+}
+
+// ListPacticipants returns an iterator over the pacticipants matching the options, in name order
+func (f *Fake) ListPacticipants(opts client.ListOptions) iter.Seq2[broker.Pacticipant, error] {
+	return f.ListPacticipantsWithContext(f.BaseContext(), opts)
+}
+
+// ListPacticipantsWithContext is the same as ListPacticipants, with the given context controlling cancellation and deadlines
+func (f *Fake) ListPacticipantsWithContext(ctx context.Context, opts client.ListOptions) iter.Seq2[broker.Pacticipant, error] {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	items := map[string]broker.Pacticipant{}
+	err := f.call(ctx, "ListPacticipants")
+	for name, p := range f.pacticipants {
+		if matches(opts, name, nil) {
+			items[name] = p
+		}
+	}
+
+	return seq(items, err)
+}
+
+// ReadWebhook gets a webhook by ID
+func (f *Fake) ReadWebhook(id string) (*broker.Webhook, error) {
+	return f.ReadWebhookWithContext(f.BaseContext(), id)
+}
+
+// ReadWebhookWithContext is the same as ReadWebhook, with the given context controlling cancellation and deadlines
+func (f *Fake) ReadWebhookWithContext(ctx context.Context, id string) (*broker.Webhook, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if err := f.call(ctx, "ReadWebhook"); err != nil {
+		return nil, err
+	}
+	w, ok := f.webhooks[id]
+	if !ok {
+		return nil, notFound("webhook", id)
+	}
+
+	return &w, nil
+}
+
+// CreateWebhook stores a new webhook, generating its ID. The ID is only available via the self link, as for the broker
+func (f *Fake) CreateWebhook(w broker.Webhook) (*broker.WebhookResponse, error) {
+	return f.CreateWebhookWithContext(f.BaseContext(), w)
+}
+
+// CreateWebhookWithContext is the same as CreateWebhook, with the given context controlling cancellation and deadlines
+func (f *Fake) CreateWebhookWithContext(ctx context.Context, w broker.Webhook) (*broker.WebhookResponse, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if err := f.call(ctx, "CreateWebhook"); err != nil {
+		return nil, err
+	}
+	w.ID = f.newUUID()
+	f.webhooks[w.ID] = w
+
+	return &broker.WebhookResponse{Webhook: w, HalDoc: f.self("/webhooks/" + w.ID)}, nil
+}
+
+// UpdateWebhook replaces an existing webhook
+func (f *Fake) UpdateWebhook(w broker.Webhook) (*broker.WebhookResponse, error) {
+	return f.UpdateWebhookWithContext(f.BaseContext(), w)
+}
+
+// UpdateWebhookWithContext is the same as UpdateWebhook, with the given context controlling cancellation and deadlines
+func (f *Fake) UpdateWebhookWithContext(ctx context.Context, w broker.Webhook) (*broker.WebhookResponse, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if err := f.call(ctx, "UpdateWebhook"); err != nil {
+		return nil, err
+	}
+	if _, ok := f.webhooks[w.ID]; !ok {
+		return nil, notFound("webhook", w.ID)
+	}
+	f.webhooks[w.ID] = w
+
+	return &broker.WebhookResponse{Webhook: w, HalDoc: f.self("/webhooks/" + w.ID)}, nil
+}
+
+// DeleteWebhook removes a webhook
+func (f *Fake) DeleteWebhook(w broker.Webhook) error {
+	return f.DeleteWebhookWithContext(f.BaseContext(), w)
+}
+
+// DeleteWebhookWithContext is the same as DeleteWebhook, with the given context controlling cancellation and deadlines
+func (f *Fake) DeleteWebhookWithContext(ctx context.Context, w broker.Webhook) error {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if err := f.call(ctx, "DeleteWebhook"); err != nil {
+		return err
+	}
+	if _, ok := f.webhooks[w.ID]; !ok {
+		return notFound("webhook", w.ID)
+	}
+	delete(f.webhooks, w.ID)
+
+	return nil
+}
+
+// ListWebhooks returns an iterator over the webhooks matching the options, in ID order
+func (f *Fake) ListWebhooks(opts client.ListOptions) iter.Seq2[broker.Webhook, error] {
+	return f.ListWebhooksWithContext(f.BaseContext(), opts)
+}
+
+// ListWebhooksWithContext is the same as ListWebhooks, with the given context controlling cancellation and deadlines
+func (f *Fake) ListWebhooksWithContext(ctx context.Context, opts client.ListOptions) iter.Seq2[broker.Webhook, error] {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	items := map[string]broker.Webhook{}
+	err := f.call(ctx, "ListWebhooks")
+	for id, w := range f.webhooks {
+		if matches(opts, w.Description, []string{w.TeamUUID}) {
+			items[id] = w
+		}
+	}
+
+	return seq(items, err)
+}
+
+// readTeam builds the team as returned by the broker, with its members embedded. The mutex must be held
+func (f *Fake) readTeam(uuid string) (*broker.Team, error) {
+	t, ok := f.teams[uuid]
+	if !ok {
+		return nil, notFound("team", uuid)
+	}
+
+	team := t.team
+	team.NumberOfMembers = len(t.members)
+	team.Embedded.Members = nil
+	for _, m := range t.members {
+		if u, ok := f.users[m]; ok {
+			team.Embedded.Members = append(team.Embedded.Members, teamUser(u.user))
+		}
+	}
+
+	return &team, nil
+}
+
+// teamFromRequest applies a create or update request to a team. The mutex must be held
+func (f *Fake) teamFromRequest(t broker.TeamCreateOrUpdateRequest) broker.Team {
+	team := broker.Team{UUID: t.UUID, Name: t.Name}
+	for _, name := range t.PacticipantNames {
+		team.Embedded.Pacticipants = append(team.Embedded.Pacticipants, broker.Pacticipant{Name: name})
+	}
+	for _, uuid := range t.AdministratorUUIDs {
+		if u, ok := f.users[uuid]; ok {
+			team.Embedded.Administrators = append(team.Embedded.Administrators, teamUser(u.user))
+		} else {
+			team.Embedded.Administrators = append(team.Embedded.Administrators, broker.TeamUser{UUID: uuid})
+		}
+	}
+	for _, uuid := range t.EnvironmentUUIDs {
+		if e, ok := f.environments[uuid]; ok {
+			team.Embedded.Environments = append(team.Embedded.Environments, broker.TeamEnvironment{
+				UUID:        uuid,
+				Name:        e.environment.Name,
+				DisplayName: e.environment.DisplayName,
+				Production:  e.environment.Production,
+			})
+		} else {
+			team.Embedded.Environments = append(team.Embedded.Environments, broker.TeamEnvironment{UUID: uuid})
+		}
+	}
+
+	return team
+}
+
+func teamUser(u broker.User) broker.TeamUser {
+	return broker.TeamUser{
+		UUID:   u.UUID,
+		Name:   u.Name,
+		Email:  u.Email,
+		Active: u.Active,
+		Type:   u.Type,
+	}
+}
+
+// teamsAssignment lists the members of a team. The mutex must be held
+func (f *Fake) teamsAssignment(uuid string) *broker.TeamsAssignmentResponse {
+	res := &broker.TeamsAssignmentResponse{}
+	for _, m := range f.teams[uuid].members {
+		if u, ok := f.users[m]; ok {
+			res.Embedded.Users = append(res.Embedded.Users, teamUser(u.user))
+		}
+	}
+
+	return res
+}
+
+// ReadTeam gets a team by UUID, including its members
+func (f *Fake) ReadTeam(t broker.Team) (*broker.Team, error) {
+	return f.ReadTeamWithContext(f.BaseContext(), t)
+}
+
+// ReadTeamWithContext is the same as ReadTeam, with the given context controlling cancellation and deadlines
+func (f *Fake) ReadTeamWithContext(ctx context.Context, t broker.Team) (*broker.Team, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if err := f.call(ctx, "ReadTeam"); err != nil {
+		return nil, err
+	}
+
+	return f.readTeam(t.UUID)
+}
+
+// CreateTeam stores a new team, generating its UUID
+func (f *Fake) CreateTeam(t broker.TeamCreateOrUpdateRequest) (*broker.Team, error) {
+	return f.CreateTeamWithContext(f.BaseContext(), t)
+}
+
+// CreateTeamWithContext is the same as CreateTeam, with the given context controlling cancellation and deadlines
+func (f *Fake) CreateTeamWithContext(ctx context.Context, t broker.TeamCreateOrUpdateRequest) (*broker.Team, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if err := f.call(ctx, "CreateTeam"); err != nil {
+		return nil, err
+	}
+	t.UUID = f.newUUID()
+	f.teams[t.UUID] = &fakeTeam{team: f.teamFromRequest(t)}
+
+	return f.readTeam(t.UUID)
+}
+
+// UpdateTeam replaces the name, applications, administrators and environments of an existing team
+func (f *Fake) UpdateTeam(t broker.TeamCreateOrUpdateRequest) (*broker.Team, error) {
+	return f.UpdateTeamWithContext(f.BaseContext(), t)
+}
+
+// UpdateTeamWithContext is the same as UpdateTeam, with the given context controlling cancellation and deadlines
+func (f *Fake) UpdateTeamWithContext(ctx context.Context, t broker.TeamCreateOrUpdateRequest) (*broker.Team, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if err := f.call(ctx, "UpdateTeam"); err != nil {
+		return nil, err
+	}
+	existing, ok := f.teams[t.UUID]
+	if !ok {
+		return nil, notFound("team", t.UUID)
+	}
+	existing.team = f.teamFromRequest(t)
+
+	return f.readTeam(t.UUID)
+}
+
+// DeleteTeam removes a team
+func (f *Fake) DeleteTeam(t broker.Team) error {
+	return f.DeleteTeamWithContext(f.BaseContext(), t)
+}
+
+// DeleteTeamWithContext is the same as DeleteTeam, with the given context controlling cancellation and deadlines
+func (f *Fake) DeleteTeamWithContext(ctx context.Context, t broker.Team) error {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if err := f.call(ctx, "DeleteTeam"); err != nil {
+		return err
+	}
+	if _, ok := f.teams[t.UUID]; !ok {
+		return notFound("team", t.UUID)
+	}
+	delete(f.teams, t.UUID)
+
+	return nil
+}
+
+// ReadTeamAssignments lists the members of a team
+func (f *Fake) ReadTeamAssignments(t broker.Team) (*broker.TeamsAssignmentResponse, error) {
+	return f.ReadTeamAssignmentsWithContext(f.BaseContext(), t)
+}
+
+// ReadTeamAssignmentsWithContext is the same as ReadTeamAssignments, with the given context controlling cancellation and deadlines
+func (f *Fake) ReadTeamAssignmentsWithContext(ctx context.Context, t broker.Team) (*broker.TeamsAssignmentResponse, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if err := f.call(ctx, "ReadTeamAssignments"); err != nil {
+		return nil, err
+	}
+	if _, ok := f.teams[t.UUID]; !ok {
+		return nil, notFound("team", t.UUID)
+	}
+
+	return f.teamsAssignment(t.UUID), nil
+}
+
+// UpdateTeamAssignments replaces the members of a team
+func (f *Fake) UpdateTeamAssignments(r broker.TeamsAssignmentRequest) (*broker.TeamsAssignmentResponse, error) {
+	return f.UpdateTeamAssignmentsWithContext(f.BaseContext(), r)
+}
+
+// UpdateTeamAssignmentsWithContext is the same as UpdateTeamAssignments, with the given context controlling cancellation and deadlines
+func (f *Fake) UpdateTeamAssignmentsWithContext(ctx context.Context, r broker.TeamsAssignmentRequest) (*broker.TeamsAssignmentResponse, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if err := f.call(ctx, "UpdateTeamAssignments"); err != nil {
+		return nil, err
+	}
+	t, ok := f.teams[r.UUID]
+	if !ok {
+		return nil, notFound("team", r.UUID)
+	}
+	t.members = append([]string{}, r.Users...)
+
+	return f.teamsAssignment(r.UUID), nil
+}
+
+// AppendTeamAssignments adds members to a team
+func (f *Fake) AppendTeamAssignments(r broker.TeamsAssignmentRequest) (*broker.TeamsAssignmentResponse, error) {
+	return f.AppendTeamAssignmentsWithContext(f.BaseContext(), r)
+}
+
+// AppendTeamAssignmentsWithContext is the same as AppendTeamAssignments, with the given context controlling cancellation and deadlines
+func (f *Fake) AppendTeamAssignmentsWithContext(ctx context.Context, r broker.TeamsAssignmentRequest) (*broker.TeamsAssignmentResponse, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if err := f.call(ctx, "AppendTeamAssignments"); err != nil {
+		return nil, err
+	}
+	t, ok := f.teams[r.UUID]
+	if !ok {
+		return nil, notFound("team", r.UUID)
+	}
+	for _, u := range r.Users {
+		if !contains(t.members, u) {
+			t.members = append(t.members, u)
+		}
+	}
+
+	return f.teamsAssignment(r.UUID), nil
+}
+
+// DeleteTeamAssignment removes a single member from a team
+func (f *Fake) DeleteTeamAssignment(t broker.Team, u broker.User) error {
+	return f.DeleteTeamAssignmentWithContext(f.BaseContext(), t, u)
+}
+
+// DeleteTeamAssignmentWithContext is the same as DeleteTeamAssignment, with the given context controlling cancellation and deadlines
+func (f *Fake) DeleteTeamAssignmentWithContext(ctx context.Context, t broker.Team, u broker.User) error {
+	return f.DeleteTeamAssignmentsWithContext(ctx, broker.TeamsAssignmentRequest{UUID: t.UUID, Users: []string{u.UUID}})
+}
+
+// DeleteTeamAssignments removes members from a team
+func (f *Fake) DeleteTeamAssignments(r broker.TeamsAssignmentRequest) error {
+	return f.DeleteTeamAssignmentsWithContext(f.BaseContext(), r)
+}
+
+// DeleteTeamAssignmentsWithContext is the same as DeleteTeamAssignments, with the given context controlling cancellation and deadlines
+func (f *Fake) DeleteTeamAssignmentsWithContext(ctx context.Context, r broker.TeamsAssignmentRequest) error {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if err := f.call(ctx, "DeleteTeamAssignments"); err != nil {
+		return err
+	}
+	t, ok := f.teams[r.UUID]
+	if !ok {
+		return notFound("team", r.UUID)
+	}
+	members := []string{}
+	for _, m := range t.members {
+		if !contains(r.Users, m) {
+			members = append(members, m)
+		}
+	}
+	t.members = members
+
+	return nil
+}
+
+// ListTeams returns an iterator over the teams matching the options, in UUID order
+func (f *Fake) ListTeams(opts client.ListOptions) iter.Seq2[broker.Team, error] {
+	return f.ListTeamsWithContext(f.BaseContext(), opts)
+}
+
+// ListTeamsWithContext is the same as ListTeams, with the given context controlling cancellation and deadlines
+func (f *Fake) ListTeamsWithContext(ctx context.Context, opts client.ListOptions) iter.Seq2[broker.Team, error] {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	items := map[string]broker.Team{}
+	err := f.call(ctx, "ListTeams")
+	for uuid, t := range f.teams {
+		if matches(opts, t.team.Name, []string{uuid}) {
+			team, _ := f.readTeam(uuid)
+			items[uuid] = *team
+		}
+	}
+
+	return seq(items, err)
+}
+
+// ReadRole gets a role by UUID
+func (f *Fake) ReadRole(uuid string) (*broker.Role, error) {
+	return f.ReadRoleWithContext(f.BaseContext(), uuid)
+}
+
+// ReadRoleWithContext is the same as ReadRole, with the given context controlling cancellation and deadlines
+func (f *Fake) ReadRoleWithContext(ctx context.Context, uuid string) (*broker.Role, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if err := f.call(ctx, "ReadRole"); err != nil {
+		return nil, err
+	}
+	r, ok := f.roles[uuid]
+	if !ok {
+		return nil, notFound("role", uuid)
+	}
+
+	return &r, nil
+}
+
+// CreateRole stores a new role, generating its UUID
+func (f *Fake) CreateRole(r broker.Role) (*broker.Role, error) {
+	return f.CreateRoleWithContext(f.BaseContext(), r)
+}
+
+// CreateRoleWithContext is the same as CreateRole, with the given context controlling cancellation and deadlines
+func (f *Fake) CreateRoleWithContext(ctx context.Context, r broker.Role) (*broker.Role, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if err := f.call(ctx, "CreateRole"); err != nil {
+		return nil, err
+	}
+	r.UUID = f.newUUID()
+	f.roles[r.UUID] = r
+
+	return &r, nil
+}
+
+// UpdateRole replaces an existing role
+func (f *Fake) UpdateRole(r broker.Role) (*broker.Role, error) {
+	return f.UpdateRoleWithContext(f.BaseContext(), r)
+}
+
+// UpdateRoleWithContext is the same as UpdateRole, with the given context controlling cancellation and deadlines
+func (f *Fake) UpdateRoleWithContext(ctx context.Context, r broker.Role) (*broker.Role, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if err := f.call(ctx, "UpdateRole"); err != nil {
+		return nil, err
+	}
+	if _, ok := f.roles[r.UUID]; !ok {
+		return nil, notFound("role", r.UUID)
+	}
+	f.roles[r.UUID] = r
+
+	return &r, nil
+}
+
+// DeleteRole removes a role
+func (f *Fake) DeleteRole(r broker.Role) error {
+	return f.DeleteRoleWithContext(f.BaseContext(), r)
+}
+
+// DeleteRoleWithContext is the same as DeleteRole, with the given context controlling cancellation and deadlines
+func (f *Fake) DeleteRoleWithContext(ctx context.Context, r broker.Role) error {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if err := f.call(ctx, "DeleteRole"); err != nil {
+		return err
+	}
+	if _, ok := f.roles[r.UUID]; !ok {
+		return notFound("role", r.UUID)
+	}
+	delete(f.roles, r.UUID)
+
+	return nil
+}
+
+// ListRoles returns an iterator over the roles matching the options, in UUID order
+func (f *Fake) ListRoles(opts client.ListOptions) iter.Seq2[broker.Role, error] {
+	return f.ListRolesWithContext(f.BaseContext(), opts)
+}
+
+// ListRolesWithContext is the same as ListRoles, with the given context controlling cancellation and deadlines
+func (f *Fake) ListRolesWithContext(ctx context.Context, opts client.ListOptions) iter.Seq2[broker.Role, error] {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	items := map[string]broker.Role{}
+	err := f.call(ctx, "ListRoles")
+	for uuid, r := range f.roles {
+		if matches(opts, r.Name, nil) {
+			items[uuid] = r
+		}
+	}
+
+	return seq(items, err)
+}
+
+// readUser builds the user as returned by the broker, with its roles and teams embedded. The mutex must be held
+func (f *Fake) readUser(uuid string) (*broker.User, error) {
+	u, ok := f.users[uuid]
+	if !ok {
+		return nil, notFound("user", uuid)
+	}
+
+	user := u.user
+	user.Embedded.Roles = nil
+	user.Embedded.Teams = nil
+	for _, r := range u.roles {
+		role, ok := f.roles[r]
+		if !ok {
+			role = broker.Role{UUID: r}
+		}
+		user.Embedded.Roles = append(user.Embedded.Roles, role)
+	}
+	teams := make([]string, 0, len(f.teams))
+	for t := range f.teams {
+		teams = append(teams, t)
+	}
+	sort.Strings(teams)
+	for _, t := range teams {
+		if contains(f.teams[t].members, uuid) {
+			user.Embedded.Teams = append(user.Embedded.Teams, broker.Team{UUID: t, Name: f.teams[t].team.Name})
+		}
+	}
+
+	return &user, nil
+}
+
+// ReadUser gets a user by UUID, including their roles and teams
+func (f *Fake) ReadUser(uuid string) (*broker.User, error) {
+	return f.ReadUserWithContext(f.BaseContext(), uuid)
+}
+
+// ReadUserWithContext is the same as ReadUser, with the given context controlling cancellation and deadlines
+func (f *Fake) ReadUserWithContext(ctx context.Context, uuid string) (*broker.User, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if err := f.call(ctx, "ReadUser"); err != nil {
+		return nil, err
+	}
+
+	return f.readUser(uuid)
+}
+
+// CreateUser stores a new user or system account, generating its UUID
+func (f *Fake) CreateUser(u broker.User) (*broker.User, error) {
+	return f.CreateUserWithContext(f.BaseContext(), u)
+}
+
+// CreateUserWithContext is the same as CreateUser, with the given context controlling cancellation and deadlines
+func (f *Fake) CreateUserWithContext(ctx context.Context, u broker.User) (*broker.User, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if err := f.call(ctx, "CreateUser"); err != nil {
+		return nil, err
+	}
+
+	return f.createUser(u)
+}
+
+// CreateSystemAccount stores a new system account, generating its UUID
+func (f *Fake) CreateSystemAccount(u broker.User) (*broker.User, error) {
+	return f.CreateSystemAccountWithContext(f.BaseContext(), u)
+}
+
+// CreateSystemAccountWithContext is the same as CreateSystemAccount, with the given context controlling cancellation and deadlines
+func (f *Fake) CreateSystemAccountWithContext(ctx context.Context, u broker.User) (*broker.User, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if err := f.call(ctx, "CreateSystemAccount"); err != nil {
+		return nil, err
+	}
+	u.Type = broker.SystemAccount
+
+	created, err := f.createUser(u)
+	if err == nil {
+		// System accounts are used by CI, so are given a read-write token
+		f.users[created.UUID].tokens = []broker.APIToken{{UUID: f.newUUID(), Description: tokenDescriptions["read-write"], Value: f.newUUID()}}
+	}
+
+	return created, err
+}
+
+// createUser stores a new user. The mutex must be held
+func (f *Fake) createUser(u broker.User) (*broker.User, error) {
+	u.UUID = f.newUUID()
+	record := &fakeUser{user: u}
+	for _, r := range u.Embedded.Roles {
+		record.roles = append(record.roles, r.UUID)
+	}
+	f.users[u.UUID] = record
+
+	return f.readUser(u.UUID)
+}
+
+// UpdateUser updates an existing user. As with the broker, roles and teams are managed separately
+func (f *Fake) UpdateUser(u broker.User) (*broker.User, error) {
+	return f.UpdateUserWithContext(f.BaseContext(), u)
+}
+
+// UpdateUserWithContext is the same as UpdateUser, with the given context controlling cancellation and deadlines
+func (f *Fake) UpdateUserWithContext(ctx context.Context, u broker.User) (*broker.User, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if err := f.call(ctx, "UpdateUser"); err != nil {
+		return nil, err
+	}
+
+	return f.updateUser(u)
+}
+
+// updateUser replaces the user's details. The mutex must be held
+func (f *Fake) updateUser(u broker.User) (*broker.User, error) {
+	existing, ok := f.users[u.UUID]
+	if !ok {
+		return nil, notFound("user", u.UUID)
+	}
+	existing.user = u
+
+	return f.readUser(u.UUID)
+}
+
+// DeleteUser de-activates a user, as users are never deleted by the broker
+func (f *Fake) DeleteUser(u broker.User) error {
+	return f.DeleteUserWithContext(f.BaseContext(), u)
+}
+
+// DeleteUserWithContext is the same as DeleteUser, with the given context controlling cancellation and deadlines
+func (f *Fake) DeleteUserWithContext(ctx context.Context, u broker.User) error {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if err := f.call(ctx, "DeleteUser"); err != nil {
+		return err
+	}
+	u.Active = false
+	_, err := f.updateUser(u)
+
+	return err
+}
+
+// AddAdminRoleToUser grants the administrator role to a user
+func (f *Fake) AddAdminRoleToUser(u broker.User) (*broker.User, error) {
+	return f.AddAdminRoleToUserWithContext(f.BaseContext(), u)
+}
+
+// AddAdminRoleToUserWithContext is the same as AddAdminRoleToUser, with the given context controlling cancellation and deadlines
+func (f *Fake) AddAdminRoleToUserWithContext(ctx context.Context, u broker.User) (*broker.User, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if err := f.call(ctx, "AddAdminRoleToUser"); err != nil {
+		return nil, err
+	}
+	existing, ok := f.users[u.UUID]
+	if !ok {
+		return nil, notFound("user", u.UUID)
+	}
+	if !contains(existing.roles, AdministratorRoleUUID) {
+		existing.roles = append(existing.roles, AdministratorRoleUUID)
+	}
+
+	return f.readUser(u.UUID)
+}
+
+// RemoveAdminRoleFromUser revokes the administrator role from a user
+func (f *Fake) RemoveAdminRoleFromUser(u broker.User) (*broker.User, error) {
+	return f.RemoveAdminRoleFromUserWithContext(f.BaseContext(), u)
+}
+
+// RemoveAdminRoleFromUserWithContext is the same as RemoveAdminRoleFromUser, with the given context controlling cancellation and deadlines
+func (f *Fake) RemoveAdminRoleFromUserWithContext(ctx context.Context, u broker.User) (*broker.User, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if err := f.call(ctx, "RemoveAdminRoleFromUser"); err != nil {
+		return nil, err
+	}
+	existing, ok := f.users[u.UUID]
+	if !ok {
+		return nil, notFound("user", u.UUID)
+	}
+	roles := []string{}
+	for _, r := range existing.roles {
+		if r != AdministratorRoleUUID {
+			roles = append(roles, r)
+		}
+	}
+	existing.roles = roles
+
+	return f.readUser(u.UUID)
+}
+
+// SetUserRoles replaces the roles of a user
+func (f *Fake) SetUserRoles(uuid string, r broker.SetUserRolesRequest) error {
+	return f.SetUserRolesWithContext(f.BaseContext(), uuid, r)
+}
+
+// SetUserRolesWithContext is the same as SetUserRoles, with the given context controlling cancellation and deadlines
+func (f *Fake) SetUserRolesWithContext(ctx context.Context, uuid string, r broker.SetUserRolesRequest) error {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if err := f.call(ctx, "SetUserRoles"); err != nil {
+		return err
+	}
+	existing, ok := f.users[uuid]
+	if !ok {
+		return notFound("user", uuid)
+	}
+	existing.roles = append([]string{}, r.Roles...)
+
+	return nil
+}
+
+// ListUsers returns an iterator over the users matching the options, in UUID order
+func (f *Fake) ListUsers(opts client.ListOptions) iter.Seq2[broker.User, error] {
+	return f.ListUsersWithContext(f.BaseContext(), opts)
+}
+
+// ListUsersWithContext is the same as ListUsers, with the given context controlling cancellation and deadlines
+func (f *Fake) ListUsersWithContext(ctx context.Context, opts client.ListOptions) iter.Seq2[broker.User, error] {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	items := map[string]broker.User{}
+	err := f.call(ctx, "ListUsers")
+	for uuid := range f.users {
+		user, _ := f.readUser(uuid)
+		teams := []string{}
+		for _, t := range user.Embedded.Teams {
+			teams = append(teams, t.UUID)
+		}
+		if matches(opts, user.Name, teams) {
+			items[uuid] = *user
+		}
+	}
+
+	return seq(items, err)
+}
+
+// ReadSecret gets a secret by UUID. As with the broker, the value of the secret is not returned
+func (f *Fake) ReadSecret(uuid string) (*broker.SecretResponse, error) {
+	return f.ReadSecretWithContext(f.BaseContext(), uuid)
+}
+
+// ReadSecretWithContext is the same as ReadSecret, with the given context controlling cancellation and deadlines
+func (f *Fake) ReadSecretWithContext(ctx context.Context, uuid string) (*broker.SecretResponse, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if err := f.call(ctx, "ReadSecret"); err != nil {
+		return nil, err
+	}
+
+	return f.secretResponse(uuid)
+}
+
+// secretResponse builds the secret as returned by the broker, without its value. The mutex must be held
+func (f *Fake) secretResponse(uuid string) (*broker.SecretResponse, error) {
+	s, ok := f.secrets[uuid]
+	if !ok {
+		return nil, notFound("secret", uuid)
+	}
+	s.Value = ""
+
+	return &broker.SecretResponse{Secret: s, HalDoc: f.self("/secrets/" + uuid)}, nil
+}
+
+// Secret returns the stored secret, including its value, which is otherwise never returned
+func (f *Fake) Secret(uuid string) (broker.Secret, bool) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	s, ok := f.secrets[uuid]
+	return s, ok
+}
+
+// CreateSecret stores a new secret, generating its UUID. The UUID is only available via the self link, as for the broker
+func (f *Fake) CreateSecret(s broker.Secret) (*broker.SecretResponse, error) {
+	return f.CreateSecretWithContext(f.BaseContext(), s)
+}
+
+// CreateSecretWithContext is the same as CreateSecret, with the given context controlling cancellation and deadlines
+func (f *Fake) CreateSecretWithContext(ctx context.Context, s broker.Secret) (*broker.SecretResponse, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if err := f.call(ctx, "CreateSecret"); err != nil {
+		return nil, err
+	}
+	s.UUID = f.newUUID()
+	f.secrets[s.UUID] = s
+
+	return f.secretResponse(s.UUID)
+}
+
+// UpdateSecret replaces an existing secret
+func (f *Fake) UpdateSecret(s broker.Secret) (*broker.SecretResponse, error) {
+	return f.UpdateSecretWithContext(f.BaseContext(), s)
+}
+
+// UpdateSecretWithContext is the same as UpdateSecret, with the given context controlling cancellation and deadlines
+func (f *Fake) UpdateSecretWithContext(ctx context.Context, s broker.Secret) (*broker.SecretResponse, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if err := f.call(ctx, "UpdateSecret"); err != nil {
+		return nil, err
+	}
+	if _, ok := f.secrets[s.UUID]; !ok {
+		return nil, notFound("secret", s.UUID)
+	}
+	f.secrets[s.UUID] = s
+
+	return f.secretResponse(s.UUID)
+}
+
+// DeleteSecret removes a secret
+func (f *Fake) DeleteSecret(s broker.Secret) error {
+	return f.DeleteSecretWithContext(f.BaseContext(), s)
+}
+
+// DeleteSecretWithContext is the same as DeleteSecret, with the given context controlling cancellation and deadlines
+func (f *Fake) DeleteSecretWithContext(ctx context.Context, s broker.Secret) error {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if err := f.call(ctx, "DeleteSecret"); err != nil {
+		return err
+	}
+	if _, ok := f.secrets[s.UUID]; !ok {
+		return notFound("secret", s.UUID)
+	}
+	delete(f.secrets, s.UUID)
+
+	return nil
+}
+
+// ListSecrets returns an iterator over the secrets matching the options, in UUID order, without their values
+func (f *Fake) ListSecrets(opts client.ListOptions) iter.Seq2[broker.Secret, error] {
+	return f.ListSecretsWithContext(f.BaseContext(), opts)
+}
+
+// ListSecretsWithContext is the same as ListSecrets, with the given context controlling cancellation and deadlines
+func (f *Fake) ListSecretsWithContext(ctx context.Context, opts client.ListOptions) iter.Seq2[broker.Secret, error] {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	items := map[string]broker.Secret{}
+	err := f.call(ctx, "ListSecrets")
+	for uuid, s := range f.secrets {
+		if matches(opts, s.Name, []string{s.TeamUUID}) {
+			s.Value = ""
+			items[uuid] = s
+		}
+	}
+
+	return seq(items, err)
+}
+
+// ReadTokens lists the tokens of the authenticated user
+func (f *Fake) ReadTokens() (*broker.APITokensResponse, error) {
+	return f.ReadTokensWithContext(f.BaseContext())
+}
+
+// ReadTokensWithContext is the same as ReadTokens, with the given context controlling cancellation and deadlines
+func (f *Fake) ReadTokensWithContext(ctx context.Context) (*broker.APITokensResponse, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if err := f.call(ctx, "ReadTokens"); err != nil {
+		return nil, err
+	}
+
+	return f.readTokens(), nil
+}
+
+// readTokens lists the tokens in UUID order. The mutex must be held
+func (f *Fake) readTokens() *broker.APITokensResponse {
+	res := &broker.APITokensResponse{}
+	for t, err := range seq(f.tokens, nil) {
+		if err == nil {
+			res.Embedded.Items = append(res.Embedded.Items, t)
+		}
+	}
+
+	return res
+}
+
+// ReadToken gets a token by UUID
+func (f *Fake) ReadToken(uuid string) (*broker.APIToken, error) {
+	return f.ReadTokenWithContext(f.BaseContext(), uuid)
+}
+
+// ReadTokenWithContext is the same as ReadToken, with the given context controlling cancellation and deadlines
+func (f *Fake) ReadTokenWithContext(ctx context.Context, uuid string) (*broker.APIToken, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if err := f.call(ctx, "ReadToken"); err != nil {
+		return nil, err
+	}
+	t, ok := f.tokens[uuid]
+	if !ok {
+		return nil, notFound("token with uuid", uuid)
+	}
+
+	return &t, nil
+}
+
+// FindTokenByType gets the read-only or read-write token
+func (f *Fake) FindTokenByType(tokenType string) (*broker.APIToken, error) {
+	return f.FindTokenByTypeWithContext(f.BaseContext(), tokenType)
+}
+
+// FindTokenByTypeWithContext is the same as FindTokenByType, with the given context controlling cancellation and deadlines
+func (f *Fake) FindTokenByTypeWithContext(ctx context.Context, tokenType string) (*broker.APIToken, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if err := f.call(ctx, "FindTokenByType"); err != nil {
+		return nil, err
+	}
+	description, ok := tokenDescriptions[tokenType]
+	if !ok {
+		return nil, fmt.Errorf("invalid token type specified, need one of %v, got %s", tokenDescriptions, tokenType)
+	}
+	for _, t := range f.readTokens().Embedded.Items {
+		if t.Description == description {
+			return &t, nil
+		}
+	}
+
+	return nil, fmt.Errorf("token of type %s not found", tokenType)
+}
+
+// RegenerateToken replaces the value of a token
+func (f *Fake) RegenerateToken(t broker.APIToken) (*broker.APITokenResponse, error) {
+	return f.RegenerateTokenWithContext(f.BaseContext(), t)
+}
+
+// RegenerateTokenWithContext is the same as RegenerateToken, with the given context controlling cancellation and deadlines
+func (f *Fake) RegenerateTokenWithContext(ctx context.Context, t broker.APIToken) (*broker.APITokenResponse, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if err := f.call(ctx, "RegenerateToken"); err != nil {
+		return nil, err
+	}
+	existing, ok := f.tokens[t.UUID]
+	if !ok {
+		return nil, notFound("token with uuid", t.UUID)
+	}
+	existing.Value = f.newUUID()
+	f.tokens[t.UUID] = existing
+
+	return &broker.APITokenResponse{APIToken: existing, HalDoc: f.self("/settings/tokens/" + t.UUID)}, nil
+}
+
+// ReadSystemAccountTokens lists the tokens of a system account
+func (f *Fake) ReadSystemAccountTokens(uuid string) (*broker.APITokensResponse, error) {
+	return f.ReadSystemAccountTokensWithContext(f.BaseContext(), uuid)
+}
+
+// ReadSystemAccountTokensWithContext is the same as ReadSystemAccountTokens, with the given context controlling cancellation and deadlines
+func (f *Fake) ReadSystemAccountTokensWithContext(ctx context.Context, uuid string) (*broker.APITokensResponse, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if err := f.call(ctx, "ReadSystemAccountTokens"); err != nil {
+		return nil, err
+	}
+	record, ok := f.users[uuid]
+	if !ok || record.user.Type != broker.SystemAccount {
+		return nil, notFound("system account", uuid)
+	}
+
+	res := &broker.APITokensResponse{HalDoc: f.self("/admin/system-accounts/" + uuid + "/tokens")}
+	res.Embedded.Items = append([]broker.APIToken{}, record.tokens...)
+
+	return res, nil
+}
+
+// ReadTenantAuthenticationSettings gets the authentication settings
+func (f *Fake) ReadTenantAuthenticationSettings() (*broker.AuthenticationSettings, error) {
+	return f.ReadTenantAuthenticationSettingsWithContext(f.BaseContext())
+}
+
+// ReadTenantAuthenticationSettingsWithContext is the same as ReadTenantAuthenticationSettings, with the given context controlling cancellation and deadlines
+func (f *Fake) ReadTenantAuthenticationSettingsWithContext(ctx context.Context) (*broker.AuthenticationSettings, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if err := f.call(ctx, "ReadTenantAuthenticationSettings"); err != nil {
+		return nil, err
+	}
+	settings := f.auth
+
+	return &settings, nil
+}
+
+// SetTenantAuthenticationSettings replaces the authentication settings
+func (f *Fake) SetTenantAuthenticationSettings(r broker.AuthenticationSettings) (*broker.AuthenticationSettings, error) {
+	return f.SetTenantAuthenticationSettingsWithContext(f.BaseContext(), r)
+}
+
+// SetTenantAuthenticationSettingsWithContext is the same as SetTenantAuthenticationSettings, with the given context controlling cancellation and deadlines
+func (f *Fake) SetTenantAuthenticationSettingsWithContext(ctx context.Context, r broker.AuthenticationSettings) (*broker.AuthenticationSettings, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if err := f.call(ctx, "SetTenantAuthenticationSettings"); err != nil {
+		return nil, err
+	}
+	f.auth = r
+
+	return &r, nil
+}
+
+// readEnvironment builds the environment as returned by the broker, with its teams embedded. The mutex must be held
+func (f *Fake) readEnvironment(uuid string) (*broker.Environment, error) {
+	e, ok := f.environments[uuid]
+	if !ok {
+		return nil, notFound("environment", uuid)
+	}
+
+	environment := e.environment
+	environment.Embedded.Teams = nil
+	for _, t := range e.teams {
+		team := broker.EnvironmentEmbeddedTeams{UUID: t}
+		if existing, ok := f.teams[t]; ok {
+			team.Name = existing.team.Name
+		}
+		environment.Embedded.Teams = append(environment.Embedded.Teams, team)
+	}
+
+	return &environment, nil
+}
+
+// environmentResponse builds the response to creating or updating an environment. The mutex must be held
+func (f *Fake) environmentResponse(uuid string) *broker.EnvironmentCreateOrUpdateResponse {
+	e, _ := f.readEnvironment(uuid)
+
+	return &broker.EnvironmentCreateOrUpdateResponse{
+		UUID:        e.UUID,
+		Name:        e.Name,
+		DisplayName: e.DisplayName,
+		Production:  e.Production,
+		Teams:       append([]string{}, f.environments[uuid].teams...),
+		Embedded:    e.Embedded,
+	}
+}
+
+// ReadEnvironment gets an environment by UUID
+func (f *Fake) ReadEnvironment(uuid string) (*broker.Environment, error) {
+	return f.ReadEnvironmentWithContext(f.BaseContext(), uuid)
+}
+
+// ReadEnvironmentWithContext is the same as ReadEnvironment, with the given context controlling cancellation and deadlines
+func (f *Fake) ReadEnvironmentWithContext(ctx context.Context, uuid string) (*broker.Environment, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if err := f.call(ctx, "ReadEnvironment"); err != nil {
+		return nil, err
+	}
+
+	return f.readEnvironment(uuid)
+}
+
+// CreateEnvironment stores a new environment, generating its UUID
+func (f *Fake) CreateEnvironment(e broker.EnvironmentCreateOrUpdateRequest) (*broker.EnvironmentCreateOrUpdateResponse, error) {
+	return f.CreateEnvironmentWithContext(f.BaseContext(), e)
+}
+
+// CreateEnvironmentWithContext is the same as CreateEnvironment, with the given context controlling cancellation and deadlines
+func (f *Fake) CreateEnvironmentWithContext(ctx context.Context, e broker.EnvironmentCreateOrUpdateRequest) (*broker.EnvironmentCreateOrUpdateResponse, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if err := f.call(ctx, "CreateEnvironment"); err != nil {
+		return nil, err
+	}
+	e.UUID = f.newUUID()
+	f.environments[e.UUID] = environmentFromRequest(e)
+
+	return f.environmentResponse(e.UUID), nil
+}
+
+// UpdateEnvironment replaces an existing environment
+func (f *Fake) UpdateEnvironment(e broker.EnvironmentCreateOrUpdateRequest) (*broker.EnvironmentCreateOrUpdateResponse, error) {
+	return f.UpdateEnvironmentWithContext(f.BaseContext(), e)
+}
+
+// UpdateEnvironmentWithContext is the same as UpdateEnvironment, with the given context controlling cancellation and deadlines
+func (f *Fake) UpdateEnvironmentWithContext(ctx context.Context, e broker.EnvironmentCreateOrUpdateRequest) (*broker.EnvironmentCreateOrUpdateResponse, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if err := f.call(ctx, "UpdateEnvironment"); err != nil {
+		return nil, err
+	}
+	if _, ok := f.environments[e.UUID]; !ok {
+		return nil, notFound("environment", e.UUID)
+	}
+	f.environments[e.UUID] = environmentFromRequest(e)
+
+	return f.environmentResponse(e.UUID), nil
+}
+
+func environmentFromRequest(e broker.EnvironmentCreateOrUpdateRequest) *fakeEnvironment {
+	return &fakeEnvironment{
+		environment: broker.Environment{
+			UUID:        e.UUID,
+			Name:        e.Name,
+			DisplayName: e.DisplayName,
+			Production:  e.Production,
+		},
+		teams: append([]string{}, e.Teams...),
+	}
+}
+
+// DeleteEnvironment removes an environment
+func (f *Fake) DeleteEnvironment(e broker.Environment) error {
+	return f.DeleteEnvironmentWithContext(f.BaseContext(), e)
+}
+
+// DeleteEnvironmentWithContext is the same as DeleteEnvironment, with the given context controlling cancellation and deadlines
+func (f *Fake) DeleteEnvironmentWithContext(ctx context.Context, e broker.Environment) error {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if err := f.call(ctx, "DeleteEnvironment"); err != nil {
+		return err
+	}
+	if _, ok := f.environments[e.UUID]; !ok {
+		return notFound("environment", e.UUID)
+	}
+	delete(f.environments, e.UUID)
+
+	return nil
+}
+
+// ListEnvironments returns an iterator over the environments matching the options, in UUID order
+func (f *Fake) ListEnvironments(opts client.ListOptions) iter.Seq2[broker.Environment, error] {
+	return f.ListEnvironmentsWithContext(f.BaseContext(), opts)
+}
+
+// ListEnvironmentsWithContext is the same as ListEnvironments, with the given context controlling cancellation and deadlines
+func (f *Fake) ListEnvironmentsWithContext(ctx context.Context, opts client.ListOptions) iter.Seq2[broker.Environment, error] {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	items := map[string]broker.Environment{}
+	err := f.call(ctx, "ListEnvironments")
+	for uuid, e := range f.environments {
+		if matches(opts, e.environment.Name, e.teams) {
+			environment, _ := f.readEnvironment(uuid)
+			items[uuid] = *environment
+		}
+	}
+
+	return seq(items, err)
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,102 @@
+package clienttest
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/pactflow/terraform/broker"
+	"github.com/pactflow/terraform/client"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFake(t *testing.T) {
+	t.Run("embeds the roles and teams of a user", func(t *testing.T) {
+		f := NewFake()
+		user, _ := f.CreateUser(broker.User{Name: "Jane", Email: "jane@example.com", Active: true})
+		team, _ := f.CreateTeam(broker.TeamCreateOrUpdateRequest{Name: "Team A"})
+		_, err := f.AppendTeamAssignments(broker.TeamsAssignmentRequest{UUID: team.UUID, Users: []string{user.UUID}})
+		assert.NoError(t, err)
+		_, err = f.AddAdminRoleToUser(*user)
+		assert.NoError(t, err)
+
+		res, err := f.ReadUser(user.UUID)
+
+		assert.NoError(t, err)
+		assert.Equal(t, []broker.Role{{UUID: AdministratorRoleUUID, Name: "Administrator"}}, res.Embedded.Roles)
+		assert.Equal(t, []broker.Team{{UUID: team.UUID, Name: "Team A"}}, res.Embedded.Teams)
+	})
+
+	t.Run("only returns the ID of a webhook via its self link", func(t *testing.T) {
+		f := NewFake()
+
+		res, err := f.CreateWebhook(broker.Webhook{Description: "notify CI"})
+		assert.NoError(t, err)
+
+		parts := strings.Split(res.Links["self"].Href, "/")
+		w, err := f.ReadWebhook(parts[len(parts)-1])
+		assert.NoError(t, err)
+		assert.Equal(t, "notify CI", w.Description)
+	})
+
+	t.Run("does not return the value of secrets", func(t *testing.T) {
+		f := NewFake()
+
+		res, _ := f.CreateSecret(broker.Secret{Name: "token", Value: "s3cr3t"})
+
+		assert.Empty(t, res.Value)
+		stored, _ := f.Secret(res.UUID)
+		assert.Equal(t, "s3cr3t", stored.Value)
+	})
+
+	t.Run("returns not found errors like the client", func(t *testing.T) {
+		_, err := NewFake().ReadRole("missing")
+
+		assert.ErrorIs(t, err, client.ErrNotFound)
+	})
+
+	t.Run("returns injected errors and records calls", func(t *testing.T) {
+		f := NewFake()
+		injected := errors.New("boom")
+		f.Errors["ListTeams"] = injected
+
+		for _, err := range f.ListTeamsWithContext(context.Background(), client.ListOptions{}) {
+			assert.ErrorIs(t, err, injected)
+		}
+		_, err := f.ReadTokens()
+
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"ListTeams", "ReadTokens"}, f.Calls)
+	})
+
+	t.Run("filters lists by name and team", func(t *testing.T) {
+		f := NewFake()
+		team, _ := f.CreateTeam(broker.TeamCreateOrUpdateRequest{Name: "Team A"})
+		f.CreateEnvironment(broker.EnvironmentCreateOrUpdateRequest{Name: "production", Teams: []string{team.UUID}})
+		f.CreateEnvironment(broker.EnvironmentCreateOrUpdateRequest{Name: "test", Teams: []string{"other"}})
+
+		var names []string
+		for e, err := range f.ListEnvironments(client.ListOptions{TeamUUID: team.UUID}) {
+			assert.NoError(t, err)
+			names = append(names, e.Name)
+		}
+
+		assert.Equal(t, []string{"production"}, names)
+	})
+
+	t.Run("filters lists by name prefix", func(t *testing.T) {
+		f := NewFake()
+		f.CreateRole(broker.Role{Name: "CI Maintainer"})
+		f.CreateRole(broker.Role{Name: "CI Viewer"})
+		f.CreateRole(broker.Role{Name: "Viewer"})
+
+		var names []string
+		for r, err := range f.ListRoles(client.ListOptions{NamePrefix: "CI "}) {
+			assert.NoError(t, err)
+			names = append(names, r.Name)
+		}
+
+		assert.ElementsMatch(t, []string{"CI Maintainer", "CI Viewer"}, names)
+	})
+}
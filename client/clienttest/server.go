@@ -0,0 +1,921 @@
+package clienttest
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"iter"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pactflow/terraform/broker"
+	"github.com/pactflow/terraform/client"
+)
+
+// defaultPageSize is the number of items in each page of a list, unless the pageSize parameter is given
+const defaultPageSize = 100
+
+// relations are the links in the broker index, relative to the broker root. Only those in Fake.Info.Relations are advertised
+var relations = map[string]broker.Link{
+	"pb:pacticipants": {Href: "/pacticipants", Title: "Pacticipants"},
+	"pb:pacticipant":  {Href: "/pacticipants/{pacticipant}", Title: "Fetch pacticipant by name", Templated: true},
+	"pb:webhooks":     {Href: "/webhooks", Title: "Webhooks"},
+	"pb:environments": {Href: "/environments", Title: "Environments"},
+	"pb:environment":  {Href: "/environments/{uuid}", Title: "Environment", Templated: true},
+	"pf:admin-users":  {Href: "/admin/users", Title: "Users"},
+	"pf:admin-teams":  {Href: "/admin/teams", Title: "Teams"},
+	"pf:admin-roles":  {Href: "/admin/roles", Title: "Roles"},
+}
+
+// Server is a fake broker served over HTTP, for testing the client (or anything built on it, such as the provider)
+// end to end without a broker. Requests are stored in Fake, which may also be used to seed or inspect the data
+type Server struct {
+	*httptest.Server
+	Fake *Fake
+}
+
+// NewServer starts a fake broker on a random local port, which accepts any credentials. Call Close when finished
+func NewServer() *Server {
+	f := NewFake()
+	s := &Server{Server: httptest.NewServer(NewHandler(f, "")), Fake: f}
+	f.baseURL, _ = url.Parse(s.URL)
+
+	return s
+}
+
+// NewHandler serves the broker API backed by the given fake. If token is set, requests must be authenticated
+// with it (or with the current value of one of the fake's API tokens), as a bearer token
+func NewHandler(f *Fake, token string) http.Handler {
+	h := &handler{fake: f, token: token}
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("GET /{$}", h.index)
+	mux.HandleFunc("GET /diagnostic/status/heartbeat", h.heartbeat)
+
+	mux.HandleFunc("GET /pacticipants", h.listPacticipants)
+	mux.HandleFunc("POST /pacticipants", h.createPacticipant)
+	mux.HandleFunc("GET /pacticipants/{name}", h.readPacticipant)
+	mux.HandleFunc("PATCH /pacticipants/{name}", h.updatePacticipant)
+	mux.HandleFunc("PUT /pacticipants/{name}", h.updatePacticipant)
+	mux.HandleFunc("DELETE /pacticipants/{name}", h.deletePacticipant)
+
+	mux.HandleFunc("GET /webhooks", h.listWebhooks)
+	mux.HandleFunc("POST /webhooks", h.createWebhook)
+	mux.HandleFunc("GET /webhooks/{id}", h.readWebhook)
+	mux.HandleFunc("PUT /webhooks/{id}", h.updateWebhook)
+	mux.HandleFunc("DELETE /webhooks/{id}", h.deleteWebhook)
+
+	mux.HandleFunc("GET /admin/teams", h.listTeams)
+	mux.HandleFunc("POST /admin/teams", h.createTeam)
+	mux.HandleFunc("GET /admin/teams/{uuid}", h.readTeam)
+	mux.HandleFunc("PUT /admin/teams/{uuid}", h.updateTeam)
+	mux.HandleFunc("DELETE /admin/teams/{uuid}", h.deleteTeam)
+	mux.HandleFunc("GET /admin/teams/{uuid}/users", h.readTeamAssignments)
+	mux.HandleFunc("PUT /admin/teams/{uuid}/users", h.updateTeamAssignments)
+	mux.HandleFunc("POST /admin/teams/{uuid}/users", h.appendTeamAssignments)
+	mux.HandleFunc("DELETE /admin/teams/{uuid}/users", h.deleteTeamAssignments)
+	mux.HandleFunc("DELETE /admin/teams/{uuid}/users/{user}", h.deleteTeamAssignment)
+
+	mux.HandleFunc("GET /admin/roles", h.listRoles)
+	mux.HandleFunc("POST /admin/roles", h.createRole)
+	mux.HandleFunc("GET /admin/roles/{uuid}", h.readRole)
+	mux.HandleFunc("PUT /admin/roles/{uuid}", h.updateRole)
+	mux.HandleFunc("DELETE /admin/roles/{uuid}", h.deleteRole)
+
+	mux.HandleFunc("GET /admin/users", h.listUsers)
+	mux.HandleFunc("POST /admin/users/invite-user", h.createUser)
+	mux.HandleFunc("POST /admin/system-accounts", h.createSystemAccount)
+	mux.HandleFunc("GET /admin/users/{uuid}", h.readUser)
+	mux.HandleFunc("PUT /admin/users/{uuid}", h.updateUser)
+	mux.HandleFunc("PUT /admin/users/{uuid}/roles", h.setUserRoles)
+	mux.HandleFunc("PUT /admin/users/{uuid}/role/admin", h.addAdminRole)
+	mux.HandleFunc("DELETE /admin/users/{uuid}/role/admin", h.removeAdminRole)
+
+	mux.HandleFunc("GET /secrets", h.listSecrets)
+	mux.HandleFunc("POST /secrets", h.createSecret)
+	mux.HandleFunc("GET /secrets/{uuid}", h.readSecret)
+	mux.HandleFunc("PUT /secrets/{uuid}", h.updateSecret)
+	mux.HandleFunc("DELETE /secrets/{uuid}", h.deleteSecret)
+
+	mux.HandleFunc("GET /settings/tokens", h.readTokens)
+	mux.HandleFunc("POST /settings/tokens/{uuid}/regenerate", h.regenerateToken)
+	mux.HandleFunc("GET /admin/system-accounts/{uuid}/tokens", h.readSystemAccountTokens)
+
+	mux.HandleFunc("GET /admin/tenant/authentication-settings", h.readAuthenticationSettings)
+	mux.HandleFunc("PUT /admin/tenant/authentication-settings", h.setAuthenticationSettings)
+
+	mux.HandleFunc("GET /environments", h.listEnvironments)
+	mux.HandleFunc("POST /environments", h.createEnvironment)
+	mux.HandleFunc("GET /environments/{uuid}", h.readEnvironment)
+	mux.HandleFunc("PUT /environments/{uuid}", h.updateEnvironment)
+	mux.HandleFunc("DELETE /environments/{uuid}", h.deleteEnvironment)
+
+	return h.authenticate(mux)
+}
+
+type handler struct {
+	fake  *Fake
+	token string
+}
+
+// authenticate rejects requests without a valid bearer token, except for the heartbeat which is always public
+func (h *handler) authenticate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		log.Printf("[DEBUG] fake broker request: method=%s path=%s", r.Method, r.URL.Path)
+
+		if h.token != "" && r.URL.Path != "/diagnostic/status/heartbeat" {
+			token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+			if token != h.token && !h.fake.acceptsToken(token) {
+				h.error(w, r, client.ErrUnauthorized)
+				return
+			}
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// acceptsToken checks whether the token is the current value of one of the API tokens
+func (f *Fake) acceptsToken(token string) bool {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	for _, t := range f.tokens {
+		if token != "" && t.Value == token {
+			return true
+		}
+	}
+
+	return false
+}
+
+// baseURL is the root of the broker, as seen by the client
+func baseURL(r *http.Request) string {
+	scheme := "http"
+	if r.TLS != nil {
+		scheme = "https"
+	}
+
+	return scheme + "://" + r.Host
+}
+
+func link(r *http.Request, format string, parameters ...interface{}) broker.Link {
+	return broker.Link{Href: baseURL(r) + fmt.Sprintf(format, parameters...)}
+}
+
+// idFromLink returns the last segment of a link, which the fake uses for the IDs of webhooks and secrets
+func idFromLink(l broker.Link) string {
+	return path.Base(l.Href)
+}
+
+// write responds with the entity as HAL, adding the given self link
+func (h *handler) write(w http.ResponseWriter, status int, v interface{}, self *broker.Link) {
+	var body interface{} = v
+	if self != nil {
+		// Round trip the entity to add the links alongside its properties
+		doc := map[string]interface{}{}
+		encoded, _ := json.Marshal(v)
+		json.Unmarshal(encoded, &doc)
+		doc["_links"] = broker.HalLinks{"self": *self}
+		body = doc
+	}
+
+	w.Header().Set("Content-Type", "application/hal+json;charset=utf-8")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(body)
+}
+
+// error responds with the status and body the broker uses for the class of error. The more specific 4xx errors
+// are checked before ErrBadRequest, as a client.APIError for them is also an ErrBadRequest
+func (h *handler) error(w http.ResponseWriter, r *http.Request, err error) {
+	status := http.StatusInternalServerError
+	var apiErr *client.APIError
+	switch {
+	case errors.As(err, &apiErr) && apiErr.StatusCode != 0:
+		status = apiErr.StatusCode
+	case errors.Is(err, client.ErrNotFound):
+		status = http.StatusNotFound
+	case errors.Is(err, client.ErrConflict):
+		status = http.StatusConflict
+	case errors.Is(err, client.ErrTooManyRequests):
+		status = http.StatusTooManyRequests
+	case errors.Is(err, client.ErrBadRequest):
+		status = http.StatusBadRequest
+	case errors.Is(err, client.ErrUnauthorized):
+		status = http.StatusUnauthorized
+	case errors.Is(err, client.ErrForbidden):
+		status = http.StatusForbidden
+	case errors.Is(err, client.ErrSystemUnavailable):
+		status = http.StatusServiceUnavailable
+	}
+
+	body := map[string]interface{}{"error": map[string]string{"message": err.Error()}}
+	if status >= 500 {
+		body["reference"] = strconv.FormatInt(time.Now().UnixNano(), 36)
+	}
+
+	h.write(w, status, body, nil)
+}
+
+// invalid responds with validation errors for each field, in the same shape as the broker
+func (h *handler) invalid(w http.ResponseWriter, fields map[string]string) bool {
+	errs := map[string][]string{}
+	for field, value := range fields {
+		if value == "" {
+			errs[field] = []string{"can't be blank"}
+		}
+	}
+	if len(errs) == 0 {
+		return false
+	}
+
+	h.write(w, http.StatusBadRequest, map[string]interface{}{"errors": errs}, nil)
+
+	return true
+}
+
+// decode reads the request body into v, responding with an error if it isn't valid JSON
+func (h *handler) decode(w http.ResponseWriter, r *http.Request, v interface{}) bool {
+	if err := json.NewDecoder(r.Body).Decode(v); err != nil {
+		h.write(w, http.StatusBadRequest, map[string]interface{}{"errors": []string{"request body is not valid JSON: " + err.Error()}}, nil)
+		return false
+	}
+
+	return true
+}
+
+// listOptions reads the filters from the query parameters the client sends
+func listOptions(r *http.Request) client.ListOptions {
+	return client.ListOptions{
+		Name:     r.URL.Query().Get("q"),
+		TeamUUID: r.URL.Query().Get("teamUuid"),
+	}
+}
+
+// page returns the items on the requested page, and the link to the next page if there is one
+func page[T any](r *http.Request, seq iter.Seq2[T, error]) ([]T, *broker.Link, error) {
+	var all []T
+	for item, err := range seq {
+		if err != nil {
+			return nil, nil, err
+		}
+		all = append(all, item)
+	}
+
+	query := r.URL.Query()
+	size, err := strconv.Atoi(query.Get("pageSize"))
+	if err != nil || size <= 0 {
+		size = defaultPageSize
+	}
+	number, err := strconv.Atoi(query.Get("page"))
+	if err != nil || number <= 0 {
+		number = 1
+	}
+
+	start := min((number-1)*size, len(all))
+	end := min(start+size, len(all))
+	if end == len(all) {
+		return all[start:end], nil, nil
+	}
+
+	query.Set("page", strconv.Itoa(number+1))
+	next := link(r, "%s?%s", r.URL.Path, query.Encode())
+
+	return all[start:end], &next, nil
+}
+
+func (h *handler) index(w http.ResponseWriter, r *http.Request) {
+	info := h.fake.BrokerInfo()
+	links := broker.HalLinks{"self": link(r, "/")}
+	for _, rel := range info.Relations {
+		if l, ok := relations[rel]; ok {
+			l.Href = baseURL(r) + l.Href
+			links[rel] = l
+		}
+	}
+
+	if info.Version != "" {
+		w.Header().Set("X-Pact-Broker-Version", info.Version)
+	}
+	h.write(w, http.StatusOK, broker.HalDoc{Links: links}, nil)
+}
+
+func (h *handler) heartbeat(w http.ResponseWriter, r *http.Request) {
+	h.write(w, http.StatusOK, map[string]bool{"ok": true}, nil)
+}
+
+func (h *handler) listPacticipants(w http.ResponseWriter, r *http.Request) {
+	items, next, err := page(r, h.fake.ListPacticipantsWithContext(r.Context(), listOptions(r)))
+	if err != nil {
+		h.error(w, r, err)
+		return
+	}
+
+	res := broker.PacticipantsResponse{}
+	res.Embedded.Pacticipants = items
+	res.Links.Next = next
+	h.write(w, http.StatusOK, res, nil)
+}
+
+func (h *handler) createPacticipant(w http.ResponseWriter, r *http.Request) {
+	p := broker.Pacticipant{}
+	if !h.decode(w, r, &p) || h.invalid(w, map[string]string{"name": p.Name}) {
+		return
+	}
+
+	res, err := h.fake.CreatePacticipantWithContext(r.Context(), p)
+	if err != nil {
+		h.error(w, r, err)
+		return
+	}
+	self := link(r, "/pacticipants/%s", url.PathEscape(res.Name))
+	w.Header().Set("Location", self.Href)
+	h.write(w, http.StatusCreated, res, &self)
+}
+
+func (h *handler) readPacticipant(w http.ResponseWriter, r *http.Request) {
+	res, err := h.fake.ReadPacticipantWithContext(r.Context(), r.PathValue("name"))
+	if err != nil {
+		h.error(w, r, err)
+		return
+	}
+	self := link(r, "/pacticipants/%s", url.PathEscape(res.Name))
+	h.write(w, http.StatusOK, res, &self)
+}
+
+// updatePacticipant merges the given properties into the pacticipant, as for a PATCH to the broker
+func (h *handler) updatePacticipant(w http.ResponseWriter, r *http.Request) {
+	p, err := h.fake.ReadPacticipantWithContext(r.Context(), r.PathValue("name"))
+	if err != nil {
+		h.error(w, r, err)
+		return
+	}
+	if !h.decode(w, r, p) {
+		return
+	}
+	p.Name = r.PathValue("name")
+
+	res, err := h.fake.UpdatePacticipantWithContext(r.Context(), *p)
+	if err != nil {
+		h.error(w, r, err)
+		return
+	}
+	self := link(r, "/pacticipants/%s", url.PathEscape(res.Name))
+	h.write(w, http.StatusOK, res, &self)
+}
+
+func (h *handler) deletePacticipant(w http.ResponseWriter, r *http.Request) {
+	if err := h.fake.DeletePacticipantWithContext(r.Context(), broker.Pacticipant{Name: r.PathValue("name")}); err != nil {
+		h.error(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *handler) listWebhooks(w http.ResponseWriter, r *http.Request) {
+	items, next, err := page(r, h.fake.ListWebhooksWithContext(r.Context(), listOptions(r)))
+	if err != nil {
+		h.error(w, r, err)
+		return
+	}
+
+	res := broker.WebhooksResponse{}
+	res.Links.Webhooks = []broker.Link{}
+	for _, webhook := range items {
+		l := link(r, "/webhooks/%s", webhook.ID)
+		l.Title = webhook.Description
+		res.Links.Webhooks = append(res.Links.Webhooks, l)
+	}
+	res.Links.Next = next
+	h.write(w, http.StatusOK, res, nil)
+}
+
+func (h *handler) createWebhook(w http.ResponseWriter, r *http.Request) {
+	webhook := broker.Webhook{}
+	if !h.decode(w, r, &webhook) || h.invalid(w, map[string]string{"request.method": webhook.Request.Method, "request.url": webhook.Request.URL}) {
+		return
+	}
+
+	res, err := h.fake.CreateWebhookWithContext(r.Context(), webhook)
+	if err != nil {
+		h.error(w, r, err)
+		return
+	}
+	self := link(r, "/webhooks/%s", idFromLink(res.Links["self"]))
+	w.Header().Set("Location", self.Href)
+	h.write(w, http.StatusCreated, res.Webhook, &self)
+}
+
+func (h *handler) readWebhook(w http.ResponseWriter, r *http.Request) {
+	res, err := h.fake.ReadWebhookWithContext(r.Context(), r.PathValue("id"))
+	if err != nil {
+		h.error(w, r, err)
+		return
+	}
+	self := link(r, "/webhooks/%s", res.ID)
+	h.write(w, http.StatusOK, res, &self)
+}
+
+func (h *handler) updateWebhook(w http.ResponseWriter, r *http.Request) {
+	webhook := broker.Webhook{}
+	if !h.decode(w, r, &webhook) || h.invalid(w, map[string]string{"request.method": webhook.Request.Method, "request.url": webhook.Request.URL}) {
+		return
+	}
+	webhook.ID = r.PathValue("id")
+
+	res, err := h.fake.UpdateWebhookWithContext(r.Context(), webhook)
+	if err != nil {
+		h.error(w, r, err)
+		return
+	}
+	self := link(r, "/webhooks/%s", webhook.ID)
+	h.write(w, http.StatusOK, res.Webhook, &self)
+}
+
+func (h *handler) deleteWebhook(w http.ResponseWriter, r *http.Request) {
+	if err := h.fake.DeleteWebhookWithContext(r.Context(), broker.Webhook{ID: r.PathValue("id")}); err != nil {
+		h.error(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *handler) listTeams(w http.ResponseWriter, r *http.Request) {
+	items, next, err := page(r, h.fake.ListTeamsWithContext(r.Context(), listOptions(r)))
+	if err != nil {
+		h.error(w, r, err)
+		return
+	}
+
+	res := broker.TeamsListResponse{Teams: items}
+	res.Links.Next = next
+	h.write(w, http.StatusOK, res, nil)
+}
+
+func (h *handler) createTeam(w http.ResponseWriter, r *http.Request) {
+	t := broker.TeamCreateOrUpdateRequest{}
+	if !h.decode(w, r, &t) || h.invalid(w, map[string]string{"name": t.Name}) {
+		return
+	}
+
+	res, err := h.fake.CreateTeamWithContext(r.Context(), t)
+	if err != nil {
+		h.error(w, r, err)
+		return
+	}
+	self := link(r, "/admin/teams/%s", res.UUID)
+	w.Header().Set("Location", self.Href)
+	h.write(w, http.StatusCreated, res, &self)
+}
+
+func (h *handler) readTeam(w http.ResponseWriter, r *http.Request) {
+	res, err := h.fake.ReadTeamWithContext(r.Context(), broker.Team{UUID: r.PathValue("uuid")})
+	if err != nil {
+		h.error(w, r, err)
+		return
+	}
+	self := link(r, "/admin/teams/%s", res.UUID)
+	h.write(w, http.StatusOK, res, &self)
+}
+
+func (h *handler) updateTeam(w http.ResponseWriter, r *http.Request) {
+	t := broker.TeamCreateOrUpdateRequest{}
+	if !h.decode(w, r, &t) || h.invalid(w, map[string]string{"name": t.Name}) {
+		return
+	}
+	t.UUID = r.PathValue("uuid")
+
+	res, err := h.fake.UpdateTeamWithContext(r.Context(), t)
+	if err != nil {
+		h.error(w, r, err)
+		return
+	}
+	self := link(r, "/admin/teams/%s", res.UUID)
+	h.write(w, http.StatusOK, res, &self)
+}
+
+func (h *handler) deleteTeam(w http.ResponseWriter, r *http.Request) {
+	if err := h.fake.DeleteTeamWithContext(r.Context(), broker.Team{UUID: r.PathValue("uuid")}); err != nil {
+		h.error(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *handler) readTeamAssignments(w http.ResponseWriter, r *http.Request) {
+	res, err := h.fake.ReadTeamAssignmentsWithContext(r.Context(), broker.Team{UUID: r.PathValue("uuid")})
+	if err != nil {
+		h.error(w, r, err)
+		return
+	}
+	h.write(w, http.StatusOK, res, nil)
+}
+
+func (h *handler) updateTeamAssignments(w http.ResponseWriter, r *http.Request) {
+	req := broker.TeamsAssignmentRequest{}
+	if !h.decode(w, r, &req) {
+		return
+	}
+	req.UUID = r.PathValue("uuid")
+
+	res, err := h.fake.UpdateTeamAssignmentsWithContext(r.Context(), req)
+	if err != nil {
+		h.error(w, r, err)
+		return
+	}
+	h.write(w, http.StatusOK, res, nil)
+}
+
+func (h *handler) appendTeamAssignments(w http.ResponseWriter, r *http.Request) {
+	req := broker.TeamsAssignmentRequest{}
+	if !h.decode(w, r, &req) {
+		return
+	}
+	req.UUID = r.PathValue("uuid")
+
+	res, err := h.fake.AppendTeamAssignmentsWithContext(r.Context(), req)
+	if err != nil {
+		h.error(w, r, err)
+		return
+	}
+	h.write(w, http.StatusOK, res, nil)
+}
+
+func (h *handler) deleteTeamAssignments(w http.ResponseWriter, r *http.Request) {
+	req := broker.TeamsAssignmentRequest{}
+	if !h.decode(w, r, &req) {
+		return
+	}
+	req.UUID = r.PathValue("uuid")
+
+	if err := h.fake.DeleteTeamAssignmentsWithContext(r.Context(), req); err != nil {
+		h.error(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *handler) deleteTeamAssignment(w http.ResponseWriter, r *http.Request) {
+	err := h.fake.DeleteTeamAssignmentWithContext(r.Context(), broker.Team{UUID: r.PathValue("uuid")}, broker.User{UUID: r.PathValue("user")})
+	if err != nil {
+		h.error(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *handler) listRoles(w http.ResponseWriter, r *http.Request) {
+	items, next, err := page(r, h.fake.ListRolesWithContext(r.Context(), listOptions(r)))
+	if err != nil {
+		h.error(w, r, err)
+		return
+	}
+
+	res := broker.RolesResponse{Roles: items}
+	res.Links.Next = next
+	h.write(w, http.StatusOK, res, nil)
+}
+
+func (h *handler) createRole(w http.ResponseWriter, r *http.Request) {
+	role := broker.Role{}
+	if !h.decode(w, r, &role) || h.invalid(w, map[string]string{"name": role.Name}) {
+		return
+	}
+
+	res, err := h.fake.CreateRoleWithContext(r.Context(), role)
+	if err != nil {
+		h.error(w, r, err)
+		return
+	}
+	self := link(r, "/admin/roles/%s", res.UUID)
+	w.Header().Set("Location", self.Href)
+	h.write(w, http.StatusCreated, res, &self)
+}
+
+func (h *handler) readRole(w http.ResponseWriter, r *http.Request) {
+	res, err := h.fake.ReadRoleWithContext(r.Context(), r.PathValue("uuid"))
+	if err != nil {
+		h.error(w, r, err)
+		return
+	}
+	self := link(r, "/admin/roles/%s", res.UUID)
+	h.write(w, http.StatusOK, res, &self)
+}
+
+func (h *handler) updateRole(w http.ResponseWriter, r *http.Request) {
+	role := broker.Role{}
+	if !h.decode(w, r, &role) || h.invalid(w, map[string]string{"name": role.Name}) {
+		return
+	}
+	role.UUID = r.PathValue("uuid")
+
+	res, err := h.fake.UpdateRoleWithContext(r.Context(), role)
+	if err != nil {
+		h.error(w, r, err)
+		return
+	}
+	self := link(r, "/admin/roles/%s", res.UUID)
+	h.write(w, http.StatusOK, res, &self)
+}
+
+func (h *handler) deleteRole(w http.ResponseWriter, r *http.Request) {
+	if err := h.fake.DeleteRoleWithContext(r.Context(), broker.Role{UUID: r.PathValue("uuid")}); err != nil {
+		h.error(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *handler) listUsers(w http.ResponseWriter, r *http.Request) {
+	items, next, err := page(r, h.fake.ListUsersWithContext(r.Context(), listOptions(r)))
+	if err != nil {
+		h.error(w, r, err)
+		return
+	}
+
+	res := broker.UsersListResponse{Users: broker.Users{Users: items}}
+	res.Links.Next = next
+	h.write(w, http.StatusOK, res, nil)
+}
+
+func (h *handler) createUser(w http.ResponseWriter, r *http.Request) {
+	u := broker.User{}
+	if !h.decode(w, r, &u) || h.invalid(w, map[string]string{"email": u.Email}) {
+		return
+	}
+
+	res, err := h.fake.CreateUserWithContext(r.Context(), u)
+	if err != nil {
+		h.error(w, r, err)
+		return
+	}
+	self := link(r, "/admin/users/%s", res.UUID)
+	w.Header().Set("Location", self.Href)
+	h.write(w, http.StatusCreated, res, &self)
+}
+
+// createSystemAccount only responds with the location of the new account, as for the broker
+func (h *handler) createSystemAccount(w http.ResponseWriter, r *http.Request) {
+	u := broker.User{}
+	if !h.decode(w, r, &u) || h.invalid(w, map[string]string{"name": u.Name}) {
+		return
+	}
+
+	res, err := h.fake.CreateSystemAccountWithContext(r.Context(), u)
+	if err != nil {
+		h.error(w, r, err)
+		return
+	}
+	w.Header().Set("Location", link(r, "/admin/system-accounts/%s", res.UUID).Href)
+	w.WriteHeader(http.StatusCreated)
+}
+
+func (h *handler) readUser(w http.ResponseWriter, r *http.Request) {
+	res, err := h.fake.ReadUserWithContext(r.Context(), r.PathValue("uuid"))
+	if err != nil {
+		h.error(w, r, err)
+		return
+	}
+	self := link(r, "/admin/users/%s", res.UUID)
+	h.write(w, http.StatusOK, res, &self)
+}
+
+func (h *handler) updateUser(w http.ResponseWriter, r *http.Request) {
+	u := broker.User{}
+	if !h.decode(w, r, &u) {
+		return
+	}
+	u.UUID = r.PathValue("uuid")
+
+	res, err := h.fake.UpdateUserWithContext(r.Context(), u)
+	if err != nil {
+		h.error(w, r, err)
+		return
+	}
+	self := link(r, "/admin/users/%s", res.UUID)
+	h.write(w, http.StatusOK, res, &self)
+}
+
+func (h *handler) setUserRoles(w http.ResponseWriter, r *http.Request) {
+	req := broker.SetUserRolesRequest{}
+	if !h.decode(w, r, &req) {
+		return
+	}
+
+	if err := h.fake.SetUserRolesWithContext(r.Context(), r.PathValue("uuid"), req); err != nil {
+		h.error(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *handler) addAdminRole(w http.ResponseWriter, r *http.Request) {
+	res, err := h.fake.AddAdminRoleToUserWithContext(r.Context(), broker.User{UUID: r.PathValue("uuid")})
+	if err != nil {
+		h.error(w, r, err)
+		return
+	}
+	self := link(r, "/admin/users/%s", res.UUID)
+	h.write(w, http.StatusOK, res, &self)
+}
+
+func (h *handler) removeAdminRole(w http.ResponseWriter, r *http.Request) {
+	res, err := h.fake.RemoveAdminRoleFromUserWithContext(r.Context(), broker.User{UUID: r.PathValue("uuid")})
+	if err != nil {
+		h.error(w, r, err)
+		return
+	}
+	self := link(r, "/admin/users/%s", res.UUID)
+	h.write(w, http.StatusOK, res, &self)
+}
+
+func (h *handler) listSecrets(w http.ResponseWriter, r *http.Request) {
+	items, next, err := page(r, h.fake.ListSecretsWithContext(r.Context(), listOptions(r)))
+	if err != nil {
+		h.error(w, r, err)
+		return
+	}
+
+	res := broker.SecretsResponse{}
+	res.Embedded.Secrets = []broker.SecretResponse{}
+	for _, s := range items {
+		res.Embedded.Secrets = append(res.Embedded.Secrets, broker.SecretResponse{
+			Secret: s,
+			HalDoc: broker.HalDoc{Links: broker.HalLinks{"self": link(r, "/secrets/%s", s.UUID)}},
+		})
+	}
+	res.Links.Next = next
+	h.write(w, http.StatusOK, res, nil)
+}
+
+func (h *handler) createSecret(w http.ResponseWriter, r *http.Request) {
+	s := broker.Secret{}
+	if !h.decode(w, r, &s) || h.invalid(w, map[string]string{"name": s.Name, "value": s.Value}) {
+		return
+	}
+
+	res, err := h.fake.CreateSecretWithContext(r.Context(), s)
+	if err != nil {
+		h.error(w, r, err)
+		return
+	}
+	self := link(r, "/secrets/%s", idFromLink(res.Links["self"]))
+	w.Header().Set("Location", self.Href)
+	h.write(w, http.StatusCreated, res.Secret, &self)
+}
+
+func (h *handler) readSecret(w http.ResponseWriter, r *http.Request) {
+	res, err := h.fake.ReadSecretWithContext(r.Context(), r.PathValue("uuid"))
+	if err != nil {
+		h.error(w, r, err)
+		return
+	}
+	self := link(r, "/secrets/%s", r.PathValue("uuid"))
+	h.write(w, http.StatusOK, res.Secret, &self)
+}
+
+func (h *handler) updateSecret(w http.ResponseWriter, r *http.Request) {
+	s := broker.Secret{}
+	if !h.decode(w, r, &s) || h.invalid(w, map[string]string{"name": s.Name}) {
+		return
+	}
+	s.UUID = r.PathValue("uuid")
+
+	res, err := h.fake.UpdateSecretWithContext(r.Context(), s)
+	if err != nil {
+		h.error(w, r, err)
+		return
+	}
+	self := link(r, "/secrets/%s", s.UUID)
+	h.write(w, http.StatusOK, res.Secret, &self)
+}
+
+func (h *handler) deleteSecret(w http.ResponseWriter, r *http.Request) {
+	if err := h.fake.DeleteSecretWithContext(r.Context(), broker.Secret{UUID: r.PathValue("uuid")}); err != nil {
+		h.error(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *handler) readTokens(w http.ResponseWriter, r *http.Request) {
+	res, err := h.fake.ReadTokensWithContext(r.Context())
+	if err != nil {
+		h.error(w, r, err)
+		return
+	}
+	self := link(r, "/settings/tokens")
+	h.write(w, http.StatusOK, res, &self)
+}
+
+func (h *handler) regenerateToken(w http.ResponseWriter, r *http.Request) {
+	res, err := h.fake.RegenerateTokenWithContext(r.Context(), broker.APIToken{UUID: r.PathValue("uuid")})
+	if err != nil {
+		h.error(w, r, err)
+		return
+	}
+	self := link(r, "/settings/tokens/%s", res.UUID)
+	h.write(w, http.StatusOK, res.APIToken, &self)
+}
+
+func (h *handler) readSystemAccountTokens(w http.ResponseWriter, r *http.Request) {
+	res, err := h.fake.ReadSystemAccountTokensWithContext(r.Context(), r.PathValue("uuid"))
+	if err != nil {
+		h.error(w, r, err)
+		return
+	}
+	self := link(r, "/admin/system-accounts/%s/tokens", r.PathValue("uuid"))
+	h.write(w, http.StatusOK, res, &self)
+}
+
+func (h *handler) readAuthenticationSettings(w http.ResponseWriter, r *http.Request) {
+	res, err := h.fake.ReadTenantAuthenticationSettingsWithContext(r.Context())
+	if err != nil {
+		h.error(w, r, err)
+		return
+	}
+	h.write(w, http.StatusOK, res, nil)
+}
+
+func (h *handler) setAuthenticationSettings(w http.ResponseWriter, r *http.Request) {
+	req := broker.AuthenticationSettings{}
+	if !h.decode(w, r, &req) {
+		return
+	}
+
+	res, err := h.fake.SetTenantAuthenticationSettingsWithContext(r.Context(), req)
+	if err != nil {
+		h.error(w, r, err)
+		return
+	}
+	h.write(w, http.StatusOK, res, nil)
+}
+
+func (h *handler) listEnvironments(w http.ResponseWriter, r *http.Request) {
+	items, next, err := page(r, h.fake.ListEnvironmentsWithContext(r.Context(), listOptions(r)))
+	if err != nil {
+		h.error(w, r, err)
+		return
+	}
+
+	res := broker.EnvironmentsResponse{}
+	res.Embedded.Environments = items
+	res.Links.Next = next
+	h.write(w, http.StatusOK, res, nil)
+}
+
+func (h *handler) createEnvironment(w http.ResponseWriter, r *http.Request) {
+	e := broker.EnvironmentCreateOrUpdateRequest{}
+	if !h.decode(w, r, &e) || h.invalid(w, map[string]string{"name": e.Name}) {
+		return
+	}
+
+	res, err := h.fake.CreateEnvironmentWithContext(r.Context(), e)
+	if err != nil {
+		h.error(w, r, err)
+		return
+	}
+	self := link(r, "/environments/%s", res.UUID)
+	w.Header().Set("Location", self.Href)
+	h.write(w, http.StatusCreated, res, &self)
+}
+
+func (h *handler) readEnvironment(w http.ResponseWriter, r *http.Request) {
+	res, err := h.fake.ReadEnvironmentWithContext(r.Context(), r.PathValue("uuid"))
+	if err != nil {
+		h.error(w, r, err)
+		return
+	}
+	self := link(r, "/environments/%s", res.UUID)
+	h.write(w, http.StatusOK, res, &self)
+}
+
+func (h *handler) updateEnvironment(w http.ResponseWriter, r *http.Request) {
+	e := broker.EnvironmentCreateOrUpdateRequest{}
+	if !h.decode(w, r, &e) || h.invalid(w, map[string]string{"name": e.Name}) {
+		return
+	}
+	e.UUID = r.PathValue("uuid")
+
+	res, err := h.fake.UpdateEnvironmentWithContext(r.Context(), e)
+	if err != nil {
+		h.error(w, r, err)
+		return
+	}
+	self := link(r, "/environments/%s", res.UUID)
+	h.write(w, http.StatusOK, res, &self)
+}
+
+func (h *handler) deleteEnvironment(w http.ResponseWriter, r *http.Request) {
+	if err := h.fake.DeleteEnvironmentWithContext(r.Context(), broker.Environment{UUID: r.PathValue("uuid")}); err != nil {
+		h.error(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,218 @@
+package clienttest
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/pactflow/terraform/broker"
+	"github.com/pactflow/terraform/client"
+	"github.com/stretchr/testify/assert"
+)
+
+func clientForServer(t *testing.T, s *Server, token string) *client.Client {
+	u, err := url.Parse(s.URL)
+	assert.NoError(t, err)
+
+	return client.NewClient(nil, client.Config{BaseURL: u, AccessToken: token})
+}
+
+func TestServer(t *testing.T) {
+	t.Run("advertises the relations of the emulated broker", func(t *testing.T) {
+		s := NewServer()
+		defer s.Close()
+		c := clientForServer(t, s, "1234")
+
+		assert.NoError(t, c.Discover())
+		assert.Equal(t, client.PactflowBroker, c.BrokerInfo().Flavour)
+
+		assert.NoError(t, c.WaitForReady(time.Second))
+
+		f := NewFake()
+		f.Info = OSSBrokerInfo()
+		oss := &Server{Server: httptest.NewServer(NewHandler(f, "")), Fake: f}
+		defer oss.Close()
+		c = clientForServer(t, oss, "1234")
+
+		assert.NoError(t, c.Discover())
+		assert.Equal(t, client.OSSBroker, c.BrokerInfo().Flavour)
+		assert.False(t, c.HasRelation("pf:admin-users"))
+	})
+
+	t.Run("supports the lifecycle of a webhook", func(t *testing.T) {
+		s := NewServer()
+		defer s.Close()
+		c := clientForServer(t, s, "1234")
+
+		created, err := c.CreateWebhook(broker.Webhook{
+			Description: "trigger build",
+			Events:      []broker.WebhookEvent{{Name: "contract_content_changed"}},
+			Request:     broker.Request{Method: "POST", URL: "https://ci.example.com/build"},
+		})
+		assert.NoError(t, err)
+		id := idFromLink(created.Links["self"])
+
+		created.Webhook.ID = id
+		created.Webhook.Description = "trigger a build"
+		_, err = c.UpdateWebhook(created.Webhook)
+		assert.NoError(t, err)
+
+		read, err := c.ReadWebhook(id)
+		assert.NoError(t, err)
+		assert.Equal(t, "trigger a build", read.Description)
+
+		assert.NoError(t, c.DeleteWebhook(broker.Webhook{ID: id}))
+		_, err = c.ReadWebhook(id)
+		assert.ErrorIs(t, err, client.ErrNotFound)
+	})
+
+	t.Run("merges pacticipant updates", func(t *testing.T) {
+		s := NewServer()
+		defer s.Close()
+		c := clientForServer(t, s, "1234")
+
+		_, err := c.CreatePacticipant(broker.Pacticipant{Name: "terraform-client", MainBranch: "main"})
+		assert.NoError(t, err)
+		_, err = c.UpdatePacticipant(broker.Pacticipant{Name: "terraform-client", DisplayName: "Terraform"})
+		assert.NoError(t, err)
+
+		p, err := c.ReadPacticipant("terraform-client")
+		assert.NoError(t, err)
+		assert.Equal(t, "main", p.MainBranch)
+		assert.Equal(t, "Terraform", p.DisplayName)
+	})
+
+	t.Run("manages users, roles and teams", func(t *testing.T) {
+		s := NewServer()
+		defer s.Close()
+		c := clientForServer(t, s, "1234")
+
+		account, err := c.CreateSystemAccount(broker.User{Name: "CI", Type: broker.SystemAccount})
+		assert.NoError(t, err)
+		role, err := c.CreateRole(broker.Role{Name: "CI", Permissions: []broker.Permission{{Scope: "contract_data:manage:*"}}})
+		assert.NoError(t, err)
+		assert.NoError(t, c.SetUserRoles(account.UUID, broker.SetUserRolesRequest{Roles: []string{role.UUID}}))
+		team, err := c.CreateTeam(broker.TeamCreateOrUpdateRequest{Name: "Platform"})
+		assert.NoError(t, err)
+		_, err = c.UpdateTeamAssignments(broker.TeamsAssignmentRequest{UUID: team.UUID, Users: []string{account.UUID}})
+		assert.NoError(t, err)
+
+		user, err := c.ReadUser(account.UUID)
+		assert.NoError(t, err)
+		assert.Equal(t, broker.SystemAccount, user.Type)
+		assert.Equal(t, role.UUID, user.Embedded.Roles[0].UUID)
+		assert.Equal(t, team.UUID, user.Embedded.Teams[0].UUID)
+
+		assert.NoError(t, c.DeleteTeamAssignment(*team, *user))
+		members, err := c.ReadTeamAssignments(*team)
+		assert.NoError(t, err)
+		assert.Empty(t, members.Embedded.Users)
+	})
+
+	t.Run("does not return the value of secrets", func(t *testing.T) {
+		s := NewServer()
+		defer s.Close()
+		c := clientForServer(t, s, "1234")
+
+		created, err := c.CreateSecret(broker.Secret{Name: "ci-token", Value: "s3cr3t"})
+		assert.NoError(t, err)
+
+		read, err := c.ReadSecret(idFromLink(created.Links["self"]))
+		assert.NoError(t, err)
+		assert.Equal(t, "ci-token", read.Name)
+		assert.Empty(t, read.Value)
+	})
+
+	t.Run("reads the tokens of system accounts", func(t *testing.T) {
+		s := NewServer()
+		defer s.Close()
+		c := clientForServer(t, s, "1234")
+
+		account, err := c.CreateSystemAccount(broker.User{Name: "CI"})
+		assert.NoError(t, err)
+
+		tokens, err := c.ReadSystemAccountTokens(account.UUID)
+		assert.NoError(t, err)
+		assert.Len(t, tokens.Embedded.Items, 1)
+		assert.NotEmpty(t, tokens.Embedded.Items[0].Value)
+
+		user, err := c.CreateUser(broker.User{Name: "Jane", Email: "jane@example.com"})
+		assert.NoError(t, err)
+		_, err = c.ReadSystemAccountTokens(user.UUID)
+		assert.ErrorIs(t, err, client.ErrNotFound)
+	})
+
+	t.Run("pages lists", func(t *testing.T) {
+		s := NewServer()
+		defer s.Close()
+		c := clientForServer(t, s, "1234")
+		for _, name := range []string{"production", "staging", "test"} {
+			_, err := c.CreateEnvironment(broker.EnvironmentCreateOrUpdateRequest{Name: name})
+			assert.NoError(t, err)
+		}
+
+		var names []string
+		for e, err := range c.ListEnvironments(client.ListOptions{PageSize: 2}) {
+			assert.NoError(t, err)
+			names = append(names, e.Name)
+		}
+
+		assert.Equal(t, []string{"production", "staging", "test"}, names)
+	})
+
+	t.Run("responds with validation errors", func(t *testing.T) {
+		s := NewServer()
+		defer s.Close()
+
+		_, err := clientForServer(t, s, "1234").CreateTeam(broker.TeamCreateOrUpdateRequest{})
+
+		assert.ErrorIs(t, err, client.ErrBadRequest)
+		apiErr := &client.APIError{}
+		assert.True(t, errors.As(err, &apiErr))
+		assert.Equal(t, []string{"can't be blank"}, apiErr.FieldErrors["name"])
+	})
+
+	t.Run("responds with injected errors", func(t *testing.T) {
+		s := NewServer()
+		defer s.Close()
+		s.Fake.Errors["ReadTokens"] = client.ErrForbidden
+
+		_, err := clientForServer(t, s, "1234").ReadTokens()
+
+		assert.ErrorIs(t, err, client.ErrForbidden)
+	})
+
+	t.Run("responds with the status of injected API errors", func(t *testing.T) {
+		s := NewServer()
+		defer s.Close()
+		s.Fake.Errors["ReadTeam"] = &client.APIError{StatusCode: http.StatusNotFound}
+
+		_, err := clientForServer(t, s, "1234").ReadTeam(broker.Team{UUID: "1234"})
+
+		var apiErr *client.APIError
+		assert.ErrorAs(t, err, &apiErr)
+		assert.Equal(t, http.StatusNotFound, apiErr.StatusCode)
+		assert.ErrorIs(t, err, client.ErrNotFound)
+	})
+}
+
+func TestHandlerAuthentication(t *testing.T) {
+	f := NewFake()
+	s := &Server{Server: httptest.NewServer(NewHandler(f, "1234")), Fake: f}
+	defer s.Close()
+
+	_, err := clientForServer(t, s, "wrong").ReadTokens()
+	assert.ErrorIs(t, err, client.ErrUnauthorized)
+
+	c := clientForServer(t, s, "1234")
+	token, err := c.FindTokenByType("read-write")
+	assert.NoError(t, err)
+
+	regenerated, err := c.RegenerateToken(*token)
+	assert.NoError(t, err)
+	_, err = clientForServer(t, s, regenerated.Value).ReadTokens()
+	assert.NoError(t, err)
+}
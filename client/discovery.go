@@ -0,0 +1,189 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/pactflow/terraform/broker"
+)
+
+// templateRelations maps the hard-coded path templates to the relation in the broker index that provides them
+var templateRelations = map[string]string{
+	pacticipantCreateTemplate:           "pb:pacticipants",
+	pacticipantReadUpdateDeleteTemplate: "pb:pacticipant",
+	webhookCreateTemplate:               "pb:webhooks",
+	environmentCreateTemplate:           "pb:environments",
+	environmentReadUpdateDeleteTemplate: "pb:environment",
+}
+
+var templateVariable = regexp.MustCompile(`\{[^}]+\}`)
+
+const (
+	// OSSBroker is the open source Pact Broker
+	OSSBroker = "oss"
+	// PactflowBroker is the Pactflow platform, which supports additional resources such as teams and users
+	PactflowBroker = "pactflow"
+
+	brokerVersionHeader    = "X-Pact-Broker-Version"
+	pactflowRelationPrefix = "pf:"
+)
+
+// BrokerInfo describes the flavour and capabilities of the broker
+type BrokerInfo struct {
+	// Flavour is either OSSBroker or PactflowBroker, or empty if it could not be detected
+	Flavour string
+	// Version is the version of the broker, if advertised
+	Version string
+	// Relations lists all relations advertised in the broker index
+	Relations []string
+}
+
+// Discover fetches the broker index (the root resource), from which the location of each
+// supported relation is resolved for all subsequent requests
+func (c *Client) Discover() error {
+	return c.DiscoverWithContext(c.BaseContext())
+}
+
+// DiscoverWithContext is the same as Discover, with the given context controlling cancellation and deadlines
+func (c *Client) DiscoverWithContext(ctx context.Context) error {
+	req, err := c.newRequest(ctx, "GET", metadataTemplate, nil)
+	if err != nil {
+		return err
+	}
+
+	index := new(broker.HalDoc)
+	resp, err := c.do(req, index)
+	if err != nil {
+		return err
+	}
+
+	c.indexMutex.Lock()
+	defer c.indexMutex.Unlock()
+	c.index = index
+	c.info = detectBroker(resp.Header, index)
+
+	log.Printf("[DEBUG] discovered broker %+v with relations %v\n", c.info, c.index.Links)
+
+	return nil
+}
+
+// BrokerInfo describes the broker as detected from its index. All fields are empty if Discover has not been
+// called, or if the broker index was unavailable
+func (c *Client) BrokerInfo() BrokerInfo {
+	c.indexMutex.RLock()
+	defer c.indexMutex.RUnlock()
+
+	return c.info
+}
+
+// detectBroker determines the broker flavour from the relations in its index, as Pactflow extends the
+// OSS broker API with its own "pf:" relations
+func detectBroker(header http.Header, index *broker.HalDoc) BrokerInfo {
+	info := BrokerInfo{
+		Flavour:   OSSBroker,
+		Version:   header.Get(brokerVersionHeader),
+		Relations: make([]string, 0, len(index.Links)),
+	}
+
+	for rel := range index.Links {
+		info.Relations = append(info.Relations, rel)
+		if strings.HasPrefix(rel, pactflowRelationPrefix) {
+			info.Flavour = PactflowBroker
+		}
+	}
+	sort.Strings(info.Relations)
+
+	return info
+}
+
+// HasRelation returns true if the broker index advertises the given relation (e.g. pb:environments).
+// Always returns false if Discover has not been called
+func (c *Client) HasRelation(rel string) bool {
+	c.indexMutex.RLock()
+	defer c.indexMutex.RUnlock()
+
+	if c.index == nil {
+		return false
+	}
+	_, ok := c.index.Links[rel]
+
+	return ok
+}
+
+// path returns the path for the given template and parameters, preferring the location advertised in the
+// broker index and falling back to the template if the relation is missing
+func (c *Client) path(template string, parameters ...string) string {
+	if rel, ok := templateRelations[template]; ok {
+		if p, ok := c.pathForRelation(rel, parameters...); ok {
+			return p
+		}
+	}
+
+	return urlEncodeTemplate(template, parameters...)
+}
+
+// pathForRelation expands the (possibly templated) link for the relation, and returns its path relative to the broker root
+func (c *Client) pathForRelation(rel string, parameters ...string) (string, bool) {
+	c.indexMutex.RLock()
+	defer c.indexMutex.RUnlock()
+
+	if c.index == nil {
+		return "", false
+	}
+	link, ok := c.index.Links[rel]
+	if !ok || link.Href == "" {
+		return "", false
+	}
+
+	variables := templateVariable.FindAllString(link.Href, -1)
+	if len(variables) != len(parameters) {
+		log.Println("[WARN] unable to expand relation", rel, "with parameters", parameters, ", using the default location")
+		return "", false
+	}
+
+	i := 0
+	expanded := templateVariable.ReplaceAllStringFunc(link.Href, func(string) string {
+		p := url.PathEscape(parameters[i])
+		i++
+		return p
+	})
+
+	path, err := c.relativePath(expanded)
+	if err != nil {
+		log.Println("[WARN] unable to use relation", rel, ", using the default location:", err)
+		return "", false
+	}
+
+	return path, true
+}
+
+// relativePath returns the path (and query) of a link relative to the broker root.
+// Only the path of the link is used, requests are always sent to the configured host
+func (c *Client) relativePath(href string) (string, error) {
+	u, err := url.Parse(href)
+	if err != nil {
+		return "", err
+	}
+
+	basePath := ""
+	if c.Config.BaseURL != nil {
+		basePath = strings.TrimRight(c.Config.BaseURL.EscapedPath(), "/")
+	}
+	path := u.EscapedPath()
+	if !strings.HasPrefix(path, basePath+"/") {
+		return "", fmt.Errorf("link %s is not located under the broker base path", href)
+	}
+	path = strings.TrimPrefix(path, basePath)
+
+	if u.RawQuery != "" {
+		path = path + "?" + u.RawQuery
+	}
+
+	return path, nil
+}
@@ -0,0 +1,89 @@
+package client
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiscovery(t *testing.T) {
+	requests := []string{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.URL.EscapedPath())
+		w.Header().Set("Content-Type", "application/hal+json")
+
+		switch r.URL.Path {
+		case "/pact-broker/":
+			w.Write([]byte(`{"_links":{
+				"pb:pacticipant":{"href":"http://internal.example.com/pact-broker/v2/pacticipants/{pacticipant}","templated":true},
+				"pb:webhooks":{"href":"http://internal.example.com/elsewhere/webhooks"}
+			}}`))
+		default:
+			w.Write([]byte(`{"name":"terraform client"}`))
+		}
+	}))
+	defer server.Close()
+
+	base, _ := url.Parse(server.URL + "/pact-broker/")
+	c := NewClient(nil, Config{BaseURL: base})
+
+	assert.False(t, c.HasRelation("pb:pacticipant"))
+	assert.NoError(t, c.Discover())
+	assert.True(t, c.HasRelation("pb:pacticipant"))
+	assert.False(t, c.HasRelation("pb:environments"))
+
+	t.Run("uses the discovered location of a templated relation", func(t *testing.T) {
+		assert.Equal(t, "/v2/pacticipants/terraform%20client", c.path(pacticipantReadUpdateDeleteTemplate, "terraform client"))
+
+		_, err := c.ReadPacticipant("terraform client")
+		assert.NoError(t, err)
+		assert.Equal(t, "/pact-broker/v2/pacticipants/terraform%20client", requests[len(requests)-1])
+	})
+
+	t.Run("falls back to the template when the relation is missing", func(t *testing.T) {
+		assert.Equal(t, "/environments/1234", c.path(environmentReadUpdateDeleteTemplate, "1234"))
+	})
+
+	t.Run("falls back to the template when the relation is outside of the base path", func(t *testing.T) {
+		assert.Equal(t, "/webhooks", c.path(webhookCreateTemplate))
+	})
+
+	t.Run("falls back to the template when the parameters don't match the relation", func(t *testing.T) {
+		c.index.Links["pb:environments"] = c.index.Links["pb:pacticipant"]
+
+		assert.Equal(t, "/environments", c.path(environmentCreateTemplate))
+	})
+}
+
+func TestDetectBroker(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		index    string
+		flavour  string
+		version  string
+		relation string
+	}{
+		{"oss", `{"_links":{"pb:webhooks":{"href":"/webhooks"}}}`, OSSBroker, "2.107.1", "pb:webhooks"},
+		{"pactflow", `{"_links":{"pb:webhooks":{"href":"/webhooks"},"pf:ui":{"href":"/"}}}`, PactflowBroker, "2.107.1", "pf:ui"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("X-Pact-Broker-Version", tc.version)
+				w.Write([]byte(tc.index))
+			}))
+			defer server.Close()
+
+			c := clientForTest(server, 0)
+			assert.Equal(t, BrokerInfo{}, c.BrokerInfo())
+			assert.NoError(t, c.Discover())
+
+			info := c.BrokerInfo()
+			assert.Equal(t, tc.flavour, info.Flavour)
+			assert.Equal(t, tc.version, info.Version)
+			assert.Contains(t, info.Relations, tc.relation)
+		})
+	}
+}
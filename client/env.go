@@ -0,0 +1,48 @@
+package client
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+)
+
+// Environment variables shared with the Pact CLI tools
+const (
+	EnvBaseURL  = "PACT_BROKER_BASE_URL"
+	EnvToken    = "PACT_BROKER_TOKEN"
+	EnvUsername = "PACT_BROKER_USERNAME"
+	EnvPassword = "PACT_BROKER_PASSWORD"
+)
+
+// ErrConflictingAuth is returned when both an access token (or token source) and basic auth credentials are configured
+var ErrConflictingAuth = errors.New("only one of an access token or basic auth credentials may be configured")
+
+// NewConfigFromEnv creates a Config from the standard PACT_BROKER_* environment variables
+func NewConfigFromEnv() (Config, error) {
+	config := Config{
+		AccessToken:       os.Getenv(EnvToken),
+		BasicAuthUsername: os.Getenv(EnvUsername),
+		BasicAuthPassword: os.Getenv(EnvPassword),
+	}
+
+	if base := os.Getenv(EnvBaseURL); base != "" {
+		u, err := url.Parse(strings.TrimRight(base, "/"))
+		if err != nil {
+			return config, fmt.Errorf("invalid %s: %w", EnvBaseURL, err)
+		}
+		config.BaseURL = u
+	}
+
+	return config, config.Validate()
+}
+
+// Validate checks that the configuration is usable
+func (c Config) Validate() error {
+	if (c.AccessToken != "" || c.TokenSource != nil || c.ReadAccessToken != "") && (c.BasicAuthUsername != "" || c.BasicAuthPassword != "") {
+		return ErrConflictingAuth
+	}
+
+	return nil
+}
@@ -0,0 +1,33 @@
+package client
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewConfigFromEnv(t *testing.T) {
+	t.Run("reads the standard environment variables", func(t *testing.T) {
+		t.Setenv(EnvBaseURL, "https://tools.example.com/pact-broker/")
+		t.Setenv(EnvUsername, "user")
+		t.Setenv(EnvPassword, "pass")
+		t.Setenv(EnvToken, "")
+
+		config, err := NewConfigFromEnv()
+
+		assert.NoError(t, err)
+		assert.Equal(t, "https://tools.example.com/pact-broker", config.BaseURL.String())
+		assert.Equal(t, "user", config.BasicAuthUsername)
+		assert.Equal(t, "pass", config.BasicAuthPassword)
+		assert.Empty(t, config.AccessToken)
+	})
+
+	t.Run("rejects both a token and basic auth credentials", func(t *testing.T) {
+		t.Setenv(EnvToken, "1234")
+		t.Setenv(EnvUsername, "user")
+
+		_, err := NewConfigFromEnv()
+
+		assert.ErrorIs(t, err, ErrConflictingAuth)
+	})
+}
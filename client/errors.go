@@ -1,8 +1,12 @@
 package client
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
+	"log"
+	"net/http"
+	"sort"
 	"strings"
 )
 
@@ -16,7 +20,6 @@ type apiErrorResponse struct {
 	Errors       APIKeyedError   `json:"errors"`
 	Reference    string          `json:"reference"`
 	ErrorDetails apiErrorMessage `json:"error"`
-	err          error
 }
 
 // apiErrorMessage represents are higher-level error such as for the cause of a 5xx
@@ -29,58 +32,91 @@ type apiArrayErrorResponse struct {
 	Errors       apiErrorDescriptions `json:"errors"`
 	Reference    string               `json:"reference"`
 	ErrorDetails apiErrorMessage      `json:"error"`
-	err          error
 }
 
-// type apiError interface {
-// 	Error() string
-// 	GetError() error
-// 	GetReference() string
-// 	GetMessage() string
-// }
-
-func (e *apiErrorResponse) Error() string {
-	errors := new(strings.Builder)
-	if e.ErrorDetails.Message != "" || len(e.Errors) > 0 || e.Reference != "" {
-		errors.WriteString("\terror details: \n")
+// APIError is returned for any unsuccessful (4xx or 5xx) response from the broker.
+// Use errors.Is to check the class of error (e.g. ErrNotFound), or errors.As to access the details
+type APIError struct {
+	// StatusCode is the HTTP status code of the response
+	StatusCode int
+	// Method is the HTTP method of the request
+	Method string
+	// Path is the URL path of the request
+	Path string
+	// Reference identifies the error, and may be given to Pactflow support
+	Reference string
+	// Message is a higher-level summary of the error, such as for the cause of a 5xx
+	Message string
+	// Errors contains any error descriptions that are not specific to a field
+	Errors []string
+	// FieldErrors contains the validation errors for each invalid field in the request
+	FieldErrors map[string][]string
+
+	err error
+}
 
-		if e.ErrorDetails.Message != "" {
-			errors.WriteString(fmt.Sprintf("\t\tsummary: %s\n", e.ErrorDetails.Message))
-		}
+func newAPIError(err error, req *http.Request, resp *http.Response) *APIError {
+	return &APIError{
+		StatusCode:  resp.StatusCode,
+		Method:      req.Method,
+		Path:        req.URL.Path,
+		FieldErrors: make(map[string][]string),
+		err:         err,
+	}
+}
 
-		if len(e.Errors) > 0 {
-			for _, v := range e.Errors {
-				errors.WriteString(fmt.Sprintf("\t\t%s\n", strings.Join(v, "\n")))
-			}
+// decode extracts the error details from a response body, supporting both the keyed and array error shapes
+func (e *APIError) decode(body []byte) error {
+	keyed := &apiErrorResponse{}
+	err := json.Unmarshal(body, keyed)
+	if err == nil {
+		e.Reference = keyed.Reference
+		e.Message = keyed.ErrorDetails.Message
+		for k, v := range keyed.Errors {
+			e.FieldErrors[string(k)] = v
 		}
 
-		if e.Reference != "" {
-			errors.WriteString(fmt.Sprintf("\t\treference: %s\n", e.Reference))
-		}
-	} else {
-		errors.WriteString("\n\tplease see the log for error details\n")
+		return nil
 	}
+	log.Println("[DEBUG] error decoding APIErrorResponse from response for", e.Method, e.Path, ". Error", err)
 
-	if e.err != nil {
-		return fmt.Sprintf("%s \n\n%s", e.err, errors.String())
+	array := &apiArrayErrorResponse{}
+	err = json.Unmarshal(body, array)
+	if err == nil {
+		e.Reference = array.Reference
+		e.Message = array.ErrorDetails.Message
+		e.Errors = array.Errors
+
+		return nil
 	}
+	log.Println("[DEBUG] error decoding APIArrayErrorResponse from response for", e.Method, e.Path, ". Error", err)
 
-	return errors.String()
+	return err
 }
 
-func (e *apiArrayErrorResponse) Error() string {
+func (e *APIError) Error() string {
 	errors := new(strings.Builder)
-	if e.ErrorDetails.Message != "" || len(e.Errors) > 0 || e.Reference != "" {
-		errors.WriteString("\n\terror details: \n")
+	if e.Message != "" || len(e.Errors) > 0 || len(e.FieldErrors) > 0 || e.Reference != "" {
+		errors.WriteString("\terror details: \n")
 
-		if e.ErrorDetails.Message != "" {
-			errors.WriteString(fmt.Sprintf("\t\tsummary: %s\n", e.ErrorDetails.Message))
+		if e.Message != "" {
+			errors.WriteString(fmt.Sprintf("\t\tsummary: %s\n", e.Message))
 		}
 
 		if len(e.Errors) > 0 {
 			errors.WriteString(fmt.Sprintf("\t\t%s\n", strings.Join(e.Errors, "\n")))
 		}
 
+		fields := make([]string, 0, len(e.FieldErrors))
+		for k := range e.FieldErrors {
+			fields = append(fields, k)
+		}
+		sort.Strings(fields)
+
+		for _, k := range fields {
+			errors.WriteString(fmt.Sprintf("\t\t%s\n", strings.Join(e.FieldErrors[k], "\n")))
+		}
+
 		if e.Reference != "" {
 			errors.WriteString(fmt.Sprintf("\t\treference: %s\n", e.Reference))
 		}
@@ -95,6 +131,21 @@ func (e *apiArrayErrorResponse) Error() string {
 	return errors.String()
 }
 
+// Unwrap supports errors.Is with the sentinel errors below. For backwards compatibility,
+// the more specific 4xx errors (e.g. ErrNotFound) are also an ErrBadRequest
+func (e *APIError) Unwrap() []error {
+	if e.err == nil {
+		return nil
+	}
+
+	switch e.err {
+	case ErrNotFound, ErrConflict, ErrTooManyRequests:
+		return []error{e.err, ErrBadRequest}
+	}
+
+	return []error{e.err}
+}
+
 var (
 	// ErrBadRequest represents an HTTP 400 error
 	ErrBadRequest = errors.New("bad request")
@@ -104,4 +155,12 @@ var (
 	ErrUnauthorized = errors.New("unauthorized")
 	// ErrForbidden represents an HTTP 403 permissions issue
 	ErrForbidden = errors.New("access denied, check that you have access to this resource")
+	// ErrNotFound represents an HTTP 404 error
+	ErrNotFound = errors.New("not found")
+	// ErrConflict represents an HTTP 409 error, such as when a resource with the same name already exists
+	ErrConflict = errors.New("conflict")
+	// ErrTooManyRequests represents an HTTP 429 error that persisted after all retries were exhausted
+	ErrTooManyRequests = errors.New("too many requests")
+	// ErrReadOnly is returned for requests that would modify the broker, when the client is read-only
+	ErrReadOnly = errors.New("the client is in read-only mode")
 )
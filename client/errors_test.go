@@ -1 +1,94 @@
 package client
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAPIError(t *testing.T) {
+	t.Run("decodes keyed field errors", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"errors":{"name":["Name must be unique"]},"reference":"abc123"}`))
+		}))
+		defer server.Close()
+
+		_, err := clientForTest(server, 0).ReadPacticipant("terraform-client")
+
+		var apiErr *APIError
+		assert.True(t, errors.As(err, &apiErr))
+		assert.Equal(t, http.StatusBadRequest, apiErr.StatusCode)
+		assert.Equal(t, "GET", apiErr.Method)
+		assert.Equal(t, "/pacticipants/terraform-client", apiErr.Path)
+		assert.Equal(t, "abc123", apiErr.Reference)
+		assert.Equal(t, []string{"Name must be unique"}, apiErr.FieldErrors["name"])
+		assert.ErrorIs(t, err, ErrBadRequest)
+		assert.Contains(t, err.Error(), "Name must be unique")
+		assert.Contains(t, err.Error(), "reference: abc123")
+	})
+
+	t.Run("decodes array errors", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"errors":["Missing required attribute 'name'"]}`))
+		}))
+		defer server.Close()
+
+		_, err := clientForTest(server, 0).ReadPacticipant("terraform-client")
+
+		var apiErr *APIError
+		assert.True(t, errors.As(err, &apiErr))
+		assert.Equal(t, []string{"Missing required attribute 'name'"}, apiErr.Errors)
+		assert.Empty(t, apiErr.FieldErrors)
+	})
+
+	t.Run("decodes a summary message", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusInternalServerError)
+			w.Write([]byte(`{"error":{"message":"something went wrong"},"reference":"xyz"}`))
+		}))
+		defer server.Close()
+
+		_, err := clientForTest(server, 0).ReadPacticipant("terraform-client")
+
+		var apiErr *APIError
+		assert.True(t, errors.As(err, &apiErr))
+		assert.Equal(t, "something went wrong", apiErr.Message)
+		assert.Equal(t, "xyz", apiErr.Reference)
+		assert.ErrorIs(t, err, ErrSystemUnavailable)
+		assert.NotErrorIs(t, err, ErrBadRequest)
+	})
+
+	t.Run("maps status codes to sentinel errors", func(t *testing.T) {
+		for status, sentinel := range map[int]error{
+			http.StatusBadRequest:      ErrBadRequest,
+			http.StatusUnauthorized:    ErrUnauthorized,
+			http.StatusForbidden:       ErrForbidden,
+			http.StatusNotFound:        ErrNotFound,
+			http.StatusConflict:        ErrConflict,
+			http.StatusTooManyRequests: ErrTooManyRequests,
+			http.StatusBadGateway:      ErrSystemUnavailable,
+		} {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(status)
+			}))
+
+			_, err := clientForTest(server, 0).ReadPacticipant("terraform-client")
+			server.Close()
+
+			assert.ErrorIs(t, err, sentinel, "status %d", status)
+		}
+	})
+
+	t.Run("specific client errors are also bad requests", func(t *testing.T) {
+		err := &APIError{StatusCode: http.StatusNotFound, err: ErrNotFound}
+
+		assert.ErrorIs(t, err, ErrNotFound)
+		assert.ErrorIs(t, err, ErrBadRequest)
+		assert.NotErrorIs(t, err, ErrConflict)
+	})
+}
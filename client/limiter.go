@@ -0,0 +1,62 @@
+package client
+
+import (
+	"context"
+	"math"
+
+	"golang.org/x/time/rate"
+)
+
+// limiter is shared by all requests made by a client, regardless of which resource makes them,
+// to avoid exceeding the broker's rate limits when Terraform runs many operations in parallel
+type limiter struct {
+	rate  *rate.Limiter
+	slots chan struct{}
+}
+
+func newLimiter(requestsPerSecond float64, maxConcurrentRequests int) *limiter {
+	l := &limiter{}
+
+	if requestsPerSecond > 0 {
+		// Allow a burst of up to a second's worth of requests, so that a limit below 1 still permits a request
+		burst := int(math.Max(1, math.Ceil(requestsPerSecond)))
+		l.rate = rate.NewLimiter(rate.Limit(requestsPerSecond), burst)
+	}
+
+	if maxConcurrentRequests > 0 {
+		l.slots = make(chan struct{}, maxConcurrentRequests)
+	}
+
+	return l
+}
+
+// acquire blocks until a request may be sent, or the context is done. The returned function must be
+// called once the request has completed
+func (l *limiter) acquire(ctx context.Context) (func(), error) {
+	if l == nil {
+		return func() {}, nil
+	}
+
+	if l.slots != nil {
+		select {
+		case l.slots <- struct{}{}:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+
+	release := func() {
+		if l.slots != nil {
+			<-l.slots
+		}
+	}
+
+	if l.rate != nil {
+		if err := l.rate.Wait(ctx); err != nil {
+			release()
+			return nil, err
+		}
+	}
+
+	return release, nil
+}
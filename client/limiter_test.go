@@ -0,0 +1,81 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLimiter(t *testing.T) {
+	t.Run("caps the number of concurrent requests", func(t *testing.T) {
+		var inFlight, maxInFlight int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			n := atomic.AddInt32(&inFlight, 1)
+			defer atomic.AddInt32(&inFlight, -1)
+			for {
+				max := atomic.LoadInt32(&maxInFlight)
+				if n <= max || atomic.CompareAndSwapInt32(&maxInFlight, max, n) {
+					break
+				}
+			}
+			time.Sleep(20 * time.Millisecond)
+			w.Write([]byte(`{"name":"terraform-client"}`))
+		}))
+		defer server.Close()
+
+		u, _ := url.Parse(server.URL)
+		c := NewClient(nil, Config{BaseURL: u, MaxConcurrentRequests: 2})
+
+		var wg sync.WaitGroup
+		for i := 0; i < 8; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				_, err := c.ReadPacticipant("terraform-client")
+				assert.NoError(t, err)
+			}()
+		}
+		wg.Wait()
+
+		assert.Equal(t, int32(2), maxInFlight)
+	})
+
+	t.Run("limits the rate of requests", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(`{"name":"terraform-client"}`))
+		}))
+		defer server.Close()
+
+		u, _ := url.Parse(server.URL)
+		c := NewClient(nil, Config{BaseURL: u, RequestsPerSecond: 20})
+
+		start := time.Now()
+		for i := 0; i < 25; i++ {
+			_, err := c.ReadPacticipant("terraform-client")
+			assert.NoError(t, err)
+		}
+
+		// The first 20 requests are a burst, the remaining 5 are spread over a quarter of a second
+		assert.GreaterOrEqual(t, time.Since(start), 200*time.Millisecond)
+	})
+
+	t.Run("stops waiting once the context is done", func(t *testing.T) {
+		l := newLimiter(0, 1)
+		release, err := l.acquire(context.Background())
+		assert.NoError(t, err)
+		defer release()
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+
+		_, err = l.acquire(ctx)
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+	})
+}
@@ -0,0 +1,280 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"iter"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/pactflow/terraform/broker"
+)
+
+const (
+	userListTemplate = "/admin/users"
+)
+
+// ListOptions filters and pages the results of the List methods. Filters are sent to the broker, and also applied
+// to the results, as not every broker supports filtering every type of resource
+type ListOptions struct {
+	// Name only returns resources with exactly this name
+	Name string
+	// NamePrefix only returns resources whose name starts with this prefix. It is only applied to the results,
+	// as the broker doesn't support searching by prefix
+	NamePrefix string
+	// TeamUUID only returns resources belonging to this team, for resources that belong to teams
+	TeamUUID string
+	// PageSize is the number of items to request per page. The broker's default is used if zero
+	PageSize int
+}
+
+// query adds the filters to the path as query parameters
+func (o ListOptions) query(p string) string {
+	params := url.Values{}
+	if o.Name != "" {
+		params.Set("q", o.Name)
+	}
+	if o.TeamUUID != "" {
+		params.Set("teamUuid", o.TeamUUID)
+	}
+	if o.PageSize > 0 {
+		params.Set("pageSize", strconv.Itoa(o.PageSize))
+	}
+
+	if len(params) == 0 {
+		return p
+	}
+
+	return p + "?" + params.Encode()
+}
+
+// matches checks a resource against the filters. teams are the UUIDs of the teams the resource belongs to,
+// or nil if the resource doesn't belong to teams
+func (o ListOptions) matches(name string, teams []string) bool {
+	if o.Name != "" && o.Name != name {
+		return false
+	}
+	if !strings.HasPrefix(name, o.NamePrefix) {
+		return false
+	}
+	if o.TeamUUID == "" || teams == nil {
+		return true
+	}
+	for _, t := range teams {
+		if t == o.TeamUUID {
+			return true
+		}
+	}
+
+	return false
+}
+
+// list returns an iterator over every item in a paginated list, following the next link of each page.
+// items extracts the items and the next link from a page
+func list[T any, P any](c *Client, ctx context.Context, first string, items func(*P) ([]T, *broker.Link)) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		var zero T
+		visited := map[string]bool{}
+
+		for next := first; next != "" && !visited[next]; {
+			visited[next] = true
+
+			page := new(P)
+			req, err := c.newRequest(ctx, "GET", next, nil)
+			if err == nil {
+				_, err = c.do(req, page)
+			}
+			if err != nil {
+				yield(zero, err)
+				return
+			}
+
+			results, link := items(page)
+			for _, item := range results {
+				if !yield(item, nil) {
+					return
+				}
+			}
+
+			next = ""
+			if link != nil && link.Href != "" {
+				if next, err = c.relativePath(link.Href); err != nil {
+					yield(zero, fmt.Errorf("unable to fetch the next page: %w", err))
+					return
+				}
+			}
+		}
+	}
+}
+
+// filter only yields the items (and any errors) accepted by include
+func filter[T any](seq iter.Seq2[T, error], include func(T) bool) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		for item, err := range seq {
+			if err == nil && !include(item) {
+				continue
+			}
+			if !yield(item, err) || err != nil {
+				return
+			}
+		}
+	}
+}
+
+// idFromLink returns the last segment of a link, which is the ID of the resource for most broker APIs
+func idFromLink(link broker.Link) string {
+	return path.Base(strings.TrimRight(link.Href, "/"))
+}
+
+// ListPacticipants returns an iterator over all pacticipants (applications)
+func (c *Client) ListPacticipants(opts ListOptions) iter.Seq2[broker.Pacticipant, error] {
+	return c.ListPacticipantsWithContext(c.BaseContext(), opts)
+}
+
+// ListPacticipantsWithContext is the same as ListPacticipants, with the given context controlling cancellation and deadlines
+func (c *Client) ListPacticipantsWithContext(ctx context.Context, opts ListOptions) iter.Seq2[broker.Pacticipant, error] {
+	seq := list(c, ctx, opts.query(c.path(pacticipantCreateTemplate)), func(p *broker.PacticipantsResponse) ([]broker.Pacticipant, *broker.Link) {
+		return p.Embedded.Pacticipants, p.Links.Next
+	})
+
+	return filter(seq, func(p broker.Pacticipant) bool {
+		return opts.matches(p.Name, nil)
+	})
+}
+
+// ListTeams returns an iterator over all teams
+func (c *Client) ListTeams(opts ListOptions) iter.Seq2[broker.Team, error] {
+	return c.ListTeamsWithContext(c.BaseContext(), opts)
+}
+
+// ListTeamsWithContext is the same as ListTeams, with the given context controlling cancellation and deadlines
+func (c *Client) ListTeamsWithContext(ctx context.Context, opts ListOptions) iter.Seq2[broker.Team, error] {
+	seq := list(c, ctx, opts.query(c.path(teamCreateTemplate)), func(p *broker.TeamsListResponse) ([]broker.Team, *broker.Link) {
+		return p.Teams, p.Links.Next
+	})
+
+	return filter(seq, func(t broker.Team) bool {
+		return opts.matches(t.Name, nil)
+	})
+}
+
+// ListUsers returns an iterator over all users, including system accounts
+func (c *Client) ListUsers(opts ListOptions) iter.Seq2[broker.User, error] {
+	return c.ListUsersWithContext(c.BaseContext(), opts)
+}
+
+// ListUsersWithContext is the same as ListUsers, with the given context controlling cancellation and deadlines
+func (c *Client) ListUsersWithContext(ctx context.Context, opts ListOptions) iter.Seq2[broker.User, error] {
+	seq := list(c, ctx, opts.query(c.path(userListTemplate)), func(p *broker.UsersListResponse) ([]broker.User, *broker.Link) {
+		return p.Users.Users, p.Links.Next
+	})
+
+	return filter(seq, func(u broker.User) bool {
+		teams := make([]string, len(u.Embedded.Teams))
+		for i, t := range u.Embedded.Teams {
+			teams[i] = t.UUID
+		}
+		return opts.matches(u.Name, teams)
+	})
+}
+
+// ListRoles returns an iterator over all roles
+func (c *Client) ListRoles(opts ListOptions) iter.Seq2[broker.Role, error] {
+	return c.ListRolesWithContext(c.BaseContext(), opts)
+}
+
+// ListRolesWithContext is the same as ListRoles, with the given context controlling cancellation and deadlines
+func (c *Client) ListRolesWithContext(ctx context.Context, opts ListOptions) iter.Seq2[broker.Role, error] {
+	seq := list(c, ctx, opts.query(c.path(roleCreateTemplate)), func(p *broker.RolesResponse) ([]broker.Role, *broker.Link) {
+		return p.Roles, p.Links.Next
+	})
+
+	return filter(seq, func(r broker.Role) bool {
+		return opts.matches(r.Name, nil)
+	})
+}
+
+// ListEnvironments returns an iterator over all environments
+func (c *Client) ListEnvironments(opts ListOptions) iter.Seq2[broker.Environment, error] {
+	return c.ListEnvironmentsWithContext(c.BaseContext(), opts)
+}
+
+// ListEnvironmentsWithContext is the same as ListEnvironments, with the given context controlling cancellation and deadlines
+func (c *Client) ListEnvironmentsWithContext(ctx context.Context, opts ListOptions) iter.Seq2[broker.Environment, error] {
+	seq := list(c, ctx, opts.query(c.path(environmentCreateTemplate)), func(p *broker.EnvironmentsResponse) ([]broker.Environment, *broker.Link) {
+		return p.Embedded.Environments, p.Links.Next
+	})
+
+	return filter(seq, func(e broker.Environment) bool {
+		teams := make([]string, len(e.Embedded.Teams))
+		for i, t := range e.Embedded.Teams {
+			teams[i] = t.UUID
+		}
+		return opts.matches(e.Name, teams)
+	})
+}
+
+// ListSecrets returns an iterator over all secrets. Secret values are never returned by the broker
+func (c *Client) ListSecrets(opts ListOptions) iter.Seq2[broker.Secret, error] {
+	return c.ListSecretsWithContext(c.BaseContext(), opts)
+}
+
+// ListSecretsWithContext is the same as ListSecrets, with the given context controlling cancellation and deadlines
+func (c *Client) ListSecretsWithContext(ctx context.Context, opts ListOptions) iter.Seq2[broker.Secret, error] {
+	seq := list(c, ctx, opts.query(c.path(secretCreateTemplate)), func(p *broker.SecretsResponse) ([]broker.Secret, *broker.Link) {
+		secrets := make([]broker.Secret, len(p.Embedded.Secrets))
+		for i, s := range p.Embedded.Secrets {
+			secrets[i] = s.Secret
+			secrets[i].UUID = idFromLink(s.Links["self"])
+		}
+		return secrets, p.Links.Next
+	})
+
+	return filter(seq, func(s broker.Secret) bool {
+		return opts.matches(s.Name, []string{s.TeamUUID})
+	})
+}
+
+// ListWebhooks returns an iterator over all webhooks. The broker only lists links to each webhook,
+// so each webhook is read as it is iterated. Name filters are not supported, as webhooks don't have names
+func (c *Client) ListWebhooks(opts ListOptions) iter.Seq2[broker.Webhook, error] {
+	return c.ListWebhooksWithContext(c.BaseContext(), opts)
+}
+
+// ListWebhooksWithContext is the same as ListWebhooks, with the given context controlling cancellation and deadlines
+func (c *Client) ListWebhooksWithContext(ctx context.Context, opts ListOptions) iter.Seq2[broker.Webhook, error] {
+	links := list(c, ctx, ListOptions{TeamUUID: opts.TeamUUID, PageSize: opts.PageSize}.query(c.path(webhookCreateTemplate)), func(p *broker.WebhooksResponse) ([]broker.Link, *broker.Link) {
+		return p.Links.Webhooks, p.Links.Next
+	})
+
+	return func(yield func(broker.Webhook, error) bool) {
+		for link, err := range links {
+			if err != nil {
+				yield(broker.Webhook{}, err)
+				return
+			}
+
+			id := idFromLink(link)
+			webhook, err := c.ReadWebhookWithContext(ctx, id)
+			if errors.Is(err, ErrNotFound) {
+				// Deleted since the list was fetched
+				continue
+			}
+			if err != nil {
+				yield(broker.Webhook{}, err)
+				return
+			}
+			webhook.ID = id
+
+			if opts.TeamUUID != "" && webhook.TeamUUID != opts.TeamUUID {
+				continue
+			}
+			if !yield(*webhook, nil) {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,114 @@
+package client
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/pactflow/terraform/broker"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestList(t *testing.T) {
+	t.Run("follows next links until the last page", func(t *testing.T) {
+		var queries []string
+		var server *httptest.Server
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			queries = append(queries, r.URL.RawQuery)
+			w.Header().Set("Content-Type", "application/hal+json")
+			if r.URL.Query().Get("pageNumber") == "" {
+				fmt.Fprintf(w, `{"_embedded":{"pacticipants":[{"name":"a"},{"name":"b"}]},"_links":{"next":{"href":"%s/pacticipants?pageNumber=2&pageSize=2"}}}`, server.URL)
+				return
+			}
+			w.Write([]byte(`{"_embedded":{"pacticipants":[{"name":"c"}]},"_links":{}}`))
+		}))
+		defer server.Close()
+
+		var names []string
+		for p, err := range clientForTest(server, 0).ListPacticipants(ListOptions{PageSize: 2}) {
+			assert.NoError(t, err)
+			names = append(names, p.Name)
+		}
+
+		assert.Equal(t, []string{"a", "b", "c"}, names)
+		assert.Equal(t, []string{"pageSize=2", "pageNumber=2&pageSize=2"}, queries)
+	})
+
+	t.Run("stops fetching pages once iteration stops", func(t *testing.T) {
+		requests := 0
+		var server *httptest.Server
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requests++
+			fmt.Fprintf(w, `{"teams":[{"name":"a"},{"name":"b"}],"_links":{"next":{"href":"%s/admin/teams?pageNumber=%d"}}}`, server.URL, requests+1)
+		}))
+		defer server.Close()
+
+		for range clientForTest(server, 0).ListTeams(ListOptions{}) {
+			break
+		}
+
+		assert.Equal(t, 1, requests)
+	})
+
+	t.Run("applies filters to the results", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "team-1", r.URL.Query().Get("teamUuid"))
+			w.Write([]byte(`{"_embedded":{"secrets":[
+				{"name":"a","teamUuid":"team-1","_links":{"self":{"href":"http://localhost/secrets/1"}}},
+				{"name":"b","teamUuid":"team-2","_links":{"self":{"href":"http://localhost/secrets/2"}}}
+			]}}`))
+		}))
+		defer server.Close()
+
+		var secrets []broker.Secret
+		for s, err := range clientForTest(server, 0).ListSecrets(ListOptions{TeamUUID: "team-1"}) {
+			assert.NoError(t, err)
+			secrets = append(secrets, s)
+		}
+
+		assert.Len(t, secrets, 1)
+		assert.Equal(t, "a", secrets[0].Name)
+		assert.Equal(t, "1", secrets[0].UUID)
+	})
+
+	t.Run("reads each listed webhook", func(t *testing.T) {
+		var server *httptest.Server
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch r.URL.Path {
+			case "/webhooks":
+				fmt.Fprintf(w, `{"_links":{"pb:webhooks":[{"href":"%[1]s/webhooks/1"},{"href":"%[1]s/webhooks/2"}]}}`, server.URL)
+			case "/webhooks/1":
+				w.Write([]byte(`{"description":"first"}`))
+			default:
+				w.WriteHeader(http.StatusNotFound)
+			}
+		}))
+		defer server.Close()
+
+		var webhooks []broker.Webhook
+		for w, err := range clientForTest(server, 0).ListWebhooks(ListOptions{}) {
+			assert.NoError(t, err)
+			webhooks = append(webhooks, w)
+		}
+
+		assert.Len(t, webhooks, 1)
+		assert.Equal(t, "1", webhooks[0].ID)
+		assert.Equal(t, "first", webhooks[0].Description)
+	})
+
+	t.Run("returns errors", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusForbidden)
+		}))
+		defer server.Close()
+
+		count := 0
+		for _, err := range clientForTest(server, 0).ListUsers(ListOptions{}) {
+			assert.ErrorIs(t, err, ErrForbidden)
+			count++
+		}
+
+		assert.Equal(t, 1, count)
+	})
+}
@@ -0,0 +1,188 @@
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+)
+
+const redacted = "*****"
+
+// requestIDHeaders are response headers that identify a request in the broker's own logs
+var requestIDHeaders = []string{"X-Request-Id", "X-Amzn-Trace-Id"}
+
+// sensitiveKeys are JSON properties whose values must never be logged, compared case-insensitively
+// ignoring "_" and "-". This covers secret and token values, webhook passwords and tokens
+var sensitiveKeys = map[string]bool{
+	"value":        true,
+	"password":     true,
+	"token":        true,
+	"accesstoken":  true,
+	"apitoken":     true,
+	"secret":       true,
+	"clientsecret": true,
+}
+
+// sensitiveHeaders are HTTP headers (including those configured on webhooks) whose values must never be logged
+var sensitiveHeaders = map[string]bool{
+	"authorization":       true,
+	"proxy-authorization": true,
+	"cookie":              true,
+	"set-cookie":          true,
+	"x-api-key":           true,
+}
+
+// sensitiveHeaderFragments mark any header containing them as sensitive, e.g. X-Auth-Token or X-Client-Secret
+var sensitiveHeaderFragments = []string{"token", "secret", "password", "api-key", "apikey", "auth"}
+
+// loggedWebhookHeaders are the only webhook headers whose values are logged. Webhooks may send credentials in
+// headers with any name (e.g. X-Hub-Signature), so the values of all other webhook headers are redacted
+var loggedWebhookHeaders = map[string]bool{
+	"content-type": true,
+	"accept":       true,
+}
+
+func normaliseKey(k string) string {
+	return strings.NewReplacer("_", "", "-", "").Replace(strings.ToLower(k))
+}
+
+// IsSensitiveHeader returns true for HTTP headers (including those configured on webhooks) that carry credentials,
+// such as Authorization or X-Auth-Token
+func IsSensitiveHeader(name string) bool {
+	name = strings.ToLower(name)
+	if sensitiveHeaders[name] {
+		return true
+	}
+	for _, f := range sensitiveHeaderFragments {
+		if strings.Contains(name, f) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// redactHeaders returns a copy of the headers, safe to be logged
+func redactHeaders(h http.Header) http.Header {
+	out := make(http.Header, len(h))
+	for k, v := range h {
+		if IsSensitiveHeader(k) {
+			out[k] = []string{redacted}
+		} else {
+			out[k] = v
+		}
+	}
+
+	return out
+}
+
+// redactBody returns a JSON body with all sensitive values removed, safe to be logged.
+// Bodies that are not valid JSON are not logged at all, as their contents can't be inspected
+func redactBody(body []byte) string {
+	if len(body) == 0 {
+		return ""
+	}
+
+	var v interface{}
+	if err := json.Unmarshal(body, &v); err != nil {
+		return fmt.Sprintf("<%d bytes of non-JSON content>", len(body))
+	}
+
+	redacted, err := json.Marshal(redactValue(v, false))
+	if err != nil {
+		return fmt.Sprintf("<%d bytes of content>", len(body))
+	}
+
+	return string(redacted)
+}
+
+// redactEntity redacts an entity (e.g. a decoded response) for logging
+func redactEntity(v interface{}) string {
+	body, err := json.Marshal(v)
+	if err != nil {
+		return "<unable to serialise entity>"
+	}
+
+	return redactBody(body)
+}
+
+// redactValue walks the decoded JSON, replacing sensitive values. Keys of "headers" objects (i.e. in webhooks)
+// are treated as HTTP header names, and only the values of loggedWebhookHeaders are kept
+func redactValue(v interface{}, headers bool) interface{} {
+	switch t := v.(type) {
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(t))
+		for k, item := range t {
+			if (headers && !loggedWebhookHeaders[strings.ToLower(k)]) || (!headers && sensitiveKeys[normaliseKey(k)] && !isContainer(item)) {
+				out[k] = redacted
+				continue
+			}
+			out[k] = redactValue(item, strings.EqualFold(k, "headers"))
+		}
+		return out
+	case []interface{}:
+		out := make([]interface{}, len(t))
+		for i, item := range t {
+			out[i] = redactValue(item, false)
+		}
+		return out
+	}
+
+	return v
+}
+
+// isContainer allows nested objects under a sensitive key (e.g. {"value": {"uuid": "..."}}) to be inspected
+// rather than hidden completely
+func isContainer(v interface{}) bool {
+	switch v.(type) {
+	case map[string]interface{}, []interface{}:
+		return true
+	}
+
+	return false
+}
+
+// fields formats key/value pairs for structured log lines, e.g. "method=GET path=/webhooks status=200"
+func fields(kv ...interface{}) string {
+	pairs := make([]string, 0, len(kv)/2)
+	for i := 0; i+1 < len(kv); i += 2 {
+		pairs = append(pairs, fmt.Sprintf("%v=%v", kv[i], kv[i+1]))
+	}
+
+	return strings.Join(pairs, " ")
+}
+
+// formatHeaders renders redacted headers in a stable order
+func formatHeaders(h http.Header) string {
+	h = redactHeaders(h)
+	keys := make([]string, 0, len(h))
+	for k := range h {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	out := make([]string, len(keys))
+	for i, k := range keys {
+		out[i] = fmt.Sprintf("%s:%q", k, strings.Join(h[k], ","))
+	}
+
+	return "{" + strings.Join(out, " ") + "}"
+}
+
+// requestID returns the broker's identifier for the response, if any
+func requestID(resp *http.Response) string {
+	for _, h := range requestIDHeaders {
+		if id := resp.Header.Get(h); id != "" {
+			return id
+		}
+	}
+
+	return "-"
+}
+
+func latency(start time.Time) string {
+	return time.Since(start).Round(time.Millisecond).String()
+}
@@ -0,0 +1,95 @@
+package client
+
+import (
+	"bytes"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/pactflow/terraform/broker"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRedactBody(t *testing.T) {
+	t.Run("redacts sensitive JSON properties at any depth", func(t *testing.T) {
+		body := []byte(`{"name":"my-secret","value":"s3cr3t","_embedded":{"items":[{"uuid":"1234","accessToken":"abcd","client_secret":"efgh"}]}}`)
+
+		out := redactBody(body)
+
+		assert.NotContains(t, out, "s3cr3t")
+		assert.NotContains(t, out, "abcd")
+		assert.NotContains(t, out, "efgh")
+		assert.Contains(t, out, `"name":"my-secret"`)
+		assert.Contains(t, out, `"uuid":"1234"`)
+	})
+
+	t.Run("redacts webhook credentials and sensitive headers", func(t *testing.T) {
+		body := []byte(`{"request":{"url":"https://example.com","username":"user","password":"hunter2","headers":{"Content-Type":"application/json","Authorization":"Bearer xyz","X-Custom-Token":"qwerty"}}}`)
+
+		out := redactBody(body)
+
+		assert.NotContains(t, out, "hunter2")
+		assert.NotContains(t, out, "Bearer xyz")
+		assert.NotContains(t, out, "qwerty")
+		assert.Contains(t, out, `"Content-Type":"application/json"`)
+		assert.Contains(t, out, `"username":"user"`)
+	})
+
+	t.Run("redacts webhook headers with unrecognised names", func(t *testing.T) {
+		body := []byte(`{"request":{"headers":{"Accept":"application/json","X-Hub-Signature":"sha256=abcd","Circle-Token-V2":"efgh"}}}`)
+
+		out := redactBody(body)
+
+		assert.NotContains(t, out, "sha256=abcd")
+		assert.NotContains(t, out, "efgh")
+		assert.Contains(t, out, `"X-Hub-Signature":"*****"`)
+		assert.Contains(t, out, `"Accept":"application/json"`)
+	})
+
+	t.Run("does not log bodies that are not JSON", func(t *testing.T) {
+		out := redactBody([]byte("token=s3cr3t"))
+
+		assert.Equal(t, "<12 bytes of non-JSON content>", out)
+	})
+}
+
+func TestRedactHeaders(t *testing.T) {
+	h := http.Header{}
+	h.Set("Authorization", "Bearer 1234")
+	h.Set("X-Api-Key", "abcd")
+	h.Set("Accept", "application/hal+json")
+
+	out := formatHeaders(h)
+
+	assert.NotContains(t, out, "1234")
+	assert.NotContains(t, out, "abcd")
+	assert.Contains(t, out, `Accept:"application/hal+json"`)
+	assert.Equal(t, "Bearer 1234", h.Get("Authorization"))
+}
+
+func TestRequestLogging(t *testing.T) {
+	var buf bytes.Buffer
+	log.SetOutput(&buf)
+	defer log.SetOutput(os.Stderr)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Request-Id", "req-5678")
+		w.Header().Set("Content-Type", "application/hal+json")
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"name":"my-secret","value":"s3cr3t"}`))
+	}))
+	defer server.Close()
+
+	_, err := clientForTest(server, 0).CreateSecret(broker.Secret{Name: "my-secret", Value: "s3cr3t"})
+	assert.NoError(t, err)
+
+	out := buf.String()
+	assert.NotContains(t, out, "s3cr3t")
+	assert.NotContains(t, out, "Bearer 1234")
+	assert.Contains(t, out, "method=POST path=/secrets")
+	assert.Contains(t, out, "status=201")
+	assert.Contains(t, out, "latency=")
+	assert.Contains(t, out, "request_id=req-5678")
+}
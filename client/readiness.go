@@ -0,0 +1,76 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"time"
+)
+
+// heartbeatPollInterval is the time between checks of the broker heartbeat endpoint
+var heartbeatPollInterval = 2 * time.Second
+
+// WaitForReady polls the broker heartbeat endpoint until it responds successfully, or the timeout elapses
+func (c *Client) WaitForReady(timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(c.BaseContext(), timeout)
+	defer cancel()
+
+	return c.WaitForReadyWithContext(ctx)
+}
+
+// WaitForReadyWithContext polls the broker heartbeat endpoint until it responds successfully, or the context is done
+func (c *Client) WaitForReadyWithContext(ctx context.Context) error {
+	var lastErr error
+
+	for attempt := 1; ; attempt++ {
+		err := c.heartbeat(ctx)
+		if err == nil {
+			log.Println("[DEBUG] broker is ready after", attempt, "attempt(s)")
+			return nil
+		}
+		log.Printf("[DEBUG] broker is not ready (attempt %d): %v\n", attempt, err)
+
+		// Report the reason the broker wasn't ready, rather than the cancellation of the final attempt
+		if ctx.Err() == nil || lastErr == nil {
+			lastErr = err
+		}
+
+		select {
+		case <-time.After(heartbeatPollInterval):
+		case <-ctx.Done():
+			return fmt.Errorf("broker at %s was not ready in time: %w", c.Config.BaseURL, lastErr)
+		}
+	}
+}
+
+// heartbeat performs a single, unauthenticated check of the broker, without retries
+func (c *Client) heartbeat(ctx context.Context) error {
+	u, err := c.resolve(heartbeatTemplate)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", u.String(), nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("User-Agent", c.UserAgent)
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		io.Copy(ioutil.Discard, resp.Body)
+		resp.Body.Close()
+	}()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("heartbeat returned status %d", resp.StatusCode)
+	}
+
+	return nil
+}
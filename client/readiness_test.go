@@ -0,0 +1,47 @@
+package client
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWaitForReady(t *testing.T) {
+	heartbeatPollInterval = time.Millisecond
+
+	t.Run("waits until the heartbeat succeeds", func(t *testing.T) {
+		attempts := 0
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "/diagnostic/status/heartbeat", r.URL.Path)
+			assert.Empty(t, r.Header.Get("Authorization"))
+
+			attempts++
+			if attempts < 3 {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			w.Write([]byte(`{"ok":true}`))
+		}))
+		defer server.Close()
+
+		err := clientForTest(server, 0).WaitForReady(time.Second)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 3, attempts)
+	})
+
+	t.Run("gives up after the timeout", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusBadGateway)
+		}))
+		defer server.Close()
+
+		err := clientForTest(server, 0).WaitForReady(20 * time.Millisecond)
+
+		assert.ErrorContains(t, err, "was not ready in time")
+		assert.ErrorContains(t, err, "status 502")
+	})
+}
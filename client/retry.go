@@ -0,0 +1,143 @@
+package client
+
+import (
+	"math"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	defaultRetryWaitMin = 1 * time.Second
+	defaultRetryWaitMax = 30 * time.Second
+)
+
+// idempotentMethods may safely be re-sent after a failure where the broker may have already processed the request
+var idempotentMethods = map[string]bool{
+	http.MethodGet:     true,
+	http.MethodHead:    true,
+	http.MethodOptions: true,
+	http.MethodPut:     true,
+	http.MethodDelete:  true,
+}
+
+// retryableStatusCodes are transient responses from the broker (or the infrastructure in front of it)
+var retryableStatusCodes = map[int]bool{
+	http.StatusTooManyRequests:    true,
+	http.StatusBadGateway:         true,
+	http.StatusServiceUnavailable: true,
+	http.StatusGatewayTimeout:     true,
+}
+
+// shouldRetry determines if a request should be attempted again, and if so, how long to wait before doing so
+func (c *Client) shouldRetry(req *http.Request, resp *http.Response, err error, attempt int) (time.Duration, bool) {
+	if attempt >= c.Config.MaxRetries {
+		return 0, false
+	}
+
+	// Never retry if the caller has given up
+	if req.Context().Err() != nil {
+		return 0, false
+	}
+
+	if err != nil {
+		// Connection errors are ambiguous: the broker may or may not have processed the request
+		return c.backoff(attempt), idempotentMethods[req.Method]
+	}
+
+	if !retryableStatusCodes[resp.StatusCode] {
+		return 0, false
+	}
+
+	// A 429 means the request was rejected before being processed, so is safe to retry for any method
+	if resp.StatusCode != http.StatusTooManyRequests && !idempotentMethods[req.Method] {
+		return 0, false
+	}
+
+	wait := c.backoff(attempt)
+	if after, ok := parseRetryAfter(resp.Header.Get("Retry-After")); ok {
+		// A Retry-After header may ask for a wait of hours (e.g. from a proxy), so it is capped by the maximum
+		wait = min(after, c.retryWaitMax())
+	}
+
+	// Return the response rather than waiting for a retry that the caller won't wait for
+	if deadline, ok := req.Context().Deadline(); ok && time.Until(deadline) < wait {
+		return 0, false
+	}
+
+	return wait, true
+}
+
+// retryWaitMax returns the configured maximum time to wait between retries, or the default
+func (c *Client) retryWaitMax() time.Duration {
+	if c.Config.RetryWaitMax <= 0 {
+		return defaultRetryWaitMax
+	}
+
+	return c.Config.RetryWaitMax
+}
+
+// backoff calculates an exponential wait time between the configured minimum and maximum, with jitter
+func (c *Client) backoff(attempt int) time.Duration {
+	min := c.Config.RetryWaitMin
+	if min <= 0 {
+		min = defaultRetryWaitMin
+	}
+	max := c.retryWaitMax()
+	if max < min {
+		max = min
+	}
+
+	wait := time.Duration(float64(min) * math.Pow(2, float64(attempt)))
+	if wait <= 0 || wait > max {
+		wait = max
+	}
+
+	// Jitter between half and the full wait time, to avoid parallel resources retrying in lockstep
+	half := int64(wait / 2)
+	if half <= 0 {
+		return wait
+	}
+
+	return time.Duration(half + rand.Int63n(half+1))
+}
+
+// parseRetryAfter supports both the delay-seconds and HTTP-date forms of the Retry-After header
+func parseRetryAfter(header string) (time.Duration, bool) {
+	if header == "" {
+		return 0, false
+	}
+
+	if seconds, err := strconv.Atoi(header); err == nil {
+		if seconds < 0 {
+			return 0, false
+		}
+		return time.Duration(seconds) * time.Second, true
+	}
+
+	if date, err := http.ParseTime(header); err == nil {
+		wait := time.Until(date)
+		if wait < 0 {
+			wait = 0
+		}
+		return wait, true
+	}
+
+	return 0, false
+}
+
+// rewindBody resets the request body so that it may be sent again
+func rewindBody(req *http.Request) error {
+	if req.GetBody == nil {
+		return nil
+	}
+
+	body, err := req.GetBody()
+	if err != nil {
+		return err
+	}
+	req.Body = body
+
+	return nil
+}
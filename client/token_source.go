@@ -0,0 +1,138 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+)
+
+// TokenSource supplies the bearer token used to authenticate to the broker. It is called for every request,
+// so that short-lived tokens can be rotated without re-configuring the client
+type TokenSource interface {
+	Token(ctx context.Context) (string, error)
+}
+
+// tokenInvalidator is implemented by token sources that cache a token, and can discard it once the broker rejects it
+type tokenInvalidator interface {
+	Invalidate()
+}
+
+// StaticTokenSource always returns the same token
+type StaticTokenSource string
+
+// Token returns the static token
+func (s StaticTokenSource) Token(ctx context.Context) (string, error) {
+	return string(s), nil
+}
+
+// FileTokenSource reads the token from a file on every request, such as one on a secrets mount that is rotated externally
+type FileTokenSource struct {
+	Path string
+}
+
+// Token returns the contents of the file, without surrounding whitespace
+func (s FileTokenSource) Token(ctx context.Context) (string, error) {
+	contents, err := os.ReadFile(s.Path)
+	if err != nil {
+		return "", fmt.Errorf("unable to read access token file: %w", err)
+	}
+
+	token := strings.TrimSpace(string(contents))
+	if token == "" {
+		return "", fmt.Errorf("access token file %s is empty", s.Path)
+	}
+
+	return token, nil
+}
+
+// tokenExpiryLeeway refreshes tokens shortly before they expire, so they don't expire in flight
+const tokenExpiryLeeway = 30 * time.Second
+
+// CommandTokenSource runs a command, using its standard output as the token (e.g. a Vault CLI invocation).
+// The token is cached until it expires (for JWTs with an "exp" claim), or until it is rejected by the broker
+type CommandTokenSource struct {
+	// Command is the program and its arguments. It is run directly, not via a shell
+	Command []string
+
+	mutex  sync.Mutex
+	token  string
+	expiry time.Time
+}
+
+// NewCommandTokenSource creates a token source for the given command and arguments
+func NewCommandTokenSource(command []string) *CommandTokenSource {
+	return &CommandTokenSource{Command: command}
+}
+
+// Token returns the cached token, re-running the command if there is none or it has expired
+func (s *CommandTokenSource) Token(ctx context.Context) (string, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.token != "" && (s.expiry.IsZero() || time.Now().Add(tokenExpiryLeeway).Before(s.expiry)) {
+		return s.token, nil
+	}
+
+	if len(s.Command) == 0 {
+		return "", errors.New("no access token command was given")
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, s.Command[0], s.Command[1:]...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("access token command %q failed: %w: %s", s.Command[0], err, strings.TrimSpace(stderr.String()))
+	}
+
+	token := strings.TrimSpace(stdout.String())
+	if token == "" {
+		return "", fmt.Errorf("access token command %q did not output a token", s.Command[0])
+	}
+
+	s.token = token
+	s.expiry = jwtExpiry(token)
+
+	return s.token, nil
+}
+
+// Invalidate discards the cached token, so that the command is run again for the next request
+func (s *CommandTokenSource) Invalidate() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.token = ""
+	s.expiry = time.Time{}
+}
+
+// jwtExpiry returns the expiry of a JWT, or the zero time if the token isn't a JWT or doesn't expire.
+// The signature is not verified, as the broker is responsible for that
+func jwtExpiry(token string) time.Time {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return time.Time{}
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(parts[1], "="))
+	if err != nil {
+		return time.Time{}
+	}
+
+	claims := struct {
+		Exp int64 `json:"exp"`
+	}{}
+	if err := json.Unmarshal(payload, &claims); err != nil || claims.Exp == 0 {
+		return time.Time{}
+	}
+
+	return time.Unix(claims.Exp, 0)
+}
@@ -0,0 +1,117 @@
+package client
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTokenSources(t *testing.T) {
+	t.Run("reads the token from a file for every request", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "token")
+		var tokens []string
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			tokens = append(tokens, r.Header.Get("Authorization"))
+			w.Write([]byte(`{"name":"terraform-client"}`))
+		}))
+		defer server.Close()
+
+		c := clientForTest(server, 0)
+		c.Config.TokenSource = FileTokenSource{Path: path}
+
+		assert.NoError(t, os.WriteFile(path, []byte("first\n"), 0600))
+		_, err := c.ReadPacticipant("terraform-client")
+		assert.NoError(t, err)
+
+		assert.NoError(t, os.WriteFile(path, []byte("second\n"), 0600))
+		_, err = c.ReadPacticipant("terraform-client")
+		assert.NoError(t, err)
+
+		assert.Equal(t, []string{"Bearer first", "Bearer second"}, tokens)
+	})
+
+	t.Run("fails the request if the token can't be obtained", func(t *testing.T) {
+		c := NewClient(nil, Config{TokenSource: FileTokenSource{Path: filepath.Join(t.TempDir(), "missing")}})
+
+		_, err := c.ReadPacticipant("terraform-client")
+
+		assert.ErrorContains(t, err, "unable to obtain an access token")
+	})
+
+	t.Run("caches the output of the command", func(t *testing.T) {
+		s := NewCommandTokenSource([]string{"echo", "1234"})
+
+		token, err := s.Token(context.Background())
+		assert.NoError(t, err)
+		assert.Equal(t, "1234", token)
+
+		s.Command = []string{"false"}
+		token, err = s.Token(context.Background())
+		assert.NoError(t, err)
+		assert.Equal(t, "1234", token)
+
+		s.Invalidate()
+		_, err = s.Token(context.Background())
+		assert.Error(t, err)
+	})
+
+	t.Run("re-runs the command once the token has expired", func(t *testing.T) {
+		expired := jwtForTest(time.Now().Add(-1 * time.Minute))
+		s := NewCommandTokenSource([]string{"echo", expired})
+
+		_, err := s.Token(context.Background())
+		assert.NoError(t, err)
+
+		s.Command = []string{"echo", "fresh"}
+		token, err := s.Token(context.Background())
+		assert.NoError(t, err)
+		assert.Equal(t, "fresh", token)
+	})
+
+	t.Run("fetches a new token when the broker rejects the current one", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "token")
+		assert.NoError(t, os.WriteFile(path, []byte("stale"), 0600))
+
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get("Authorization") != "Bearer fresh" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			w.Write([]byte(`{"name":"terraform-client"}`))
+		}))
+		defer server.Close()
+
+		c := clientForTest(server, 0)
+		c.Config.TokenSource = NewCommandTokenSource([]string{"cat", path})
+
+		_, err := c.ReadPacticipant("terraform-client")
+		assert.ErrorIs(t, err, ErrUnauthorized)
+
+		assert.NoError(t, os.WriteFile(path, []byte("fresh"), 0600))
+		res, err := c.ReadPacticipant("terraform-client")
+		assert.NoError(t, err)
+		assert.Equal(t, "terraform-client", res.Name)
+	})
+}
+
+func TestJWTExpiry(t *testing.T) {
+	exp := time.Unix(time.Now().Add(time.Hour).Unix(), 0)
+
+	assert.Equal(t, exp, jwtExpiry(jwtForTest(exp)))
+	assert.True(t, jwtExpiry("not-a-jwt").IsZero())
+	assert.True(t, jwtExpiry("a.b.c").IsZero())
+}
+
+func jwtForTest(exp time.Time) string {
+	encode := base64.RawURLEncoding.EncodeToString
+
+	return fmt.Sprintf("%s.%s.signature", encode([]byte(`{"alg":"HS256"}`)), encode([]byte(fmt.Sprintf(`{"exp":%d}`, exp.Unix()))))
+}
@@ -0,0 +1,86 @@
+package client
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"golang.org/x/net/http/httpproxy"
+)
+
+// TLSOptions describes how the broker's certificate is verified, and how the client authenticates to the broker
+// (or the ingress in front of it) with a certificate of its own
+type TLSOptions struct {
+	// Insecure disables verification of the broker's certificate
+	Insecure bool
+	// CACertPEM is a PEM encoded bundle of certificate authorities to trust, in addition to the system roots
+	CACertPEM []byte
+	// ClientCertPEM and ClientKeyPEM are the PEM encoded certificate and private key for mutual TLS
+	ClientCertPEM []byte
+	ClientKeyPEM  []byte
+}
+
+// NewTLSConfig builds a TLS configuration suitable for Config.CustomTLSConfig
+func NewTLSConfig(o TLSOptions) (*tls.Config, error) {
+	config := &tls.Config{
+		InsecureSkipVerify: o.Insecure,
+	}
+
+	if len(o.CACertPEM) > 0 {
+		pool, err := x509.SystemCertPool()
+		if err != nil || pool == nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(o.CACertPEM) {
+			return nil, errors.New("no valid PEM encoded certificates were found in the CA bundle")
+		}
+		config.RootCAs = pool
+	}
+
+	if len(o.ClientCertPEM) > 0 || len(o.ClientKeyPEM) > 0 {
+		if len(o.ClientCertPEM) == 0 || len(o.ClientKeyPEM) == 0 {
+			return nil, errors.New("both a client certificate and a client key are required for mutual TLS")
+		}
+		cert, err := tls.X509KeyPair(o.ClientCertPEM, o.ClientKeyPEM)
+		if err != nil {
+			return nil, fmt.Errorf("unable to load the client certificate: %w", err)
+		}
+		config.Certificates = []tls.Certificate{cert}
+	}
+
+	return config, nil
+}
+
+// newTransport creates a transport for a single client, so that its TLS and proxy settings
+// don't leak into other clients in the process (e.g. via http.DefaultClient)
+func newTransport(config Config) *http.Transport {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+
+	if config.CustomTLSConfig != nil {
+		transport.TLSClientConfig = config.CustomTLSConfig.Clone()
+	}
+
+	if config.ProxyURL != nil {
+		transport.Proxy = proxyFunc(config.ProxyURL, config.NoProxy)
+	}
+
+	return transport
+}
+
+// proxyFunc routes all requests via the given proxy, except for hosts matched by noProxy
+// (a comma separated list, in the same format as the NO_PROXY environment variable)
+func proxyFunc(proxy *url.URL, noProxy string) func(*http.Request) (*url.URL, error) {
+	proxyConfig := &httpproxy.Config{
+		HTTPProxy:  proxy.String(),
+		HTTPSProxy: proxy.String(),
+		NoProxy:    noProxy,
+	}
+	f := proxyConfig.ProxyFunc()
+
+	return func(req *http.Request) (*url.URL, error) {
+		return f(req.URL)
+	}
+}
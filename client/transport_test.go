@@ -0,0 +1,120 @@
+package client
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTransport(t *testing.T) {
+	t.Run("trusts a custom CA bundle", func(t *testing.T) {
+		server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(`{"name":"terraform-client"}`))
+		}))
+		defer server.Close()
+
+		_, err := tlsClientForTest(t, server, TLSOptions{}).ReadPacticipant("terraform-client")
+		assert.Error(t, err)
+
+		caCert := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+		res, err := tlsClientForTest(t, server, TLSOptions{CACertPEM: caCert}).ReadPacticipant("terraform-client")
+		assert.NoError(t, err)
+		assert.Equal(t, "terraform-client", res.Name)
+	})
+
+	t.Run("presents a client certificate for mutual TLS", func(t *testing.T) {
+		certPEM, keyPEM := generateCertificate(t)
+		pool := x509.NewCertPool()
+		pool.AppendCertsFromPEM(certPEM)
+
+		server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(`{"name":"terraform-client"}`))
+		}))
+		server.TLS = &tls.Config{ClientAuth: tls.RequireAndVerifyClientCert, ClientCAs: pool}
+		server.StartTLS()
+		defer server.Close()
+
+		_, err := tlsClientForTest(t, server, TLSOptions{Insecure: true}).ReadPacticipant("terraform-client")
+		assert.Error(t, err)
+
+		_, err = tlsClientForTest(t, server, TLSOptions{Insecure: true, ClientCertPEM: certPEM, ClientKeyPEM: keyPEM}).ReadPacticipant("terraform-client")
+		assert.NoError(t, err)
+	})
+
+	t.Run("rejects invalid certificates", func(t *testing.T) {
+		_, err := NewTLSConfig(TLSOptions{CACertPEM: []byte("not a certificate")})
+		assert.Error(t, err)
+
+		certPEM, _ := generateCertificate(t)
+		_, err = NewTLSConfig(TLSOptions{ClientCertPEM: certPEM})
+		assert.Error(t, err)
+	})
+
+	t.Run("does not modify the default http client", func(t *testing.T) {
+		NewClient(nil, Config{CustomTLSConfig: &tls.Config{InsecureSkipVerify: true}})
+
+		assert.Nil(t, http.DefaultClient.Transport)
+	})
+
+	t.Run("sends requests via the proxy, except for excluded hosts", func(t *testing.T) {
+		proxy, _ := url.Parse("http://proxy.example.com:3128")
+		f := proxyFunc(proxy, ".internal.example.com")
+
+		req, _ := http.NewRequest("GET", "https://broker.example.com/", nil)
+		u, err := f(req)
+		assert.NoError(t, err)
+		assert.Equal(t, proxy.String(), u.String())
+
+		req, _ = http.NewRequest("GET", "https://broker.internal.example.com/", nil)
+		u, err = f(req)
+		assert.NoError(t, err)
+		assert.Nil(t, u)
+	})
+}
+
+func tlsClientForTest(t *testing.T, server *httptest.Server, o TLSOptions) *Client {
+	tlsConfig, err := NewTLSConfig(o)
+	assert.NoError(t, err)
+
+	c := clientForTest(server, 0)
+	return NewClient(nil, Config{
+		BaseURL:         c.Config.BaseURL,
+		AccessToken:     c.Config.AccessToken,
+		CustomTLSConfig: tlsConfig,
+	})
+}
+
+func generateCertificate(t *testing.T) ([]byte, []byte) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "terraform-client"},
+		NotBefore:             time.Now().Add(-1 * time.Hour),
+		NotAfter:              time.Now().Add(1 * time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	assert.NoError(t, err)
+
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	assert.NoError(t, err)
+
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+}
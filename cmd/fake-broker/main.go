@@ -0,0 +1,31 @@
+// Command fake-broker serves an in-memory Pact Broker (or Pactflow) API, for running acceptance tests without a broker.
+// All data is lost when it exits
+package main
+
+import (
+	"flag"
+	"log"
+	"net/http"
+
+	"github.com/pactflow/terraform/client"
+	"github.com/pactflow/terraform/client/clienttest"
+)
+
+func main() {
+	addr := flag.String("addr", "localhost:9393", "address to listen on")
+	token := flag.String("token", "", "bearer token that requests must be authenticated with (any credentials are accepted if empty)")
+	flavour := flag.String("flavour", client.PactflowBroker, "broker to emulate, either \"pactflow\" or \"oss\" (which doesn't advertise Pactflow relations)")
+	flag.Parse()
+
+	f := clienttest.NewFake()
+	switch *flavour {
+	case client.PactflowBroker:
+	case client.OSSBroker:
+		f.Info = clienttest.OSSBrokerInfo()
+	default:
+		log.Fatalf("unknown flavour %q, need one of %q or %q", *flavour, client.PactflowBroker, client.OSSBroker)
+	}
+
+	log.Printf("fake %s broker listening on http://%s", *flavour, *addr)
+	log.Fatal(http.ListenAndServe(*addr, clienttest.NewHandler(f, *token)))
+}
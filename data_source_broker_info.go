@@ -0,0 +1,60 @@
+package main
+
+import (
+	"context"
+	"log"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/pactflow/terraform/client"
+)
+
+func brokerInfo() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: stoppable(brokerInfoRead),
+		Schema: map[string]*schema.Schema{
+			"flavour": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The type of broker, either 'oss' or 'pactflow'. Empty if it could not be detected",
+			},
+			"pactflow": {
+				Type:        schema.TypeBool,
+				Computed:    true,
+				Description: "Whether the broker is the Pactflow platform",
+			},
+			"version": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The version of the broker, if advertised",
+			},
+			"relations": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Description: "The relations advertised in the broker index (e.g. pb:environments)",
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
+		},
+	}
+}
+
+func brokerInfoRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	httpClient := meta.(client.BrokerAPI)
+
+	log.Println("[DEBUG] reading broker info")
+
+	if err := httpClient.DiscoverWithContext(ctx); err != nil {
+		return brokerDiagnostics(d, "Unable to read the broker index", err)
+	}
+	info := httpClient.BrokerInfo()
+
+	d.SetId(httpClient.BaseURL().String())
+	d.Set("flavour", info.Flavour)
+	d.Set("pactflow", info.Flavour == client.PactflowBroker)
+	d.Set("version", info.Version)
+	d.Set("relations", info.Relations)
+
+	return nil
+}
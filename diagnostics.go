@@ -0,0 +1,151 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/hashicorp/go-cty/cty"
+	fwdiag "github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-go/tfprotov6"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/pactflow/terraform/client"
+)
+
+// brokerDiagnostics describes a failed operation. The summary says what the provider was doing (e.g. "Unable to create
+// team") and the detail why it failed. Validation errors for individual fields are attached to the matching attribute,
+// and the broker's error reference is included so that it can be quoted to Pactflow support
+func brokerDiagnostics(d *schema.ResourceData, summary string, err error) diag.Diagnostics {
+	var apiErr *client.APIError
+	if !errors.As(err, &apiErr) {
+		return diag.Diagnostics{{Severity: diag.Error, Summary: summary, Detail: err.Error()}}
+	}
+
+	var diags diag.Diagnostics
+	detail := new(strings.Builder)
+	fmt.Fprintf(detail, "The broker responded to %s %s with %d %s", apiErr.Method, apiErr.Path, apiErr.StatusCode, http.StatusText(apiErr.StatusCode))
+
+	if apiErr.Message != "" {
+		fmt.Fprintf(detail, ": %s", apiErr.Message)
+	}
+	for _, e := range apiErr.Errors {
+		fmt.Fprintf(detail, "\n\n%s", e)
+	}
+
+	fields := make([]string, 0, len(apiErr.FieldErrors))
+	for k := range apiErr.FieldErrors {
+		fields = append(fields, k)
+	}
+	sort.Strings(fields)
+
+	for _, field := range fields {
+		messages := strings.Join(apiErr.FieldErrors[field], ", ")
+		attribute := attributeName(field)
+
+		// Errors for fields that aren't part of the resource's configuration (e.g. nested or derived fields) stay
+		// with the overall error
+		if d == nil || !d.GetRawConfig().Type().IsObjectType() || !d.GetRawConfig().Type().HasAttribute(attribute) {
+			fmt.Fprintf(detail, "\n\n%s %s", field, messages)
+			continue
+		}
+
+		diags = append(diags, diag.Diagnostic{
+			Severity:      diag.Error,
+			Summary:       summary,
+			Detail:        fmt.Sprintf("The broker rejected the value of %q: %s%s", attribute, messages, referenceDetail(apiErr)),
+			AttributePath: cty.GetAttrPath(attribute),
+		})
+	}
+
+	if len(diags) > 0 && len(fields) == len(diags) && apiErr.Message == "" && len(apiErr.Errors) == 0 {
+		return diags
+	}
+
+	detail.WriteString(referenceDetail(apiErr))
+
+	return append(diag.Diagnostics{{Severity: diag.Error, Summary: summary, Detail: detail.String()}}, diags...)
+}
+
+// referenceDetail is appended to a diagnostic's detail, if the broker identified the error
+func referenceDetail(apiErr *client.APIError) string {
+	if apiErr.Reference == "" {
+		return ""
+	}
+
+	return fmt.Sprintf("\n\nError reference: %s (please include this when contacting Pactflow support)", apiErr.Reference)
+}
+
+// stateDiagnostics describes a failure to save a resource's attributes to the Terraform state
+func stateDiagnostics(resource string, err error) diag.Diagnostics {
+	return diag.Diagnostics{{
+		Severity: diag.Error,
+		Summary:  fmt.Sprintf("Unable to save the state of the %s", resource),
+		Detail:   err.Error(),
+	}}
+}
+
+// frameworkDiagnostics converts SDK diagnostics (e.g. from brokerDiagnostics) for the framework provider
+func frameworkDiagnostics(diags diag.Diagnostics) fwdiag.Diagnostics {
+	var res fwdiag.Diagnostics
+	for _, d := range diags {
+		attribute, ok := rootAttribute(d.AttributePath)
+		switch {
+		case d.Severity == diag.Warning && ok:
+			res.AddAttributeWarning(path.Root(attribute), d.Summary, d.Detail)
+		case d.Severity == diag.Warning:
+			res.AddWarning(d.Summary, d.Detail)
+		case ok:
+			res.AddAttributeError(path.Root(attribute), d.Summary, d.Detail)
+		default:
+			res.AddError(d.Summary, d.Detail)
+		}
+	}
+
+	return res
+}
+
+// protoDiagnostics converts the diagnostics returned by a provider server (e.g. the SDK provider's) for the framework provider
+func protoDiagnostics(diags []*tfprotov6.Diagnostic) fwdiag.Diagnostics {
+	var res fwdiag.Diagnostics
+	for _, d := range diags {
+		if d.Severity == tfprotov6.DiagnosticSeverityWarning {
+			res.AddWarning(d.Summary, d.Detail)
+		} else {
+			res.AddError(d.Summary, d.Detail)
+		}
+	}
+
+	return res
+}
+
+// rootAttribute returns the name of the attribute, for a path to a top-level attribute
+func rootAttribute(p cty.Path) (string, bool) {
+	if len(p) != 1 {
+		return "", false
+	}
+	step, ok := p[0].(cty.GetAttrStep)
+
+	return step.Name, ok
+}
+
+// attributeName converts the name of a field in a broker request (e.g. "displayName") to the name of the equivalent
+// resource attribute (e.g. "display_name")
+func attributeName(field string) string {
+	name := new(strings.Builder)
+	for i, r := range field {
+		if unicode.IsUpper(r) {
+			if i > 0 {
+				name.WriteRune('_')
+			}
+			r = unicode.ToLower(r)
+		}
+		name.WriteRune(r)
+	}
+
+	return name.String()
+}
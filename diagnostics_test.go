@@ -0,0 +1,68 @@
+package main
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/pactflow/terraform/client"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBrokerDiagnostics(t *testing.T) {
+	d := schema.TestResourceDataRaw(t, application().Schema, map[string]interface{}{"name": "terraform-client"})
+
+	t.Run("separates the summary from the detail", func(t *testing.T) {
+		diags := brokerDiagnostics(d, "Unable to create application", errors.New("connection reset"))
+
+		assert.Equal(t, diag.Diagnostics{{Severity: diag.Error, Summary: "Unable to create application", Detail: "connection reset"}}, diags)
+	})
+
+	t.Run("attaches validation errors to the matching attribute", func(t *testing.T) {
+		err := &client.APIError{
+			StatusCode: 400,
+			Method:     "POST",
+			Path:       "/pacticipants",
+			FieldErrors: map[string][]string{
+				"displayName": {"is too long"},
+			},
+		}
+
+		diags := brokerDiagnostics(d, "Unable to create application", err)
+
+		assert.Len(t, diags, 1)
+		assert.Equal(t, cty.GetAttrPath("display_name"), diags[0].AttributePath)
+		assert.Contains(t, diags[0].Detail, "is too long")
+	})
+
+	t.Run("keeps errors for unknown fields and the error reference in the detail", func(t *testing.T) {
+		err := &client.APIError{
+			StatusCode: 500,
+			Method:     "POST",
+			Path:       "/pacticipants",
+			Reference:  "a1b2c3",
+			Message:    "Something went wrong",
+			FieldErrors: map[string][]string{
+				"mainBranch": {"is invalid"},
+				"labels":     {"must be unique"},
+			},
+		}
+
+		diags := brokerDiagnostics(d, "Unable to create application", err)
+
+		assert.Len(t, diags, 2)
+		assert.Nil(t, diags[0].AttributePath)
+		assert.Contains(t, diags[0].Detail, "POST /pacticipants with 500 Internal Server Error: Something went wrong")
+		assert.Contains(t, diags[0].Detail, "labels must be unique")
+		assert.Contains(t, diags[0].Detail, "Error reference: a1b2c3")
+		assert.Equal(t, cty.GetAttrPath("main_branch"), diags[1].AttributePath)
+	})
+}
+
+func TestAttributeName(t *testing.T) {
+	assert.Equal(t, "name", attributeName("name"))
+	assert.Equal(t, "display_name", attributeName("displayName"))
+	assert.Equal(t, "repository_url", attributeName("repositoryUrl"))
+}
@@ -0,0 +1,135 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/hashicorp/terraform-plugin-framework/ephemeral"
+	"github.com/hashicorp/terraform-plugin-framework/ephemeral/schema"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/pactflow/terraform/broker"
+	"github.com/pactflow/terraform/client"
+)
+
+// systemAccountTokenEphemeral is the ephemeral pact_system_account_token, which reads the token of a system account
+// (e.g. one created with pact_user) during a run without the value being written to the state or plan
+type systemAccountTokenEphemeral struct {
+	client client.BrokerAPI
+}
+
+type systemAccountTokenEphemeralModel struct {
+	SystemAccount types.String `tfsdk:"system_account"`
+	Type          types.String `tfsdk:"type"`
+	UUID          types.String `tfsdk:"uuid"`
+	Description   types.String `tfsdk:"description"`
+	Value         types.String `tfsdk:"value"`
+}
+
+var _ ephemeral.EphemeralResourceWithConfigure = &systemAccountTokenEphemeral{}
+var _ ephemeral.EphemeralResourceWithValidateConfig = &systemAccountTokenEphemeral{}
+
+func newSystemAccountTokenEphemeral() ephemeral.EphemeralResource {
+	return &systemAccountTokenEphemeral{}
+}
+
+func (e *systemAccountTokenEphemeral) Metadata(ctx context.Context, req ephemeral.MetadataRequest, resp *ephemeral.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_system_account_token"
+}
+
+func (e *systemAccountTokenEphemeral) Schema(ctx context.Context, req ephemeral.SchemaRequest, resp *ephemeral.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Description: "An API token of a system account. The value is never written to the state or plan, so may only be passed to other ephemeral values, such as a write-only attribute or a provider configuration",
+		Attributes: map[string]schema.Attribute{
+			"system_account": schema.StringAttribute{
+				Required:    true,
+				Description: "The UUID of the system account",
+			},
+			"type": schema.StringAttribute{
+				Optional:    true,
+				Description: "The type of token (valid values are 'read-only' and 'read-write'). Defaults to the first token of the system account",
+			},
+			"uuid": schema.StringAttribute{
+				Computed:    true,
+				Description: "The UUID of the token",
+			},
+			"description": schema.StringAttribute{
+				Computed:    true,
+				Description: "The description of the token as defined by the broker",
+			},
+			"value": schema.StringAttribute{
+				Computed:    true,
+				Sensitive:   true,
+				Description: "The actual API token",
+			},
+		},
+	}
+}
+
+func (e *systemAccountTokenEphemeral) Configure(ctx context.Context, req ephemeral.ConfigureRequest, resp *ephemeral.ConfigureResponse) {
+	e.client, _ = req.ProviderData.(client.BrokerAPI)
+}
+
+func (e *systemAccountTokenEphemeral) ValidateConfig(ctx context.Context, req ephemeral.ValidateConfigRequest, resp *ephemeral.ValidateConfigResponse) {
+	var tokenType types.String
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("type"), &tokenType)...)
+
+	if tokenType.IsNull() || tokenType.IsUnknown() {
+		return
+	}
+	if _, ok := allowedTokenTypes[tokenType.ValueString()]; !ok {
+		resp.Diagnostics.AddAttributeError(path.Root("type"), "Invalid token type",
+			fmt.Sprintf("type must be one of the allowed types %v, got %s", allowedTokenTypes, tokenType.ValueString()))
+	}
+}
+
+func (e *systemAccountTokenEphemeral) Open(ctx context.Context, req ephemeral.OpenRequest, resp *ephemeral.OpenResponse) {
+	var model systemAccountTokenEphemeralModel
+	resp.Diagnostics.Append(req.Config.Get(ctx, &model)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if e.client == nil {
+		resp.Diagnostics.AddError("Provider not configured", "The provider must be configured before the pact_system_account_token ephemeral resource is opened")
+		return
+	}
+	if err := checkPactflow("pact_system_account_token", e.client); err != nil {
+		resp.Diagnostics.AddError("Unsupported broker", err.Error())
+		return
+	}
+
+	uuid := model.SystemAccount.ValueString()
+	log.Println("[DEBUG] reading tokens of system account", uuid)
+
+	tokens, err := e.client.ReadSystemAccountTokensWithContext(ctx, uuid)
+	if err != nil {
+		resp.Diagnostics.Append(frameworkDiagnostics(brokerDiagnostics(nil, "Unable to read the tokens of system account", err))...)
+		return
+	}
+
+	token, ok := findToken(tokens.Embedded.Items, model.Type.ValueString())
+	if !ok {
+		resp.Diagnostics.AddAttributeError(path.Root("type"), "Token not found",
+			fmt.Sprintf("system account %s does not have a %s token", uuid, model.Type.ValueString()))
+		return
+	}
+
+	model.UUID = types.StringValue(token.UUID)
+	model.Description = types.StringValue(token.Description)
+	model.Value = types.StringValue(token.Value)
+
+	resp.Diagnostics.Append(resp.Result.Set(ctx, model)...)
+}
+
+// findToken returns the token of the given type, or the first token if no type is given
+func findToken(tokens []broker.APIToken, tokenType string) (broker.APIToken, bool) {
+	for _, t := range tokens {
+		if tokenType == "" || t.Description == allowedTokenTypes[tokenType] {
+			return t, true
+		}
+	}
+
+	return broker.APIToken{}, false
+}
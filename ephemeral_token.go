@@ -0,0 +1,112 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/hashicorp/terraform-plugin-framework/ephemeral"
+	"github.com/hashicorp/terraform-plugin-framework/ephemeral/schema"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/pactflow/terraform/client"
+)
+
+// tokenEphemeral is the ephemeral pact_token, which reads one of the authenticated user's tokens during a run without
+// the value being written to the state or plan. It never regenerates the token, as it is opened during every plan,
+// so rotation is left to the pact_token resource
+type tokenEphemeral struct {
+	client client.BrokerAPI
+}
+
+type tokenEphemeralModel struct {
+	Type        types.String `tfsdk:"type"`
+	UUID        types.String `tfsdk:"uuid"`
+	Description types.String `tfsdk:"description"`
+	Value       types.String `tfsdk:"value"`
+}
+
+var _ ephemeral.EphemeralResourceWithConfigure = &tokenEphemeral{}
+var _ ephemeral.EphemeralResourceWithValidateConfig = &tokenEphemeral{}
+
+func newTokenEphemeral() ephemeral.EphemeralResource {
+	return &tokenEphemeral{}
+}
+
+func (e *tokenEphemeral) Metadata(ctx context.Context, req ephemeral.MetadataRequest, resp *ephemeral.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_token"
+}
+
+func (e *tokenEphemeral) Schema(ctx context.Context, req ephemeral.SchemaRequest, resp *ephemeral.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Description: "An API token of the authenticated user. The value is never written to the state or plan, so may only be passed to other ephemeral values, such as a write-only attribute or a provider configuration",
+		Attributes: map[string]schema.Attribute{
+			"type": schema.StringAttribute{
+				Required:    true,
+				Description: "The type of token (valid values are 'read-only' and 'read-write')",
+			},
+			"uuid": schema.StringAttribute{
+				Computed:    true,
+				Description: "The UUID of the token",
+			},
+			"description": schema.StringAttribute{
+				Computed:    true,
+				Description: "The description of the token as defined by the broker",
+			},
+			"value": schema.StringAttribute{
+				Computed:    true,
+				Sensitive:   true,
+				Description: "The actual API token",
+			},
+		},
+	}
+}
+
+func (e *tokenEphemeral) Configure(ctx context.Context, req ephemeral.ConfigureRequest, resp *ephemeral.ConfigureResponse) {
+	e.client, _ = req.ProviderData.(client.BrokerAPI)
+}
+
+func (e *tokenEphemeral) ValidateConfig(ctx context.Context, req ephemeral.ValidateConfigRequest, resp *ephemeral.ValidateConfigResponse) {
+	var tokenType types.String
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("type"), &tokenType)...)
+
+	if tokenType.IsNull() || tokenType.IsUnknown() {
+		return
+	}
+	if _, ok := allowedTokenTypes[tokenType.ValueString()]; !ok {
+		resp.Diagnostics.AddAttributeError(path.Root("type"), "Invalid token type",
+			fmt.Sprintf("type must be one of the allowed types %v, got %s", allowedTokenTypes, tokenType.ValueString()))
+	}
+}
+
+func (e *tokenEphemeral) Open(ctx context.Context, req ephemeral.OpenRequest, resp *ephemeral.OpenResponse) {
+	var model tokenEphemeralModel
+	resp.Diagnostics.Append(req.Config.Get(ctx, &model)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if e.client == nil {
+		resp.Diagnostics.AddError("Provider not configured", "The provider must be configured before the pact_token ephemeral resource is opened")
+		return
+	}
+	if err := checkPactflow("pact_token", e.client); err != nil {
+		resp.Diagnostics.AddError("Unsupported broker", err.Error())
+		return
+	}
+
+	tokenType := model.Type.ValueString()
+	log.Println("[DEBUG] reading token for ephemeral resource", tokenType)
+
+	token, err := e.client.FindTokenByTypeWithContext(ctx, tokenType)
+	if err != nil {
+		resp.Diagnostics.Append(frameworkDiagnostics(brokerDiagnostics(nil, "Unable to find token", err))...)
+		return
+	}
+
+	model.UUID = types.StringValue(token.UUID)
+	model.Description = types.StringValue(token.Description)
+	model.Value = types.StringValue(token.Value)
+
+	resp.Diagnostics.Append(resp.Result.Set(ctx, model)...)
+}
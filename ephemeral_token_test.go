@@ -0,0 +1,139 @@
+package main
+
+import (
+	"context"
+	"testing"
+
+	fwdiag "github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/ephemeral"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+	"github.com/pactflow/terraform/broker"
+	"github.com/pactflow/terraform/client"
+	"github.com/pactflow/terraform/client/clienttest"
+	"github.com/stretchr/testify/assert"
+)
+
+// openEphemeral opens the ephemeral resource with the given configuration, returning the attributes of the result
+func openEphemeral(t *testing.T, e ephemeral.EphemeralResource, c client.BrokerAPI, config map[string]tftypes.Value) (map[string]tftypes.Value, fwdiag.Diagnostics) {
+	ctx := context.Background()
+
+	schemaResp := &ephemeral.SchemaResponse{}
+	e.Schema(ctx, ephemeral.SchemaRequest{}, schemaResp)
+	objectType := schemaResp.Schema.Type().TerraformType(ctx).(tftypes.Object)
+
+	values := make(map[string]tftypes.Value, len(objectType.AttributeTypes))
+	for k, typ := range objectType.AttributeTypes {
+		values[k] = tftypes.NewValue(typ, nil)
+	}
+	for k, v := range config {
+		values[k] = v
+	}
+
+	e.(ephemeral.EphemeralResourceWithConfigure).Configure(ctx, ephemeral.ConfigureRequest{ProviderData: c}, &ephemeral.ConfigureResponse{})
+
+	resp := &ephemeral.OpenResponse{Result: tfsdk.EphemeralResultData{Schema: schemaResp.Schema, Raw: tftypes.NewValue(objectType, nil)}}
+	e.Open(ctx, ephemeral.OpenRequest{Config: tfsdk.Config{Schema: schemaResp.Schema, Raw: tftypes.NewValue(objectType, values)}}, resp)
+
+	result := map[string]tftypes.Value{}
+	if !resp.Diagnostics.HasError() {
+		assert.NoError(t, resp.Result.Raw.As(&result))
+	}
+
+	return result, resp.Diagnostics
+}
+
+func stringValue(t *testing.T, v tftypes.Value) string {
+	var s string
+	assert.NoError(t, v.As(&s))
+
+	return s
+}
+
+func TestTokenEphemeral(t *testing.T) {
+	t.Run("reads the token without regenerating it", func(t *testing.T) {
+		f := clienttest.NewFake()
+		token, err := f.FindTokenByType("read-only")
+		assert.NoError(t, err)
+
+		res, diags := openEphemeral(t, newTokenEphemeral(), f, map[string]tftypes.Value{
+			"type": tftypes.NewValue(tftypes.String, "read-only"),
+		})
+
+		assert.False(t, diags.HasError())
+		assert.Equal(t, token.UUID, stringValue(t, res["uuid"]))
+		assert.Equal(t, token.Value, stringValue(t, res["value"]))
+	})
+
+	t.Run("never modifies the token", func(t *testing.T) {
+		f := clienttest.NewFake()
+		token, err := f.FindTokenByType("read-write")
+		assert.NoError(t, err)
+
+		res, diags := openEphemeral(t, newTokenEphemeral(), f, map[string]tftypes.Value{
+			"type": tftypes.NewValue(tftypes.String, "read-write"),
+		})
+
+		assert.Empty(t, diags)
+		assert.Equal(t, token.Value, stringValue(t, res["value"]))
+		assert.NotContains(t, f.Calls, "RegenerateToken")
+	})
+
+	t.Run("is only supported by Pactflow", func(t *testing.T) {
+		f := clienttest.NewFake()
+		f.Info = clienttest.OSSBrokerInfo()
+
+		_, diags := openEphemeral(t, newTokenEphemeral(), f, map[string]tftypes.Value{
+			"type": tftypes.NewValue(tftypes.String, "read-only"),
+		})
+
+		assert.True(t, diags.HasError())
+	})
+
+	t.Run("reports broker errors", func(t *testing.T) {
+		f := clienttest.NewFake()
+		f.Errors["FindTokenByType"] = client.ErrForbidden
+
+		_, diags := openEphemeral(t, newTokenEphemeral(), f, map[string]tftypes.Value{
+			"type": tftypes.NewValue(tftypes.String, "read-only"),
+		})
+
+		assert.True(t, diags.HasError())
+		assert.Equal(t, "Unable to find token", diags[0].Summary())
+	})
+}
+
+func TestSystemAccountTokenEphemeral(t *testing.T) {
+	f := clienttest.NewFake()
+	account, err := f.CreateSystemAccount(broker.User{Name: "CI"})
+	assert.NoError(t, err)
+	tokens, err := f.ReadSystemAccountTokens(account.UUID)
+	assert.NoError(t, err)
+
+	t.Run("reads the first token by default", func(t *testing.T) {
+		res, diags := openEphemeral(t, newSystemAccountTokenEphemeral(), f, map[string]tftypes.Value{
+			"system_account": tftypes.NewValue(tftypes.String, account.UUID),
+		})
+
+		assert.Empty(t, diags)
+		assert.Equal(t, tokens.Embedded.Items[0].Value, stringValue(t, res["value"]))
+	})
+
+	t.Run("reports a missing token type", func(t *testing.T) {
+		_, diags := openEphemeral(t, newSystemAccountTokenEphemeral(), f, map[string]tftypes.Value{
+			"system_account": tftypes.NewValue(tftypes.String, account.UUID),
+			"type":           tftypes.NewValue(tftypes.String, "read-only"),
+		})
+
+		assert.True(t, diags.HasError())
+		assert.Equal(t, "Token not found", diags[0].Summary())
+	})
+
+	t.Run("reports an unknown system account", func(t *testing.T) {
+		_, diags := openEphemeral(t, newSystemAccountTokenEphemeral(), f, map[string]tftypes.Value{
+			"system_account": tftypes.NewValue(tftypes.String, "unknown"),
+		})
+
+		assert.True(t, diags.HasError())
+	})
+}
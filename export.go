@@ -0,0 +1,480 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"log"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	"github.com/hashicorp/logutils"
+	"github.com/pactflow/terraform/broker"
+	"github.com/pactflow/terraform/client"
+	"github.com/zclconf/go-cty/cty"
+	ctyjson "github.com/zclconf/go-cty/cty/json"
+)
+
+// runExport implements the export subcommand, which writes the configuration of an existing broker as Terraform
+// configuration with import blocks, so that it can be adopted by Terraform. Returns the exit code
+func runExport(args []string) int {
+	flags := flag.NewFlagSet("export", flag.ContinueOnError)
+	host := flags.String("host", os.Getenv(client.EnvBaseURL), "URL of the broker, defaults to the PACT_BROKER_BASE_URL environment variable")
+	token := flags.String("token", os.Getenv(client.EnvToken), "API token, defaults to the PACT_BROKER_TOKEN environment variable")
+	username := flags.String("username", os.Getenv(client.EnvUsername), "basic auth username, defaults to the PACT_BROKER_USERNAME environment variable")
+	password := flags.String("password", os.Getenv(client.EnvPassword), "basic auth password, defaults to the PACT_BROKER_PASSWORD environment variable")
+	out := flags.String("out", "", "directory to write the configuration to, which must not already contain exported files")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: terraform-provider-pact export --host URL --out DIR")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if *host == "" || *out == "" {
+		flags.Usage()
+		return 2
+	}
+
+	baseURL, err := url.Parse(strings.TrimRight(*host, "/"))
+	if err != nil {
+		log.Println("invalid host:", err)
+		return 1
+	}
+	config := client.Config{BaseURL: baseURL, AccessToken: *token, BasicAuthUsername: *username, BasicAuthPassword: *password, ReadOnly: true}
+	if err := config.Validate(); err != nil {
+		log.Println("invalid credentials:", err)
+		return 1
+	}
+
+	c := client.NewClient(nil, config)
+	if err := c.Discover(); err != nil {
+		log.Println("[WARN] unable to discover resources from the broker index, using default locations:", err)
+	}
+
+	files, err := exportConfiguration(context.Background(), c)
+	if err != nil {
+		log.Println("unable to export the configuration:", err)
+		return 1
+	}
+	if err := writeFiles(*out, files); err != nil {
+		log.Println(err)
+		return 1
+	}
+
+	return 0
+}
+
+// exportLogFilter hides the provider's debug logging, which Terraform would otherwise filter, unless TF_LOG is set.
+// Messages without a level (e.g. progress) are always shown
+func exportLogFilter(w io.Writer) io.Writer {
+	level := logutils.LogLevel(strings.ToUpper(os.Getenv("TF_LOG")))
+	if level == "" {
+		level = "WARN"
+	}
+
+	return &logutils.LevelFilter{Levels: []logutils.LogLevel{"TRACE", "DEBUG", "INFO", "WARN", "ERROR"}, MinLevel: level, Writer: w}
+}
+
+// writeFiles writes the exported files, refusing to overwrite any existing file
+func writeFiles(dir string, files map[string][]byte) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+
+	names := make([]string, 0, len(files))
+	for name := range files {
+		if _, err := os.Stat(filepath.Join(dir, name)); !errors.Is(err, fs.ErrNotExist) {
+			return fmt.Errorf("%s already exists in %s, export to an empty directory", name, dir)
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		if err := os.WriteFile(filepath.Join(dir, name), files[name], 0o644); err != nil {
+			return err
+		}
+		log.Println("wrote", filepath.Join(dir, name))
+	}
+
+	return nil
+}
+
+// exporter generates the configuration of each type of resource, recording the address of each exported resource so
+// that later resources can refer to it rather than its UUID
+type exporter struct {
+	c     client.BrokerAPI
+	files map[string]*hclwrite.File
+	// names are the resource names used so far, by resource type
+	names map[string]map[string]bool
+	// addresses are the exported resources (e.g. pact_team.platform), by UUID
+	addresses map[string]hcl.Traversal
+}
+
+// exportConfiguration returns the configuration of the broker's resources, by file name. Resources are exported in
+// dependency order, so that references can be resolved
+func exportConfiguration(ctx context.Context, c client.BrokerAPI) (map[string][]byte, error) {
+	e := &exporter{c: c, files: map[string]*hclwrite.File{}, names: map[string]map[string]bool{}, addresses: map[string]hcl.Traversal{}}
+
+	steps := []func(context.Context) error{e.environments, e.webhooks}
+	// The other resources are only available on the Pactflow platform
+	if checkPactflow("export", c) == nil {
+		steps = []func(context.Context) error{e.roles, e.users, e.teams, e.environments, e.secrets, e.webhooks}
+	}
+	for _, step := range steps {
+		if err := step(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	res := make(map[string][]byte, len(e.files))
+	for name, f := range e.files {
+		res[name] = f.Bytes()
+	}
+
+	return res, nil
+}
+
+func (e *exporter) roles(ctx context.Context) error {
+	for r, err := range e.c.ListRolesWithContext(ctx, client.ListOptions{}) {
+		if err != nil {
+			return fmt.Errorf("unable to list roles: %w", err)
+		}
+
+		body := e.resource("roles.tf", "pact_role", r.Name, r.UUID)
+		body.SetAttributeValue("name", cty.StringVal(r.Name))
+		body.SetAttributeValue("scopes", stringList(scopesFromUser(r)))
+	}
+
+	return nil
+}
+
+func (e *exporter) users(ctx context.Context) error {
+	for u, err := range e.c.ListUsersWithContext(ctx, client.ListOptions{}) {
+		if err != nil {
+			return fmt.Errorf("unable to list users: %w", err)
+		}
+
+		// Users are read individually, as the list doesn't include their roles
+		user, err := e.c.ReadUserWithContext(ctx, u.UUID)
+		if err != nil {
+			return fmt.Errorf("unable to read user %s: %w", u.UUID, err)
+		}
+
+		name := user.Name
+		if local, _, ok := strings.Cut(user.Email, "@"); ok {
+			name = local
+		}
+
+		body := e.resource("users.tf", "pact_user", name, user.UUID)
+		body.SetAttributeValue("name", cty.StringVal(user.Name))
+		if user.Email != "" {
+			body.SetAttributeValue("email", cty.StringVal(user.Email))
+		}
+		if user.Type == broker.SystemAccount {
+			body.SetAttributeValue("type", cty.StringVal(userTypeAsString(user.Type)))
+		}
+		body.SetAttributeValue("active", cty.BoolVal(user.Active))
+		if roles := rolesFromUser(*user); len(roles) > 0 {
+			body.SetAttributeRaw("roles", e.references(roles))
+		}
+	}
+
+	return nil
+}
+
+func (e *exporter) teams(ctx context.Context) error {
+	for t, err := range e.c.ListTeamsWithContext(ctx, client.ListOptions{}) {
+		if err != nil {
+			return fmt.Errorf("unable to list teams: %w", err)
+		}
+
+		// Teams are read individually, as the list doesn't include their members
+		team, err := e.c.ReadTeamWithContext(ctx, t)
+		if err != nil {
+			return fmt.Errorf("unable to read team %s: %w", t.UUID, err)
+		}
+
+		body := e.resource("teams.tf", "pact_team", team.Name, team.UUID)
+		body.SetAttributeValue("name", cty.StringVal(team.Name))
+
+		pacticipants := make([]string, len(team.Embedded.Pacticipants))
+		for i, p := range team.Embedded.Pacticipants {
+			pacticipants[i] = p.Name
+		}
+		if len(pacticipants) > 0 {
+			body.SetAttributeValue("pacticipants", stringList(pacticipants))
+		}
+		if users := teamUserUUIDs(team.Embedded.Members); len(users) > 0 {
+			body.SetAttributeRaw("users", e.references(users))
+		}
+		if administrators := teamUserUUIDs(team.Embedded.Administrators); len(administrators) > 0 {
+			body.SetAttributeRaw("administrators", e.references(administrators))
+		}
+	}
+
+	return nil
+}
+
+func (e *exporter) environments(ctx context.Context) error {
+	for env, err := range e.c.ListEnvironmentsWithContext(ctx, client.ListOptions{}) {
+		if err != nil {
+			return fmt.Errorf("unable to list environments: %w", err)
+		}
+
+		body := e.resource("environments.tf", "pact_environment", env.Name, env.UUID)
+		body.SetAttributeValue("name", cty.StringVal(env.Name))
+		if env.DisplayName != "" {
+			body.SetAttributeValue("display_name", cty.StringVal(env.DisplayName))
+		}
+		body.SetAttributeValue("production", cty.BoolVal(env.Production))
+		if teams := teamsFromEnvironment(env); len(teams) > 0 {
+			body.SetAttributeRaw("teams", e.references(teams))
+		}
+	}
+
+	return nil
+}
+
+// secrets are exported without their values, which can't be read from the broker. Each value is a sensitive variable
+func (e *exporter) secrets(ctx context.Context) error {
+	for s, err := range e.c.ListSecretsWithContext(ctx, client.ListOptions{}) {
+		if err != nil {
+			return fmt.Errorf("unable to list secrets: %w", err)
+		}
+
+		name := e.name("pact_secret", s.Name)
+		body := e.resourceNamed("secrets.tf", "pact_secret", name, s.UUID)
+		body.SetAttributeValue("name", cty.StringVal(s.Name))
+		body.SetAttributeValue("description", cty.StringVal(s.Description))
+		body.SetAttributeTraversal("value", e.variable("secret_"+name, "The value of the "+s.Name+" secret"))
+		if s.TeamUUID != "" {
+			body.SetAttributeRaw("team", e.reference(s.TeamUUID))
+		}
+	}
+
+	return nil
+}
+
+func (e *exporter) webhooks(ctx context.Context) error {
+	for w, err := range e.c.ListWebhooksWithContext(ctx, client.ListOptions{}) {
+		if err != nil {
+			return fmt.Errorf("unable to list webhooks: %w", err)
+		}
+
+		label := w.Description
+		if label == "" {
+			label = strings.TrimSpace(pacticipantName(w.Consumer) + " " + pacticipantName(w.Provider) + " webhook")
+		}
+		name := e.name("pact_webhook", label)
+
+		body := e.resourceNamed("webhooks.tf", "pact_webhook", name, w.ID)
+		if w.Description != "" {
+			body.SetAttributeValue("description", cty.StringVal(w.Description))
+		}
+		if w.Consumer != nil && w.Consumer.Name != "" {
+			body.SetAttributeValue("webhook_consumer", cty.MapVal(map[string]cty.Value{"name": cty.StringVal(w.Consumer.Name)}))
+		}
+		if w.Provider != nil && w.Provider.Name != "" {
+			body.SetAttributeValue("webhook_provider", cty.MapVal(map[string]cty.Value{"name": cty.StringVal(w.Provider.Name)}))
+		}
+		if len(w.Events) > 0 {
+			body.SetAttributeValue("events", stringList(flattenEvents(w)))
+		}
+		if !w.Enabled {
+			body.SetAttributeValue("enabled", cty.False)
+		}
+		if w.TeamUUID != "" {
+			body.SetAttributeRaw("team", e.reference(w.TeamUUID))
+		}
+
+		request := body.AppendNewBlock("request", nil).Body()
+		request.SetAttributeValue("url", cty.StringVal(w.Request.URL))
+		request.SetAttributeValue("method", cty.StringVal(w.Request.Method))
+		if w.Request.Username != "" {
+			request.SetAttributeValue("username", cty.StringVal(w.Request.Username))
+		}
+		// The broker obscures passwords, so each is a sensitive variable
+		if w.Request.Password != "" {
+			request.SetAttributeTraversal("password", e.variable("webhook_"+name+"_password", "The password for the "+label+" webhook"))
+		}
+		if len(w.Request.Headers) > 0 {
+			request.SetAttributeRaw("headers", e.headers(name, label, w.Request.Headers))
+		}
+		if w.Request.Body != nil {
+			request.SetAttributeRaw("body", bodyTokens(w.Request.Body))
+		}
+	}
+
+	return nil
+}
+
+// headers returns the tokens for a webhook's headers. Headers that carry credentials (e.g. Authorization) may be
+// obscured by the broker, and must never be exported, so as for passwords each is a sensitive variable
+func (e *exporter) headers(name, label string, headers map[string]string) hclwrite.Tokens {
+	keys := make([]string, 0, len(headers))
+	for k := range headers {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	attrs := make([]hclwrite.ObjectAttrTokens, len(keys))
+	for i, k := range keys {
+		value := hclwrite.TokensForValue(cty.StringVal(headers[k]))
+		if client.IsSensitiveHeader(k) {
+			header := strings.Trim(invalidNameChars.ReplaceAllString(strings.ToLower(k), "_"), "_")
+			value = hclwrite.TokensForTraversal(e.variable("webhook_"+name+"_"+header, fmt.Sprintf("The %s header for the %s webhook", k, label)))
+		}
+		attrs[i] = hclwrite.ObjectAttrTokens{Name: hclwrite.TokensForValue(cty.StringVal(k)), Value: value}
+	}
+
+	return hclwrite.TokensForObject(attrs)
+}
+
+// resource appends an import block and an empty resource block, named after the given label, to the file
+func (e *exporter) resource(file, resourceType, label, uuid string) *hclwrite.Body {
+	return e.resourceNamed(file, resourceType, e.name(resourceType, label), uuid)
+}
+
+func (e *exporter) resourceNamed(file, resourceType, name, uuid string) *hclwrite.Body {
+	address := hcl.Traversal{hcl.TraverseRoot{Name: resourceType}, hcl.TraverseAttr{Name: name}}
+	e.addresses[uuid] = address
+
+	body := e.file(file)
+	if len(body.Blocks()) > 0 {
+		body.AppendNewline()
+	}
+	imp := body.AppendNewBlock("import", nil).Body()
+	imp.SetAttributeTraversal("to", address)
+	imp.SetAttributeValue("id", cty.StringVal(uuid))
+	body.AppendNewline()
+
+	return body.AppendNewBlock("resource", []string{resourceType, name}).Body()
+}
+
+// variable declares a sensitive variable for a value that can't be exported, returning a reference to it
+func (e *exporter) variable(name, description string) hcl.Traversal {
+	body := e.file("variables.tf")
+	if len(body.Blocks()) > 0 {
+		body.AppendNewline()
+	}
+	v := body.AppendNewBlock("variable", []string{name}).Body()
+	v.SetAttributeValue("description", cty.StringVal(description))
+	v.SetAttributeTraversal("type", hcl.Traversal{hcl.TraverseRoot{Name: "string"}})
+	v.SetAttributeValue("sensitive", cty.True)
+
+	return hcl.Traversal{hcl.TraverseRoot{Name: "var"}, hcl.TraverseAttr{Name: name}}
+}
+
+func (e *exporter) file(name string) *hclwrite.Body {
+	if e.files[name] == nil {
+		e.files[name] = hclwrite.NewEmptyFile()
+	}
+
+	return e.files[name].Body()
+}
+
+var invalidNameChars = regexp.MustCompile(`[^a-z0-9_]+`)
+
+// name returns a unique resource name for the label, e.g. "Product API" becomes product_api
+func (e *exporter) name(resourceType, label string) string {
+	base := strings.Trim(invalidNameChars.ReplaceAllString(strings.ToLower(label), "_"), "_")
+	if base == "" {
+		base = strings.TrimPrefix(resourceType, "pact_")
+	}
+	if base[0] >= '0' && base[0] <= '9' {
+		base = "_" + base
+	}
+
+	if e.names[resourceType] == nil {
+		e.names[resourceType] = map[string]bool{}
+	}
+	name := base
+	for i := 2; e.names[resourceType][name]; i++ {
+		name = fmt.Sprintf("%s_%d", base, i)
+	}
+	e.names[resourceType][name] = true
+
+	return name
+}
+
+// reference refers to the uuid attribute of an exported resource, or is the UUID if it wasn't exported
+func (e *exporter) reference(uuid string) hclwrite.Tokens {
+	address, ok := e.addresses[uuid]
+	if !ok {
+		return hclwrite.TokensForValue(cty.StringVal(uuid))
+	}
+
+	return hclwrite.TokensForTraversal(append(address, hcl.TraverseAttr{Name: "uuid"}))
+}
+
+// references is a list of references, sorted for stable output
+func (e *exporter) references(uuids []string) hclwrite.Tokens {
+	elems := make([]hclwrite.Tokens, len(uuids))
+	for i, uuid := range uuids {
+		elems[i] = e.reference(uuid)
+	}
+	sort.Slice(elems, func(i, j int) bool {
+		return string(elems[i].Bytes()) < string(elems[j].Bytes())
+	})
+
+	return hclwrite.TokensForTuple(elems)
+}
+
+func stringList(values []string) cty.Value {
+	if len(values) == 0 {
+		return cty.ListValEmpty(cty.String)
+	}
+
+	sorted := append([]string(nil), values...)
+	sort.Strings(sorted)
+	list := make([]cty.Value, len(sorted))
+	for i, v := range sorted {
+		list[i] = cty.StringVal(v)
+	}
+
+	return cty.ListVal(list)
+}
+
+func teamUserUUIDs(users []broker.TeamUser) []string {
+	uuids := make([]string, len(users))
+	for i, u := range users {
+		uuids[i] = u.UUID
+	}
+
+	return uuids
+}
+
+// bodyTokens writes a JSON body with jsonencode, so that it is readable, or a string otherwise. Template sequences in
+// webhook placeholders (e.g. ${pactbroker.consumerName}) are escaped
+func bodyTokens(body interface{}) hclwrite.Tokens {
+	if s, ok := body.(string); ok {
+		return hclwrite.TokensForValue(cty.StringVal(s))
+	}
+
+	raw, err := json.Marshal(body)
+	if err != nil {
+		return hclwrite.TokensForValue(cty.StringVal(fmt.Sprint(body)))
+	}
+	t, err := ctyjson.ImpliedType(raw)
+	if err != nil {
+		return hclwrite.TokensForValue(cty.StringVal(string(raw)))
+	}
+	v, err := ctyjson.Unmarshal(raw, t)
+	if err != nil {
+		return hclwrite.TokensForValue(cty.StringVal(string(raw)))
+	}
+
+	return hclwrite.TokensForFunctionCall("jsonencode", hclwrite.TokensForValue(v))
+}
@@ -0,0 +1,126 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	"github.com/pactflow/terraform/broker"
+	"github.com/pactflow/terraform/client/clienttest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExportConfiguration(t *testing.T) {
+	f := clienttest.NewFake()
+	role, _ := f.CreateRole(broker.Role{Name: "CI Maintainer", Permissions: []broker.Permission{{Scope: "contract_data:manage:*"}}})
+	jane, _ := f.CreateUser(broker.User{Name: "Jane", Email: "jane.doe@example.com", Active: true})
+	assert.NoError(t, f.SetUserRoles(jane.UUID, broker.SetUserRolesRequest{Roles: []string{role.UUID}}))
+	team, _ := f.CreateTeam(broker.TeamCreateOrUpdateRequest{Name: "Platform", PacticipantNames: []string{"Product API"}})
+	_, err := f.UpdateTeamAssignments(broker.TeamsAssignmentRequest{UUID: team.UUID, Users: []string{jane.UUID}})
+	assert.NoError(t, err)
+	_, _ = f.CreateEnvironment(broker.EnvironmentCreateOrUpdateRequest{Name: "production", Production: true, Teams: []string{team.UUID}})
+	_, _ = f.CreateSecret(broker.Secret{Name: "ciToken", Description: "CI token", Value: "s3cr3t", TeamUUID: team.UUID})
+	_, _ = f.CreateWebhook(broker.Webhook{
+		Description: "Trigger build",
+		Enabled:     true,
+		TeamUUID:    team.UUID,
+		Consumer:    &broker.Pacticipant{Name: "Product API"},
+		Events:      []broker.WebhookEvent{{Name: "contract_content_changed"}},
+		Request: broker.Request{
+			Method:   "POST",
+			URL:      "https://ci.example.com/build",
+			Username: "ci",
+			Password: "hunter2",
+			Headers:  map[string]string{"Authorization": "Bearer abcd1234", "Content-Type": "application/json"},
+			Body:     map[string]interface{}{"consumer": "${pactbroker.consumerName}"},
+		},
+	})
+
+	files, err := exportConfiguration(context.Background(), f)
+	assert.NoError(t, err)
+
+	t.Run("writes valid HCL", func(t *testing.T) {
+		assert.ElementsMatch(t, []string{"roles.tf", "users.tf", "teams.tf", "environments.tf", "secrets.tf", "webhooks.tf", "variables.tf"}, keys(files))
+		for name, content := range files {
+			_, diags := hclsyntax.ParseConfig(content, name, hcl.InitialPos)
+			assert.False(t, diags.HasErrors(), "%s: %s", name, diags)
+		}
+	})
+
+	t.Run("imports each resource by UUID", func(t *testing.T) {
+		assert.Contains(t, string(files["teams.tf"]), `import {
+  to = pact_team.platform
+  id = "`+team.UUID+`"
+}
+
+resource "pact_team" "platform" {`)
+	})
+
+	t.Run("refers to exported resources", func(t *testing.T) {
+		assert.Contains(t, string(files["users.tf"]), `resource "pact_user" "jane_doe" {`)
+		assert.Contains(t, string(files["users.tf"]), `roles  = [pact_role.ci_maintainer.uuid]`)
+		assert.Contains(t, string(files["teams.tf"]), `users        = [pact_user.jane_doe.uuid]`)
+		assert.Contains(t, string(files["environments.tf"]), `teams      = [pact_team.platform.uuid]`)
+		assert.Contains(t, string(files["webhooks.tf"]), `team   = pact_team.platform.uuid`)
+	})
+
+	t.Run("never exports secret values", func(t *testing.T) {
+		for name, content := range files {
+			assert.NotContains(t, string(content), "s3cr3t", name)
+			assert.NotContains(t, string(content), "hunter2", name)
+			assert.NotContains(t, string(content), "abcd1234", name)
+		}
+		assert.Contains(t, string(files["secrets.tf"]), `value       = var.secret_citoken`)
+		assert.Contains(t, string(files["webhooks.tf"]), `password = var.webhook_trigger_build_password`)
+		assert.Contains(t, string(files["variables.tf"]), `variable "secret_citoken" {`)
+		assert.Contains(t, string(files["webhooks.tf"]), `"Authorization" = var.webhook_trigger_build_authorization`)
+		assert.Contains(t, string(files["webhooks.tf"]), `"Content-Type"  = "application/json"`)
+		assert.Contains(t, string(files["variables.tf"]), `variable "webhook_trigger_build_authorization" {`)
+	})
+
+	t.Run("escapes webhook placeholders", func(t *testing.T) {
+		assert.Contains(t, string(files["webhooks.tf"]), `"$${pactbroker.consumerName}"`)
+	})
+}
+
+func TestExporterName(t *testing.T) {
+	e := &exporter{names: map[string]map[string]bool{}}
+
+	assert.Equal(t, "product_api", e.name("pact_team", "Product API"))
+	assert.Equal(t, "product_api_2", e.name("pact_team", "product-api"))
+	assert.Equal(t, "product_api", e.name("pact_role", "Product API"))
+	assert.Equal(t, "_2024_release", e.name("pact_team", "2024 release"))
+	assert.Equal(t, "team", e.name("pact_team", "!!!"))
+}
+
+func TestExporterReferences(t *testing.T) {
+	e := &exporter{addresses: map[string]hcl.Traversal{
+		"1234": {hcl.TraverseRoot{Name: "pact_team"}, hcl.TraverseAttr{Name: "platform"}},
+	}}
+
+	assert.Equal(t, `["5678", pact_team.platform.uuid]`, string(hclwrite.Format(e.references([]string{"1234", "5678"}).Bytes())))
+}
+
+func TestWriteFiles(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "tenant")
+
+	assert.NoError(t, writeFiles(dir, map[string][]byte{"teams.tf": []byte("# teams\n")}))
+	content, err := os.ReadFile(filepath.Join(dir, "teams.tf"))
+	assert.NoError(t, err)
+	assert.Equal(t, "# teams\n", string(content))
+
+	assert.ErrorContains(t, writeFiles(dir, map[string][]byte{"teams.tf": nil}), "teams.tf already exists")
+}
+
+func keys[V any](m map[string]V) []string {
+	res := make([]string, 0, len(m))
+	for k := range m {
+		res = append(res, k)
+	}
+
+	return res
+}
@@ -0,0 +1,200 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/function"
+	"github.com/pactflow/terraform/client"
+)
+
+// webhookPlaceholders are the parameters the broker substitutes into webhook requests.
+// See https://docs.pact.io/pact_broker/webhooks#dynamic-variable-substitution
+var webhookPlaceholders = []string{
+	"pactUrl",
+	"verificationResultUrl",
+	"consumerName",
+	"consumerVersionNumber",
+	"consumerVersionTags",
+	"consumerVersionBranch",
+	"consumerLabels",
+	"providerName",
+	"providerVersionNumber",
+	"providerVersionTags",
+	"providerVersionBranch",
+	"providerVersionDescriptions",
+	"providerLabels",
+	"githubVerificationStatus",
+	"bitbucketVerificationStatus",
+	"azureDevOpsVerificationStatus",
+	"gitlabVerificationStatus",
+	"eventName",
+	"currentlyDeployedProviderVersionNumber",
+	"buildUrl",
+}
+
+// pacticipantURLFunction is provider::pact::pacticipant_url
+type pacticipantURLFunction struct{}
+
+func newPacticipantURLFunction() function.Function {
+	return pacticipantURLFunction{}
+}
+
+func (f pacticipantURLFunction) Metadata(ctx context.Context, req function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = "pacticipant_url"
+}
+
+func (f pacticipantURLFunction) Definition(ctx context.Context, req function.DefinitionRequest, resp *function.DefinitionResponse) {
+	resp.Definition = function.Definition{
+		Summary:     "The API URL of a pacticipant",
+		Description: "Returns the URL of the named pacticipant's resource in the broker API, escaping the name in the same way as the provider does for its own requests",
+		Parameters: []function.Parameter{
+			function.StringParameter{Name: "base_url", Description: "The base URL of the broker, including any path it is hosted under"},
+			function.StringParameter{Name: "name", Description: "The name of the pacticipant"},
+		},
+		Return: function.StringReturn{},
+	}
+}
+
+func (f pacticipantURLFunction) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+	var baseURL, name string
+	resp.Error = req.Arguments.Get(ctx, &baseURL, &name)
+	if resp.Error != nil {
+		return
+	}
+
+	base, err := parseBaseURL(baseURL)
+	if err != nil {
+		resp.Error = function.NewArgumentFuncError(0, err.Error())
+		return
+	}
+
+	resp.Error = resp.Result.Set(ctx, base+client.PacticipantPath(name))
+}
+
+// badgeURLFunction is provider::pact::badge_url
+type badgeURLFunction struct{}
+
+func newBadgeURLFunction() function.Function {
+	return badgeURLFunction{}
+}
+
+func (f badgeURLFunction) Metadata(ctx context.Context, req function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = "badge_url"
+}
+
+func (f badgeURLFunction) Definition(ctx context.Context, req function.DefinitionRequest, resp *function.DefinitionResponse) {
+	resp.Definition = function.Definition{
+		Summary:     "The URL of a verification status badge",
+		Description: "Returns the URL of the badge showing the verification status of the latest pact between a consumer and provider, e.g. for a README",
+		Parameters: []function.Parameter{
+			function.StringParameter{Name: "base_url", Description: "The base URL of the broker, including any path it is hosted under"},
+			function.StringParameter{Name: "provider", Description: "The name of the provider"},
+			function.StringParameter{Name: "consumer", Description: "The name of the consumer"},
+		},
+		Return: function.StringReturn{},
+	}
+}
+
+func (f badgeURLFunction) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+	var baseURL, provider, consumer string
+	resp.Error = req.Arguments.Get(ctx, &baseURL, &provider, &consumer)
+	if resp.Error != nil {
+		return
+	}
+
+	base, err := parseBaseURL(baseURL)
+	if err != nil {
+		resp.Error = function.NewArgumentFuncError(0, err.Error())
+		return
+	}
+
+	resp.Error = resp.Result.Set(ctx, base+client.BadgePath(provider, consumer))
+}
+
+// escapeNameFunction is provider::pact::escape_name
+type escapeNameFunction struct{}
+
+func newEscapeNameFunction() function.Function {
+	return escapeNameFunction{}
+}
+
+func (f escapeNameFunction) Metadata(ctx context.Context, req function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = "escape_name"
+}
+
+func (f escapeNameFunction) Definition(ctx context.Context, req function.DefinitionRequest, resp *function.DefinitionResponse) {
+	resp.Definition = function.Definition{
+		Summary:     "Escapes a name for use in a broker URL",
+		Description: "Escapes a name (e.g. of a pacticipant or environment) for use as a segment of a broker URL, in the same way as the provider does for its own requests",
+		Parameters: []function.Parameter{
+			function.StringParameter{Name: "name", Description: "The name to escape"},
+		},
+		Return: function.StringReturn{},
+	}
+}
+
+func (f escapeNameFunction) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+	var name string
+	resp.Error = req.Arguments.Get(ctx, &name)
+	if resp.Error != nil {
+		return
+	}
+
+	resp.Error = resp.Result.Set(ctx, client.EscapeName(name))
+}
+
+// webhookPlaceholderFunction is provider::pact::webhook_placeholder
+type webhookPlaceholderFunction struct{}
+
+func newWebhookPlaceholderFunction() function.Function {
+	return webhookPlaceholderFunction{}
+}
+
+func (f webhookPlaceholderFunction) Metadata(ctx context.Context, req function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = "webhook_placeholder"
+}
+
+func (f webhookPlaceholderFunction) Definition(ctx context.Context, req function.DefinitionRequest, resp *function.DefinitionResponse) {
+	resp.Definition = function.Definition{
+		Summary: "A placeholder for a webhook request",
+		Description: "Returns a placeholder (e.g. ${pactbroker.pactUrl}) that the broker substitutes when sending a webhook request, " +
+			"without needing to escape it from Terraform's own interpolation. The name must be one of: " + strings.Join(webhookPlaceholders, ", "),
+		Parameters: []function.Parameter{
+			function.StringParameter{Name: "name", Description: "The name of the parameter, e.g. pactUrl"},
+		},
+		Return: function.StringReturn{},
+	}
+}
+
+func (f webhookPlaceholderFunction) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+	var name string
+	resp.Error = req.Arguments.Get(ctx, &name)
+	if resp.Error != nil {
+		return
+	}
+
+	name = strings.TrimPrefix(name, "pactbroker.")
+	if !slices.Contains(webhookPlaceholders, name) {
+		resp.Error = function.NewArgumentFuncError(0, fmt.Sprintf("unknown webhook placeholder %q, must be one of: %s", name, strings.Join(webhookPlaceholders, ", ")))
+		return
+	}
+
+	resp.Error = resp.Result.Set(ctx, fmt.Sprintf("${pactbroker.%s}", name))
+}
+
+// parseBaseURL validates the base URL of a broker, removing any trailing slash in the same way as the provider's host
+func parseBaseURL(baseURL string) (string, error) {
+	u, err := url.Parse(baseURL)
+	if err != nil {
+		return "", fmt.Errorf("invalid base URL: %w", err)
+	}
+	if u.Scheme == "" || u.Host == "" {
+		return "", fmt.Errorf("invalid base URL %q, expected an absolute URL such as https://mybroker.pactflow.io", baseURL)
+	}
+
+	return strings.TrimRight(baseURL, "/"), nil
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-go/tfprotov6"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+	"github.com/pactflow/terraform/client"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFunctions(t *testing.T) {
+	call := func(t *testing.T, name string, args ...string) (string, *tfprotov6.FunctionError) {
+		server, err := providerServer(context.Background())
+		assert.NoError(t, err)
+
+		arguments := make([]*tfprotov6.DynamicValue, len(args))
+		for i, arg := range args {
+			v, err := tfprotov6.NewDynamicValue(tftypes.String, tftypes.NewValue(tftypes.String, arg))
+			assert.NoError(t, err)
+			arguments[i] = &v
+		}
+
+		res, err := server().CallFunction(context.Background(), &tfprotov6.CallFunctionRequest{Name: name, Arguments: arguments})
+		assert.NoError(t, err)
+		if res.Error != nil {
+			return "", res.Error
+		}
+
+		value, err := res.Result.Unmarshal(tftypes.String)
+		assert.NoError(t, err)
+		var result string
+		assert.NoError(t, value.As(&result))
+
+		return result, nil
+	}
+
+	t.Run("pacticipant_url escapes the name", func(t *testing.T) {
+		res, err := call(t, "pacticipant_url", "https://tools.example.com/pact-broker/", "my app/v1")
+
+		assert.Nil(t, err)
+		assert.Equal(t, "https://tools.example.com/pact-broker/pacticipants/my%20app%2Fv1", res)
+	})
+
+	t.Run("pacticipant_url rejects a relative base URL", func(t *testing.T) {
+		_, err := call(t, "pacticipant_url", "broker.example.com", "my-app")
+
+		assert.NotNil(t, err)
+		assert.Equal(t, int64(0), *err.FunctionArgument)
+	})
+
+	t.Run("badge_url", func(t *testing.T) {
+		res, err := call(t, "badge_url", "https://broker.example.com", "Product Service", "Admin Service")
+
+		assert.Nil(t, err)
+		assert.Equal(t, "https://broker.example.com/pacts/provider/Product%20Service/consumer/Admin%20Service/latest/badge", res)
+	})
+
+	t.Run("escape_name", func(t *testing.T) {
+		res, err := call(t, "escape_name", "feat/new thing")
+
+		assert.Nil(t, err)
+		assert.Equal(t, "feat%2Fnew%20thing", res)
+	})
+
+	t.Run("webhook_placeholder", func(t *testing.T) {
+		res, err := call(t, "webhook_placeholder", "pactUrl")
+		assert.Nil(t, err)
+		assert.Equal(t, "${pactbroker.pactUrl}", res)
+
+		res, err = call(t, "webhook_placeholder", "pactbroker.consumerName")
+		assert.Nil(t, err)
+		assert.Equal(t, "${pactbroker.consumerName}", res)
+
+		_, err = call(t, "webhook_placeholder", "pactURL")
+		assert.NotNil(t, err)
+	})
+}
+
+func TestProviderServer(t *testing.T) {
+	t.Run("the SDK and framework providers have the same configuration schema", func(t *testing.T) {
+		for _, host := range []string{"", "http://localhost:9292"} {
+			t.Setenv(client.EnvBaseURL, host)
+
+			server, err := providerServer(context.Background())
+			assert.NoError(t, err)
+
+			res, err := server().GetProviderSchema(context.Background(), &tfprotov6.GetProviderSchemaRequest{})
+			assert.NoError(t, err)
+			assert.Empty(t, res.Diagnostics)
+			assert.Contains(t, res.Functions, "pacticipant_url")
+			assert.Contains(t, res.ResourceSchemas, "pact_team")
+		}
+	})
+}
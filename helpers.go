@@ -1,11 +1,106 @@
 package main
 
 import (
+	"context"
+	"errors"
+	"log"
 	"sort"
+	"time"
 
-	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/pactflow/terraform/client"
 )
 
+// defaultTimeout bounds each resource operation, unless overridden with a timeouts {} block
+const defaultTimeout = 5 * time.Minute
+
+// contextCRUDFunc is a resource operation that may be cancelled or time out via the given context
+type contextCRUDFunc func(context.Context, *schema.ResourceData, interface{}) diag.Diagnostics
+
+// stoppable adapts a contextCRUDFunc to the SDK. The SDK bounds the context by the configured timeout for the
+// operation (e.g. schema.TimeoutCreate), and it is also cancelled when Terraform is interrupted. The identity of
+// resources that have one is kept in sync with their ID
+func stoppable(f contextCRUDFunc) func(context.Context, *schema.ResourceData, interface{}) diag.Diagnostics {
+	return func(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+		ctx, cancel := context.WithCancel(ctx)
+		defer cancel()
+		stop := context.AfterFunc(providerContext(meta), cancel)
+		defer stop()
+
+		diags := f(ctx, d, meta)
+		if !diags.HasError() {
+			setIdentity(d)
+		}
+
+		return diags
+	}
+}
+
+// resourceIdentity identifies a resource by its ID, so that it can be imported by identity and listed by
+// terraform query (see list_resources.go)
+func resourceIdentity() *schema.ResourceIdentity {
+	return &schema.ResourceIdentity{
+		SchemaFunc: func() map[string]*schema.Schema {
+			return map[string]*schema.Schema{
+				"id": {
+					Type:              schema.TypeString,
+					RequiredForImport: true,
+					Description:       "The ID of the resource, as used to import it",
+				},
+			}
+		},
+	}
+}
+
+// setIdentity sets the identity of a resource with a resourceIdentity to its ID. Resources without an identity,
+// and resources that have been removed, are left alone
+func setIdentity(d *schema.ResourceData) {
+	if d.Id() == "" {
+		return
+	}
+
+	identity, err := d.Identity()
+	if err != nil {
+		return
+	}
+	if err := identity.Set("id", d.Id()); err != nil {
+		log.Println("[WARN] unable to set the identity of", d.Id(), err)
+	}
+}
+
+// providerContext returns the context that is cancelled when the provider is stopped
+func providerContext(meta interface{}) context.Context {
+	if c, ok := meta.(client.BrokerAPI); ok {
+		return c.BaseContext()
+	}
+
+	return context.Background()
+}
+
+// removeIfNotFound removes a resource from the state if it has been deleted outside of Terraform (e.g. via the UI),
+// so that it is re-created on the next apply. Returns true if the resource was removed
+func removeIfNotFound(d *schema.ResourceData, resource string, err error) bool {
+	if !errors.Is(err, client.ErrNotFound) {
+		return false
+	}
+
+	log.Printf("[WARN] %s %s no longer exists in the broker, removing it from state\n", resource, d.Id())
+	d.SetId("")
+
+	return true
+}
+
+// defaultTimeouts configures the timeouts {} block for a resource supporting all CRUD operations
+func defaultTimeouts() *schema.ResourceTimeout {
+	return &schema.ResourceTimeout{
+		Create: schema.DefaultTimeout(defaultTimeout),
+		Read:   schema.DefaultTimeout(defaultTimeout),
+		Update: schema.DefaultTimeout(defaultTimeout),
+		Delete: schema.DefaultTimeout(defaultTimeout),
+	}
+}
+
 func arrayInterfaceToArrayString(raw []interface{}) []string {
 	items := make([]string, len(raw))
 	if len(raw) > 0 {
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/pactflow/terraform/broker"
+	"github.com/pactflow/terraform/client"
+	"github.com/pactflow/terraform/client/clienttest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRemoveIfNotFound(t *testing.T) {
+	t.Run("removes the resource from state when it no longer exists", func(t *testing.T) {
+		d := schema.TestResourceDataRaw(t, team().Schema, map[string]interface{}{"name": "Futurama"})
+		d.SetId("a7ee9f85-d7e5-4bbb-a8b4-2e8c1c35c7b6")
+
+		_, err := clienttest.NewFake().ReadTeam(broker.Team{UUID: d.Id()})
+		assert.EqualError(t, err, "team 'a7ee9f85-d7e5-4bbb-a8b4-2e8c1c35c7b6' not found")
+
+		assert.True(t, removeIfNotFound(d, "team", err))
+		assert.Equal(t, "", d.Id())
+	})
+
+	t.Run("keeps the resource for any other error", func(t *testing.T) {
+		d := schema.TestResourceDataRaw(t, team().Schema, map[string]interface{}{"name": "Futurama"})
+		d.SetId("a7ee9f85-d7e5-4bbb-a8b4-2e8c1c35c7b6")
+
+		assert.False(t, removeIfNotFound(d, "team", client.ErrSystemUnavailable))
+		assert.False(t, removeIfNotFound(d, "team", errors.New("connection reset")))
+		assert.False(t, removeIfNotFound(d, "team", nil))
+		assert.Equal(t, "a7ee9f85-d7e5-4bbb-a8b4-2e8c1c35c7b6", d.Id())
+	})
+}
+
+func TestSetIdentity(t *testing.T) {
+	t.Run("sets the identity to the ID", func(t *testing.T) {
+		d := team().TestResourceData()
+		d.SetId("a7ee9f85-d7e5-4bbb-a8b4-2e8c1c35c7b6")
+
+		setIdentity(d)
+
+		identity, err := d.Identity()
+		assert.NoError(t, err)
+		assert.Equal(t, "a7ee9f85-d7e5-4bbb-a8b4-2e8c1c35c7b6", identity.Get("id"))
+	})
+
+	t.Run("ignores resources without an identity", func(t *testing.T) {
+		d := token().TestResourceData()
+		d.SetId("a7ee9f85-d7e5-4bbb-a8b4-2e8c1c35c7b6")
+
+		assert.NotPanics(t, func() { setIdentity(d) })
+	})
+}
@@ -0,0 +1,205 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"iter"
+	"log"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/pactflow/terraform/broker"
+	"github.com/pactflow/terraform/client"
+)
+
+// importCandidate is a resource matching the natural keys given to import
+type importCandidate struct {
+	id    string
+	label string
+}
+
+// importFinder returns the resources matching the given natural keys
+type importFinder func(ctx context.Context, c client.BrokerAPI, keys map[string]string) ([]importCandidate, error)
+
+// naturalKeyImporter imports a resource by its ID, as before, or by natural keys in the form key=value[,key=value...]
+// (e.g. "name=Platform"). Exactly one resource must match, otherwise the error lists the candidates. Resources may
+// also be imported by their identity (see resourceIdentity)
+func naturalKeyImporter(resource string, find importFinder, allowed ...string) *schema.ResourceImporter {
+	return &schema.ResourceImporter{
+		StateContext: func(ctx context.Context, d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
+			if d.Id() == "" {
+				return schema.ImportStatePassthroughWithIdentity("id")(ctx, d, meta)
+			}
+
+			keys, ok, err := parseImportID(d.Id(), allowed)
+			if err != nil {
+				return nil, err
+			}
+			if !ok {
+				return []*schema.ResourceData{d}, nil
+			}
+
+			c, ok := meta.(client.BrokerAPI)
+			if !ok {
+				return nil, fmt.Errorf("unable to import %s by %s: the provider is not configured", resource, d.Id())
+			}
+
+			log.Println("[DEBUG] finding", resource, "to import by", keys)
+			candidates, err := find(ctx, c, keys)
+			if err != nil {
+				return nil, fmt.Errorf("unable to find %s matching %s: %w", resource, d.Id(), err)
+			}
+
+			switch len(candidates) {
+			case 0:
+				return nil, fmt.Errorf("no %s matches %s", resource, d.Id())
+			case 1:
+				d.SetId(candidates[0].id)
+				return []*schema.ResourceData{d}, nil
+			}
+
+			list := new(strings.Builder)
+			for _, candidate := range candidates {
+				fmt.Fprintf(list, "\n  %s (%s)", candidate.id, candidate.label)
+			}
+
+			return nil, fmt.Errorf("%d resources of type %s match %s, import one by ID instead:%s", len(candidates), resource, d.Id(), list)
+		},
+	}
+}
+
+// parseImportID parses natural keys from an import ID, returning false if the ID isn't in the form key=value. A comma
+// only separates keys if it is followed by one of the allowed keys, so that values (e.g. descriptions) may contain commas
+func parseImportID(id string, allowed []string) (map[string]string, bool, error) {
+	key, _, found := strings.Cut(id, "=")
+	if !found || strings.ContainsAny(key, " ,") {
+		return nil, false, nil
+	}
+
+	keys := map[string]string{}
+	var current string
+	for i, part := range strings.Split(id, ",") {
+		k, v, found := strings.Cut(part, "=")
+		switch {
+		case found && slices.Contains(allowed, k):
+			if _, ok := keys[k]; ok {
+				return nil, false, fmt.Errorf("%s is given more than once in the import ID %q", k, id)
+			}
+			current = k
+			keys[k] = v
+		case i == 0:
+			return nil, false, fmt.Errorf("unable to import by %q, which is not one of %s", k, strings.Join(allowed, ", "))
+		default:
+			keys[current] += "," + part
+		}
+	}
+
+	return keys, true, nil
+}
+
+// collect returns the candidates for the items of a List method that are accepted by include
+func collect[T any](seq iter.Seq2[T, error], include func(T) bool, candidate func(T) importCandidate) ([]importCandidate, error) {
+	var candidates []importCandidate
+	for item, err := range seq {
+		if err != nil {
+			return nil, err
+		}
+		if include(item) {
+			candidates = append(candidates, candidate(item))
+		}
+	}
+
+	return candidates, nil
+}
+
+// includeAll accepts every item, for List methods that already filter by the natural key
+func includeAll[T any](T) bool { return true }
+
+func findTeams(ctx context.Context, c client.BrokerAPI, keys map[string]string) ([]importCandidate, error) {
+	return collect(c.ListTeamsWithContext(ctx, client.ListOptions{Name: keys["name"]}), includeAll, func(t broker.Team) importCandidate {
+		return importCandidate{t.UUID, t.Name}
+	})
+}
+
+func findRoles(ctx context.Context, c client.BrokerAPI, keys map[string]string) ([]importCandidate, error) {
+	return collect(c.ListRolesWithContext(ctx, client.ListOptions{Name: keys["name"]}), includeAll, func(r broker.Role) importCandidate {
+		return importCandidate{r.UUID, r.Name}
+	})
+}
+
+func findEnvironments(ctx context.Context, c client.BrokerAPI, keys map[string]string) ([]importCandidate, error) {
+	return collect(c.ListEnvironmentsWithContext(ctx, client.ListOptions{Name: keys["name"]}), includeAll, func(e broker.Environment) importCandidate {
+		return importCandidate{e.UUID, e.Name}
+	})
+}
+
+// findUsers matches users by email (ignoring case), or system accounts (which may not have an email) by name
+func findUsers(ctx context.Context, c client.BrokerAPI, keys map[string]string) ([]importCandidate, error) {
+	email, emailGiven := keys["email"]
+
+	return collect(c.ListUsersWithContext(ctx, client.ListOptions{Name: keys["name"]}), func(u broker.User) bool {
+		return !emailGiven || strings.EqualFold(u.Email, email)
+	}, func(u broker.User) importCandidate {
+		return importCandidate{u.UUID, fmt.Sprintf("%s <%s>", u.Name, u.Email)}
+	})
+}
+
+// findSecrets matches secrets by name, and optionally the UUID of their team
+func findSecrets(ctx context.Context, c client.BrokerAPI, keys map[string]string) ([]importCandidate, error) {
+	team, teamGiven := keys["team"]
+
+	return collect(c.ListSecretsWithContext(ctx, client.ListOptions{Name: keys["name"], TeamUUID: team}), func(s broker.Secret) bool {
+		return !teamGiven || s.TeamUUID == team
+	}, func(s broker.Secret) importCandidate {
+		label := s.Name
+		if s.TeamUUID != "" {
+			label = fmt.Sprintf("%s, team %s", s.Name, s.TeamUUID)
+		}
+		return importCandidate{s.UUID, label}
+	})
+}
+
+// findWebhooks matches webhooks by description, consumer, provider, team and events (separated by "+"), as
+// webhooks don't have names
+func findWebhooks(ctx context.Context, c client.BrokerAPI, keys map[string]string) ([]importCandidate, error) {
+	var events []string
+	if v, ok := keys["events"]; ok {
+		events = strings.Split(v, "+")
+		sort.Strings(events)
+	}
+
+	return collect(c.ListWebhooksWithContext(ctx, client.ListOptions{TeamUUID: keys["team"]}), func(w broker.Webhook) bool {
+		if v, ok := keys["description"]; ok && w.Description != v {
+			return false
+		}
+		if v, ok := keys["consumer"]; ok && pacticipantName(w.Consumer) != v {
+			return false
+		}
+		if v, ok := keys["provider"]; ok && pacticipantName(w.Provider) != v {
+			return false
+		}
+		if events == nil {
+			return true
+		}
+
+		names := make([]string, len(w.Events))
+		for i, e := range w.Events {
+			names[i] = e.Name
+		}
+		sort.Strings(names)
+
+		return slices.Equal(events, names)
+	}, func(w broker.Webhook) importCandidate {
+		return importCandidate{w.ID, fmt.Sprintf("%q, consumer %q, provider %q", w.Description, pacticipantName(w.Consumer), pacticipantName(w.Provider))}
+	})
+}
+
+func pacticipantName(p *broker.Pacticipant) string {
+	if p == nil {
+		return ""
+	}
+
+	return p.Name
+}
@@ -0,0 +1,145 @@
+package main
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/pactflow/terraform/broker"
+	"github.com/pactflow/terraform/client/clienttest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseImportID(t *testing.T) {
+	allowed := []string{"description", "consumer", "provider"}
+
+	t.Run("returns false for an ID", func(t *testing.T) {
+		_, ok, err := parseImportID("c5a1fb2f-1bc9-4ec4-b1b5-6c4bb1a0a6a9", allowed)
+
+		assert.NoError(t, err)
+		assert.False(t, ok)
+	})
+
+	t.Run("parses keys, allowing commas in values", func(t *testing.T) {
+		keys, ok, err := parseImportID("description=Build, then deploy,consumer=Product API,provider=a=b", allowed)
+
+		assert.NoError(t, err)
+		assert.True(t, ok)
+		assert.Equal(t, map[string]string{"description": "Build, then deploy", "consumer": "Product API", "provider": "a=b"}, keys)
+	})
+
+	t.Run("rejects unknown keys", func(t *testing.T) {
+		_, _, err := parseImportID("name=Product API", allowed)
+
+		assert.ErrorContains(t, err, "description, consumer, provider")
+	})
+
+	t.Run("rejects repeated keys", func(t *testing.T) {
+		_, _, err := parseImportID("consumer=a,consumer=b", allowed)
+
+		assert.Error(t, err)
+	})
+}
+
+func TestNaturalKeyImporter(t *testing.T) {
+	importID := func(t *testing.T, f *clienttest.Fake, r *schema.Resource, id string) (string, error) {
+		d := r.TestResourceData()
+		d.SetId(id)
+
+		res, err := r.Importer.StateContext(context.Background(), d, f)
+		if err != nil {
+			return "", err
+		}
+		assert.Len(t, res, 1)
+
+		return res[0].Id(), nil
+	}
+
+	t.Run("imports by ID", func(t *testing.T) {
+		id, err := importID(t, clienttest.NewFake(), team(), "1234")
+
+		assert.NoError(t, err)
+		assert.Equal(t, "1234", id)
+	})
+
+	t.Run("imports by identity", func(t *testing.T) {
+		r := team()
+		d := r.TestResourceData()
+		identity, err := d.Identity()
+		assert.NoError(t, err)
+		assert.NoError(t, identity.Set("id", "1234"))
+
+		res, err := r.Importer.StateContext(context.Background(), d, clienttest.NewFake())
+
+		assert.NoError(t, err)
+		assert.Len(t, res, 1)
+		assert.Equal(t, "1234", res[0].Id())
+	})
+
+	t.Run("imports a team by name", func(t *testing.T) {
+		f := clienttest.NewFake()
+		platform, _ := f.CreateTeam(broker.TeamCreateOrUpdateRequest{Name: "Platform"})
+		_, _ = f.CreateTeam(broker.TeamCreateOrUpdateRequest{Name: "Payments"})
+
+		id, err := importID(t, f, team(), "name=Platform")
+
+		assert.NoError(t, err)
+		assert.Equal(t, platform.UUID, id)
+	})
+
+	t.Run("imports a user by email, ignoring case", func(t *testing.T) {
+		f := clienttest.NewFake()
+		jane, _ := f.CreateUser(broker.User{Name: "Jane", Email: "jane@example.com"})
+
+		id, err := importID(t, f, user(), "email=Jane@Example.com")
+
+		assert.NoError(t, err)
+		assert.Equal(t, jane.UUID, id)
+	})
+
+	t.Run("lists the candidates for an ambiguous match", func(t *testing.T) {
+		f := clienttest.NewFake()
+		a, _ := f.CreateTeam(broker.TeamCreateOrUpdateRequest{Name: "A"})
+		b, _ := f.CreateTeam(broker.TeamCreateOrUpdateRequest{Name: "B"})
+		_, _ = f.CreateSecret(broker.Secret{Name: "ci-token", TeamUUID: a.UUID})
+		_, _ = f.CreateSecret(broker.Secret{Name: "ci-token", TeamUUID: b.UUID})
+
+		_, err := importID(t, f, secret(), "name=ci-token")
+
+		assert.ErrorContains(t, err, "2 resources of type pact_secret match name=ci-token")
+		assert.ErrorContains(t, err, "team "+a.UUID)
+		assert.ErrorContains(t, err, "team "+b.UUID)
+
+		id, err := importID(t, f, secret(), "name=ci-token,team="+b.UUID)
+
+		assert.NoError(t, err)
+		assert.NotEmpty(t, id)
+	})
+
+	t.Run("imports a webhook by consumer, provider and events", func(t *testing.T) {
+		f := clienttest.NewFake()
+		create := func(consumer string, events ...string) *broker.WebhookResponse {
+			w := broker.Webhook{Consumer: &broker.Pacticipant{Name: consumer}, Provider: &broker.Pacticipant{Name: "Order API"}}
+			for _, e := range events {
+				w.Events = append(w.Events, broker.WebhookEvent{Name: e})
+			}
+			res, err := f.CreateWebhook(w)
+			assert.NoError(t, err)
+			return res
+		}
+		create("Product API", "contract_content_changed")
+		expected := create("Product API", "contract_content_changed", "provider_verification_published")
+		create("Billing API", "contract_content_changed", "provider_verification_published")
+
+		id, err := importID(t, f, webhook(), "consumer=Product API,provider=Order API,events=provider_verification_published+contract_content_changed")
+
+		assert.NoError(t, err)
+		assert.Equal(t, expected.Webhook.ID, id)
+	})
+
+	t.Run("reports no matches", func(t *testing.T) {
+		_, err := importID(t, clienttest.NewFake(), environment(), "name=production")
+
+		assert.EqualError(t, err, "no pact_environment matches name=production")
+	})
+}
@@ -0,0 +1,398 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"iter"
+	"log"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/list"
+	"github.com/hashicorp/terraform-plugin-framework/list/schema"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-go/tfprotov6"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+	"github.com/pactflow/terraform/broker"
+	"github.com/pactflow/terraform/client"
+)
+
+// listResource finds the resources of one type in the broker for terraform query, so that unmanaged resources can be
+// discovered and their configuration generated. The resources themselves are served by the SDK provider, so their
+// schemas (and their attributes, when the query includes them) come from the SDK provider's server
+type listResource struct {
+	listing
+	sdk    tfprotov6.ProviderServer
+	client client.BrokerAPI
+}
+
+// listing describes how to list one type of resource
+type listing struct {
+	// typeName is the type of the managed resource that is listed
+	typeName string
+	// pactflow is true for resources that are only available on the Pactflow platform
+	pactflow bool
+	// filters are the list block's attributes, which are read into listFilters
+	filters map[string]schema.Attribute
+	list    func(ctx context.Context, c client.BrokerAPI, f listFilters) iter.Seq2[listItem, error]
+}
+
+// listFilters are the filters given in a list block. Only the filters in the listing's schema are set
+type listFilters struct {
+	namePrefix types.String
+	team       types.String
+	active     types.Bool
+	production types.Bool
+	consumer   types.String
+	provider   types.String
+}
+
+// listOptions returns the filters that are supported by the client
+func (f listFilters) listOptions() client.ListOptions {
+	return client.ListOptions{NamePrefix: f.namePrefix.ValueString(), TeamUUID: f.team.ValueString()}
+}
+
+// listItem is a listed resource
+type listItem struct {
+	id          string
+	displayName string
+}
+
+var (
+	namePrefixFilter = schema.StringAttribute{
+		Optional:    true,
+		Description: "Only list resources whose name starts with this prefix",
+	}
+	teamFilter = schema.StringAttribute{
+		Optional:    true,
+		Description: "Only list resources belonging to the team with this UUID",
+	}
+)
+
+var _ list.ListResourceWithConfigure = &listResource{}
+var _ list.ListResourceWithRawV6Schemas = &listResource{}
+
+// newListResources returns the list resources for the SDK provider's resources, served by sdk
+func newListResources(sdk tfprotov6.ProviderServer) []func() list.ListResource {
+	listings := []listing{
+		pacticipantListing,
+		teamListing,
+		userListing,
+		roleListing,
+		environmentListing,
+		secretListing,
+		webhookListing,
+	}
+
+	res := make([]func() list.ListResource, len(listings))
+	for i, l := range listings {
+		res[i] = func() list.ListResource {
+			return &listResource{listing: l, sdk: sdk}
+		}
+	}
+
+	return res
+}
+
+func (l *listResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = l.typeName
+}
+
+func (l *listResource) ListResourceConfigSchema(ctx context.Context, req list.ListResourceSchemaRequest, resp *list.ListResourceSchemaResponse) {
+	resp.Schema = schema.Schema{
+		Description: fmt.Sprintf("Lists the %s resources in the broker", l.typeName),
+		Attributes:  l.filters,
+	}
+}
+
+// RawV6Schemas returns the schema and identity schema of the SDK resource
+func (l *listResource) RawV6Schemas(ctx context.Context, req list.RawV6SchemaRequest, resp *list.RawV6SchemaResponse) {
+	schemas, err := l.sdk.GetProviderSchema(ctx, &tfprotov6.GetProviderSchemaRequest{})
+	if err != nil {
+		log.Println("[ERROR] unable to get the schema of", l.typeName, err)
+		return
+	}
+	identities, err := l.sdk.GetResourceIdentitySchemas(ctx, &tfprotov6.GetResourceIdentitySchemasRequest{})
+	if err != nil {
+		log.Println("[ERROR] unable to get the identity schema of", l.typeName, err)
+		return
+	}
+
+	resp.ProtoV6Schema = schemas.ResourceSchemas[l.typeName]
+	resp.ProtoV6IdentitySchema = identities.IdentitySchemas[l.typeName]
+}
+
+func (l *listResource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	l.client, _ = req.ProviderData.(client.BrokerAPI)
+}
+
+func (l *listResource) List(ctx context.Context, req list.ListRequest, stream *list.ListResultsStream) {
+	filters, diags := l.readFilters(ctx, req.Config)
+	if diags.HasError() {
+		stream.Results = list.ListResultsStreamDiagnostics(diags)
+		return
+	}
+
+	if l.client == nil {
+		diags.AddError("Provider not configured", fmt.Sprintf("The provider must be configured before %s resources are listed", l.typeName))
+		stream.Results = list.ListResultsStreamDiagnostics(diags)
+		return
+	}
+	if l.pactflow {
+		if err := checkPactflow(l.typeName, l.client); err != nil {
+			diags.AddError("Unsupported broker", err.Error())
+			stream.Results = list.ListResultsStreamDiagnostics(diags)
+			return
+		}
+	}
+
+	log.Println("[DEBUG] listing", l.typeName)
+
+	stream.Results = func(push func(list.ListResult) bool) {
+		var count int64
+		for item, err := range l.list(ctx, l.client, filters) {
+			if err != nil {
+				push(list.ListResult{Diagnostics: frameworkDiagnostics(brokerDiagnostics(nil, "Unable to list "+l.typeName, err))})
+				return
+			}
+
+			result, found := l.result(ctx, req, item)
+			if !found {
+				continue
+			}
+			if !push(result) {
+				return
+			}
+
+			count++
+			if req.Limit > 0 && count >= req.Limit {
+				return
+			}
+		}
+	}
+}
+
+// readFilters reads the filters in the listing's schema from the list block
+func (l *listResource) readFilters(ctx context.Context, config tfsdk.Config) (listFilters, diag.Diagnostics) {
+	var f listFilters
+	var diags diag.Diagnostics
+
+	for name := range l.filters {
+		var target any
+		switch name {
+		case "name_prefix":
+			target = &f.namePrefix
+		case "team":
+			target = &f.team
+		case "active":
+			target = &f.active
+		case "production":
+			target = &f.production
+		case "consumer_name":
+			target = &f.consumer
+		case "provider_name":
+			target = &f.provider
+		default:
+			diags.AddError("Unsupported filter", fmt.Sprintf("%s does not support the %s filter", l.typeName, name))
+			continue
+		}
+		diags.Append(config.GetAttribute(ctx, path.Root(name), target)...)
+	}
+
+	return f, diags
+}
+
+// result returns the list result for an item, returning false if the item was deleted while it was being read
+func (l *listResource) result(ctx context.Context, req list.ListRequest, item listItem) (list.ListResult, bool) {
+	result := req.NewListResult(ctx)
+	result.DisplayName = item.displayName
+	result.Diagnostics.Append(result.Identity.SetAttribute(ctx, path.Root("id"), item.id)...)
+
+	if !req.IncludeResource || result.Diagnostics.HasError() {
+		return result, true
+	}
+
+	state, diags := l.read(ctx, item.id, result.Resource.Schema.Type().TerraformType(ctx))
+	result.Diagnostics.Append(diags...)
+	if !diags.HasError() && state.IsNull() {
+		return result, false
+	}
+	result.Resource.Raw = state
+
+	return result, true
+}
+
+// read reads the attributes of a resource by its ID, in the same way as the SDK provider reads it when it is refreshed
+func (l *listResource) read(ctx context.Context, id string, typ tftypes.Type) (tftypes.Value, diag.Diagnostics) {
+	var diags diag.Diagnostics
+
+	objectType, ok := typ.(tftypes.Object)
+	if !ok {
+		diags.AddError("Unable to read "+l.typeName, fmt.Sprintf("unexpected resource type %s", typ))
+		return tftypes.NewValue(typ, nil), diags
+	}
+
+	values := make(map[string]tftypes.Value, len(objectType.AttributeTypes))
+	for k, t := range objectType.AttributeTypes {
+		values[k] = tftypes.NewValue(t, nil)
+	}
+	values["id"] = tftypes.NewValue(tftypes.String, id)
+
+	current, err := tfprotov6.NewDynamicValue(objectType, tftypes.NewValue(objectType, values))
+	if err != nil {
+		diags.AddError("Unable to read "+l.typeName, err.Error())
+		return tftypes.NewValue(typ, nil), diags
+	}
+
+	resp, err := l.sdk.ReadResource(ctx, &tfprotov6.ReadResourceRequest{TypeName: l.typeName, CurrentState: &current})
+	if err != nil {
+		diags.AddError("Unable to read "+l.typeName, err.Error())
+		return tftypes.NewValue(typ, nil), diags
+	}
+	diags.Append(protoDiagnostics(resp.Diagnostics)...)
+	if diags.HasError() || resp.NewState == nil {
+		return tftypes.NewValue(typ, nil), diags
+	}
+
+	state, err := resp.NewState.Unmarshal(objectType)
+	if err != nil {
+		diags.AddError("Unable to read "+l.typeName, err.Error())
+		return tftypes.NewValue(typ, nil), diags
+	}
+
+	return state, diags
+}
+
+var pacticipantListing = listing{
+	typeName: "pact_pacticipant",
+	filters:  map[string]schema.Attribute{"name_prefix": namePrefixFilter},
+	list: func(ctx context.Context, c client.BrokerAPI, f listFilters) iter.Seq2[listItem, error] {
+		return listItems(c.ListPacticipantsWithContext(ctx, f.listOptions()), includeAll, func(p broker.Pacticipant) listItem {
+			return listItem{p.Name, p.Name}
+		})
+	},
+}
+
+var teamListing = listing{
+	typeName: "pact_team",
+	pactflow: true,
+	filters:  map[string]schema.Attribute{"name_prefix": namePrefixFilter},
+	list: func(ctx context.Context, c client.BrokerAPI, f listFilters) iter.Seq2[listItem, error] {
+		return listItems(c.ListTeamsWithContext(ctx, f.listOptions()), includeAll, func(t broker.Team) listItem {
+			return listItem{t.UUID, t.Name}
+		})
+	},
+}
+
+var userListing = listing{
+	typeName: "pact_user",
+	pactflow: true,
+	filters: map[string]schema.Attribute{
+		"name_prefix": namePrefixFilter,
+		"team":        teamFilter,
+		"active": schema.BoolAttribute{
+			Optional:    true,
+			Description: "Only list active (true) or inactive (false) users",
+		},
+	},
+	list: func(ctx context.Context, c client.BrokerAPI, f listFilters) iter.Seq2[listItem, error] {
+		return listItems(c.ListUsersWithContext(ctx, f.listOptions()), func(u broker.User) bool {
+			return f.active.IsNull() || u.Active == f.active.ValueBool()
+		}, func(u broker.User) listItem {
+			if u.Email == "" {
+				return listItem{u.UUID, u.Name}
+			}
+			return listItem{u.UUID, fmt.Sprintf("%s <%s>", u.Name, u.Email)}
+		})
+	},
+}
+
+var roleListing = listing{
+	typeName: "pact_role",
+	pactflow: true,
+	filters:  map[string]schema.Attribute{"name_prefix": namePrefixFilter},
+	list: func(ctx context.Context, c client.BrokerAPI, f listFilters) iter.Seq2[listItem, error] {
+		return listItems(c.ListRolesWithContext(ctx, f.listOptions()), includeAll, func(r broker.Role) listItem {
+			return listItem{r.UUID, r.Name}
+		})
+	},
+}
+
+var environmentListing = listing{
+	typeName: "pact_environment",
+	filters: map[string]schema.Attribute{
+		"name_prefix": namePrefixFilter,
+		"team":        teamFilter,
+		"production": schema.BoolAttribute{
+			Optional:    true,
+			Description: "Only list production (true) or non-production (false) environments",
+		},
+	},
+	list: func(ctx context.Context, c client.BrokerAPI, f listFilters) iter.Seq2[listItem, error] {
+		return listItems(c.ListEnvironmentsWithContext(ctx, f.listOptions()), func(e broker.Environment) bool {
+			return f.production.IsNull() || e.Production == f.production.ValueBool()
+		}, func(e broker.Environment) listItem {
+			return listItem{e.UUID, e.Name}
+		})
+	},
+}
+
+var secretListing = listing{
+	typeName: "pact_secret",
+	pactflow: true,
+	filters: map[string]schema.Attribute{
+		"name_prefix": namePrefixFilter,
+		"team":        teamFilter,
+	},
+	list: func(ctx context.Context, c client.BrokerAPI, f listFilters) iter.Seq2[listItem, error] {
+		return listItems(c.ListSecretsWithContext(ctx, f.listOptions()), includeAll, func(s broker.Secret) listItem {
+			return listItem{s.UUID, s.Name}
+		})
+	},
+}
+
+// webhookListing filters webhooks by consumer and provider rather than by name, as webhooks don't have names
+var webhookListing = listing{
+	typeName: "pact_webhook",
+	filters: map[string]schema.Attribute{
+		"team": teamFilter,
+		"consumer_name": schema.StringAttribute{
+			Optional:    true,
+			Description: "Only list webhooks for the consumer with this name",
+		},
+		"provider_name": schema.StringAttribute{
+			Optional:    true,
+			Description: "Only list webhooks for the provider with this name",
+		},
+	},
+	list: func(ctx context.Context, c client.BrokerAPI, f listFilters) iter.Seq2[listItem, error] {
+		return listItems(c.ListWebhooksWithContext(ctx, client.ListOptions{TeamUUID: f.team.ValueString()}), func(w broker.Webhook) bool {
+			if !f.consumer.IsNull() && pacticipantName(w.Consumer) != f.consumer.ValueString() {
+				return false
+			}
+			return f.provider.IsNull() || pacticipantName(w.Provider) == f.provider.ValueString()
+		}, func(w broker.Webhook) listItem {
+			if w.Description == "" {
+				return listItem{w.ID, w.ID}
+			}
+			return listItem{w.ID, w.Description}
+		})
+	},
+}
+
+// listItems returns the items of a List method that are accepted by include, stopping at the first error
+func listItems[T any](seq iter.Seq2[T, error], include func(T) bool, item func(T) listItem) iter.Seq2[listItem, error] {
+	return func(yield func(listItem, error) bool) {
+		for v, err := range seq {
+			if err != nil {
+				yield(listItem{}, err)
+				return
+			}
+			if include(v) && !yield(item(v), nil) {
+				return
+			}
+		}
+	}
+}
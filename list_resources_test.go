@@ -0,0 +1,199 @@
+package main
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/providerserver"
+	"github.com/hashicorp/terraform-plugin-go/tfprotov6"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+	"github.com/hashicorp/terraform-plugin-mux/tf5to6server"
+	"github.com/pactflow/terraform/broker"
+	"github.com/pactflow/terraform/client/clienttest"
+	"github.com/stretchr/testify/assert"
+)
+
+// listed is a list result, with the ID from its identity and its attributes if the resource was included
+type listed struct {
+	id          string
+	displayName string
+	attributes  map[string]tftypes.Value
+}
+
+// listResources lists resources with the given list block configuration, as terraform query does
+func listResources(t *testing.T, f *clienttest.Fake, resource string, config map[string]tftypes.Value, includeResource bool, limit int64) ([]listed, []*tfprotov6.Diagnostic) {
+	ctx := context.Background()
+	sdk := Provider()
+	sdk.SetMeta(&providerMeta{BrokerAPI: f})
+	upgraded, err := tf5to6server.UpgradeServer(ctx, sdk.GRPCProvider)
+	assert.NoError(t, err)
+	server := providerserver.NewProtocol6(newFrameworkProvider(sdk, upgraded)())()
+
+	schemaResp, err := server.GetProviderSchema(ctx, &tfprotov6.GetProviderSchemaRequest{})
+	assert.NoError(t, err)
+	assert.Empty(t, schemaResp.Diagnostics)
+	providerConfig := objectValue(t, schemaResp.Provider.ValueType().(tftypes.Object), nil)
+	configureResp, err := server.ConfigureProvider(ctx, &tfprotov6.ConfigureProviderRequest{Config: &providerConfig})
+	assert.NoError(t, err)
+	assert.Empty(t, configureResp.Diagnostics)
+
+	sdkSchemaResp, err := upgraded.GetProviderSchema(ctx, &tfprotov6.GetProviderSchemaRequest{})
+	assert.NoError(t, err)
+
+	listConfig := objectValue(t, schemaResp.ListResourceSchemas[resource].ValueType().(tftypes.Object), config)
+	stream, err := server.(tfprotov6.ListResourceServer).ListResource(ctx, &tfprotov6.ListResourceRequest{
+		TypeName:        resource,
+		Config:          &listConfig,
+		IncludeResource: includeResource,
+		Limit:           limit,
+	})
+	assert.NoError(t, err)
+
+	var results []listed
+	var diags []*tfprotov6.Diagnostic
+	for result := range stream.Results {
+		diags = append(diags, result.Diagnostics...)
+		if result.Identity == nil {
+			continue
+		}
+
+		identity, err := result.Identity.IdentityData.Unmarshal(tftypes.Object{AttributeTypes: map[string]tftypes.Type{"id": tftypes.String}})
+		assert.NoError(t, err)
+		var identityValues map[string]tftypes.Value
+		assert.NoError(t, identity.As(&identityValues))
+
+		item := listed{displayName: result.DisplayName}
+		assert.NoError(t, identityValues["id"].As(&item.id))
+		if result.Resource != nil {
+			state, err := result.Resource.Unmarshal(sdkSchemaResp.ResourceSchemas[resource].ValueType())
+			assert.NoError(t, err)
+			assert.NoError(t, state.As(&item.attributes))
+		}
+		results = append(results, item)
+	}
+
+	return results, diags
+}
+
+// objectValue returns a value of the object type with the given attributes, and all others null
+func objectValue(t *testing.T, typ tftypes.Object, attributes map[string]tftypes.Value) tfprotov6.DynamicValue {
+	values := make(map[string]tftypes.Value, len(typ.AttributeTypes))
+	for k, attributeType := range typ.AttributeTypes {
+		values[k] = tftypes.NewValue(attributeType, nil)
+	}
+	for k, v := range attributes {
+		values[k] = v
+	}
+
+	value, err := tfprotov6.NewDynamicValue(typ, tftypes.NewValue(typ, values))
+	assert.NoError(t, err)
+
+	return value
+}
+
+func displayNames(results []listed) []string {
+	names := make([]string, len(results))
+	for i, r := range results {
+		names[i] = r.displayName
+	}
+
+	return names
+}
+
+func TestListResources(t *testing.T) {
+	t.Run("lists teams by name prefix", func(t *testing.T) {
+		f := clienttest.NewFake()
+		platform, _ := f.CreateTeam(broker.TeamCreateOrUpdateRequest{Name: "Platform"})
+		_, _ = f.CreateTeam(broker.TeamCreateOrUpdateRequest{Name: "Payments"})
+		_, _ = f.CreateTeam(broker.TeamCreateOrUpdateRequest{Name: "Mobile"})
+
+		results, diags := listResources(t, f, "pact_team", map[string]tftypes.Value{
+			"name_prefix": tftypes.NewValue(tftypes.String, "Pla"),
+		}, false, 0)
+
+		assert.Empty(t, diags)
+		assert.Equal(t, []listed{{id: platform.UUID, displayName: "Platform"}}, results)
+	})
+
+	t.Run("filters users by team and active status", func(t *testing.T) {
+		f := clienttest.NewFake()
+		team, _ := f.CreateTeam(broker.TeamCreateOrUpdateRequest{Name: "Platform"})
+		jane, _ := f.CreateUser(broker.User{Name: "Jane", Email: "jane@example.com", Active: true})
+		john, _ := f.CreateUser(broker.User{Name: "John", Email: "john@example.com"})
+		_, _ = f.CreateUser(broker.User{Name: "Joan", Email: "joan@example.com", Active: true})
+		_, err := f.UpdateTeamAssignments(broker.TeamsAssignmentRequest{UUID: team.UUID, Users: []string{jane.UUID, john.UUID}})
+		assert.NoError(t, err)
+
+		results, diags := listResources(t, f, "pact_user", map[string]tftypes.Value{
+			"team":   tftypes.NewValue(tftypes.String, team.UUID),
+			"active": tftypes.NewValue(tftypes.Bool, true),
+		}, false, 0)
+
+		assert.Empty(t, diags)
+		assert.Equal(t, []listed{{id: jane.UUID, displayName: "Jane <jane@example.com>"}}, results)
+	})
+
+	t.Run("filters environments by the production flag", func(t *testing.T) {
+		f := clienttest.NewFake()
+		_, _ = f.CreateEnvironment(broker.EnvironmentCreateOrUpdateRequest{Name: "production", Production: true})
+		_, _ = f.CreateEnvironment(broker.EnvironmentCreateOrUpdateRequest{Name: "test"})
+
+		results, diags := listResources(t, f, "pact_environment", map[string]tftypes.Value{
+			"production": tftypes.NewValue(tftypes.Bool, false),
+		}, false, 0)
+
+		assert.Empty(t, diags)
+		assert.Equal(t, []string{"test"}, displayNames(results))
+	})
+
+	t.Run("filters webhooks by consumer", func(t *testing.T) {
+		f := clienttest.NewFake()
+		_, _ = f.CreateWebhook(broker.Webhook{Description: "Build product", Consumer: &broker.Pacticipant{Name: "Product API"}})
+		_, _ = f.CreateWebhook(broker.Webhook{Description: "Build billing", Consumer: &broker.Pacticipant{Name: "Billing API"}})
+
+		results, diags := listResources(t, f, "pact_webhook", map[string]tftypes.Value{
+			"consumer_name": tftypes.NewValue(tftypes.String, "Product API"),
+		}, false, 0)
+
+		assert.Empty(t, diags)
+		assert.Equal(t, []string{"Build product"}, displayNames(results))
+	})
+
+	t.Run("includes the attributes of each resource", func(t *testing.T) {
+		f := clienttest.NewFake()
+		role, _ := f.CreateRole(broker.Role{Name: "CI Maintainer", Permissions: []broker.Permission{{Scope: "contract_data:manage:*"}}})
+
+		results, diags := listResources(t, f, "pact_role", map[string]tftypes.Value{
+			"name_prefix": tftypes.NewValue(tftypes.String, "CI"),
+		}, true, 0)
+
+		assert.Empty(t, diags)
+		assert.Len(t, results, 1)
+		assert.Equal(t, role.UUID, results[0].id)
+		assert.True(t, results[0].attributes["name"].Equal(tftypes.NewValue(tftypes.String, "CI Maintainer")))
+		assert.True(t, results[0].attributes["uuid"].Equal(tftypes.NewValue(tftypes.String, role.UUID)))
+	})
+
+	t.Run("stops at the limit", func(t *testing.T) {
+		f := clienttest.NewFake()
+		for _, name := range []string{"a", "b", "c"} {
+			_, _ = f.CreatePacticipant(broker.Pacticipant{Name: name})
+		}
+
+		results, diags := listResources(t, f, "pact_pacticipant", nil, false, 2)
+
+		assert.Empty(t, diags)
+		assert.Len(t, results, 2)
+	})
+
+	t.Run("requires Pactflow for Pactflow resources", func(t *testing.T) {
+		f := clienttest.NewFake()
+		f.Info = clienttest.OSSBrokerInfo()
+
+		results, diags := listResources(t, f, "pact_secret", nil, false, 0)
+
+		assert.Empty(t, results)
+		assert.Len(t, diags, 1)
+		assert.Contains(t, diags[0].Detail, "pact_secret is not supported by an OSS Pact Broker")
+	})
+}
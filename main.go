@@ -1,14 +1,65 @@
 package main
 
 import (
-	"github.com/hashicorp/terraform-plugin-sdk/plugin"
-	"github.com/hashicorp/terraform-plugin-sdk/terraform"
+	"context"
+	"flag"
+	"log"
+	"os"
+
+	"github.com/hashicorp/terraform-plugin-framework/providerserver"
+	"github.com/hashicorp/terraform-plugin-go/tfprotov6"
+	"github.com/hashicorp/terraform-plugin-go/tfprotov6/tf6server"
+	"github.com/hashicorp/terraform-plugin-mux/tf5to6server"
+	"github.com/hashicorp/terraform-plugin-mux/tf6muxserver"
 )
 
+const providerAddress = "registry.terraform.io/pactflow/pact"
+
 func main() {
-	plugin.Serve(&plugin.ServeOpts{
-		ProviderFunc: func() terraform.ResourceProvider {
-			return Provider()
-		},
-	})
+	// The export subcommand runs standalone, rather than being served to Terraform
+	if len(os.Args) > 1 && os.Args[1] == "export" {
+		log.SetFlags(0)
+		log.SetOutput(exportLogFilter(os.Stderr))
+		os.Exit(runExport(os.Args[2:]))
+	}
+
+	debug := flag.Bool("debug", false, "start the provider in debug mode, for use with a debugger such as delve")
+	flag.Parse()
+
+	server, err := providerServer(context.Background())
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	var opts []tf6server.ServeOpt
+	if *debug {
+		opts = append(opts, tf6server.WithManagedDebug())
+	}
+
+	if err := tf6server.Serve(providerAddress, server, opts...); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// providerServer muxes the SDK provider (upgraded to protocol v6), which serves all resources and data sources,
+// with the framework provider, which serves provider functions, ephemeral resources and list resources. The SDK
+// provider must come first, as the mux configures the servers in order and the framework provider shares the SDK
+// provider's client
+func providerServer(ctx context.Context) (func() tfprotov6.ProviderServer, error) {
+	sdk := Provider()
+
+	upgraded, err := tf5to6server.UpgradeServer(ctx, sdk.GRPCProvider)
+	if err != nil {
+		return nil, err
+	}
+
+	mux, err := tf6muxserver.NewMuxServer(ctx,
+		func() tfprotov6.ProviderServer { return upgraded },
+		providerserver.NewProtocol6(newFrameworkProvider(sdk, upgraded)()),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return mux.ProviderServer, nil
 }
@@ -1,15 +1,26 @@
 package main
 
 import (
+	"context"
 	"crypto/tls"
+	"errors"
+	"fmt"
+	"log"
 	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
 
-	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
 	"github.com/pactflow/terraform/client"
 )
 
 func Provider() *schema.Provider {
-	return &schema.Provider{
+	provider := &schema.Provider{
 		ResourcesMap: map[string]*schema.Resource{
 			"pact_role":           role(),
 			"pact_role_v1":        roleV1(),
@@ -23,27 +34,60 @@ func Provider() *schema.Provider {
 			"pact_authentication": authentication(),
 			"pact_environment":    environment(),
 		},
-		ConfigureFunc: configureProvider,
+		DataSourcesMap: map[string]*schema.Resource{
+			"pact_broker_info": brokerInfo(),
+		},
 		Schema: map[string]*schema.Schema{
 			"access_token": {
-				Type:        schema.TypeString,
+				Type:          schema.TypeString,
+				Optional:      true,
+				DefaultFunc:   schema.EnvDefaultFunc(client.EnvToken, nil),
+				ConflictsWith: []string{"basic_auth_username", "basic_auth_password", "access_token_file", "access_token_command"},
+				Description:   "An API Bearer token to authenticate to a Pactflow account (for Pactflow users only). Defaults to the PACT_BROKER_TOKEN environment variable",
+			},
+			"access_token_file": {
+				Type:          schema.TypeString,
+				Optional:      true,
+				ConflictsWith: []string{"basic_auth_username", "basic_auth_password", "access_token_command"},
+				Description:   "Path to a file containing the API Bearer token, such as on a secrets mount. The file is read for every request, so the token may be rotated",
+			},
+			"access_token_command": {
+				Type:          schema.TypeList,
+				Optional:      true,
+				MinItems:      1,
+				Elem:          &schema.Schema{Type: schema.TypeString},
+				ConflictsWith: []string{"basic_auth_username", "basic_auth_password", "access_token_file"},
+				Description:   "A command (and its arguments) that outputs the API Bearer token, e.g. [\"vault\", \"kv\", \"get\", \"-field=token\", \"secret/pactflow\"]. The command is run again when the token expires or is rejected",
+			},
+			"read_access_token": {
+				Type:          schema.TypeString,
+				Optional:      true,
+				ConflictsWith: []string{"basic_auth_username", "basic_auth_password"},
+				Description:   "An API Bearer token used for requests that only read from the broker, such as refreshing state and data sources. Allows plan-only pipelines to run without write credentials",
+			},
+			"read_only": {
+				Type:        schema.TypeBool,
 				Optional:    true,
-				Description: "An API Bearer token to authenticate to a Pactflow account (for Pactflow users only)",
+				Default:     false,
+				Description: "Reject any request that would modify the broker before it is sent, for plan-only pipelines",
 			},
 			"basic_auth_username": {
 				Type:        schema.TypeString,
 				Optional:    true,
-				Description: "A basic auth username to authenticate to a Pact Broker (not required for Pactflow users)",
+				DefaultFunc: schema.EnvDefaultFunc(client.EnvUsername, nil),
+				Description: "A basic auth username to authenticate to a Pact Broker (not required for Pactflow users). Defaults to the PACT_BROKER_USERNAME environment variable",
 			},
 			"basic_auth_password": {
 				Type:        schema.TypeString,
 				Optional:    true,
-				Description: "A basic auth password to authenticate to a Pact Broker (not required for Pactflow users)",
+				DefaultFunc: schema.EnvDefaultFunc(client.EnvPassword, nil),
+				Description: "A basic auth password to authenticate to a Pact Broker (not required for Pactflow users). Defaults to the PACT_BROKER_PASSWORD environment variable",
 			},
 			"host": {
 				Type:        schema.TypeString,
 				Required:    true,
-				Description: "A fully qualified hostname (e.g. for a Pactflow account https://mybroker.pact.dius.com.au",
+				DefaultFunc: schema.EnvDefaultFunc(client.EnvBaseURL, nil),
+				Description: "A fully qualified hostname (e.g. for a Pactflow account https://mybroker.pact.dius.com.au), including any path the broker is hosted under (e.g. https://tools.example.com/pact-broker). Defaults to the PACT_BROKER_BASE_URL environment variable",
 			},
 			"tls_insecure": {
 				Type:        schema.TypeBool,
@@ -51,19 +95,335 @@ func Provider() *schema.Provider {
 				Default:     false,
 				Description: "Disable TLS verification checks for privately hosted brokers",
 			},
+			"ca_cert_file": {
+				Type:          schema.TypeString,
+				Optional:      true,
+				ConflictsWith: []string{"ca_cert_pem"},
+				Description:   "Path to a PEM encoded bundle of certificate authorities to trust (in addition to the system roots), for brokers using a certificate issued by an internal CA",
+			},
+			"ca_cert_pem": {
+				Type:          schema.TypeString,
+				Optional:      true,
+				ConflictsWith: []string{"ca_cert_file"},
+				Description:   "A PEM encoded bundle of certificate authorities to trust (in addition to the system roots), for brokers using a certificate issued by an internal CA",
+			},
+			"client_cert_pem": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				RequiredWith: []string{"client_key_pem"},
+				Description:  "A PEM encoded client certificate, presented to the broker for mutual TLS",
+			},
+			"client_key_pem": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Sensitive:    true,
+				RequiredWith: []string{"client_cert_pem"},
+				Description:  "The PEM encoded private key for client_cert_pem",
+			},
+			"proxy_url": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "The URL of an HTTP proxy to access the broker via (e.g. http://proxy.example.com:3128). Defaults to the HTTP_PROXY and HTTPS_PROXY environment variables",
+			},
+			"no_proxy": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "A comma separated list of hosts, domains and CIDR ranges that should not be accessed via proxy_url",
+			},
+			"max_retries": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      3,
+				ValidateFunc: validation.IntAtLeast(0),
+				Description:  "The maximum number of times to retry a request that failed due to rate limiting, a transient server error or a dropped connection. Set to 0 to disable retries",
+			},
+			"retry_wait_min": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      1,
+				ValidateFunc: validation.IntAtLeast(0),
+				Description:  "The minimum time (in seconds) to wait before retrying a request. The wait time doubles with each attempt",
+			},
+			"retry_wait_max": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      30,
+				ValidateFunc: validation.IntAtLeast(0),
+				Description:  "The maximum time (in seconds) to wait before retrying a request",
+			},
+			"requests_per_second": {
+				Type:         schema.TypeFloat,
+				Optional:     true,
+				Default:      0,
+				ValidateFunc: validation.FloatAtLeast(0),
+				Description:  "The maximum rate of requests to the broker, shared by all resources. Set to 0 (the default) for no limit",
+			},
+			"max_concurrent_requests": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      0,
+				ValidateFunc: validation.IntAtLeast(0),
+				Description:  "The maximum number of requests to the broker in flight at once, shared by all resources. Set to 0 (the default) for no limit",
+			},
+			"cache_reads": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "Cache the responses to read requests for the duration of a plan or apply, so that data shared by many resources (e.g. users and teams) is only fetched once. The cache is invalidated whenever the same type of resource, or one that it embeds (e.g. the pacticipants of a webhook), is modified",
+			},
+			"validate_credentials": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "Check the credentials against the broker when the provider is configured, failing early if they are rejected",
+			},
+			"validate_references": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "Check during plan that the teams, users and roles referred to by resources exist, rather than failing during apply. Missing pacticipants are only logged as warnings, as they may be created later in the same plan",
+			},
+			"wait_for_ready": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      0,
+				ValidateFunc: validation.IntAtLeast(0),
+				Description:  "The maximum time (in seconds) to wait for the broker to become available (via its heartbeat endpoint) before managing any resources. Set to 0 to disable",
+			},
 		},
 	}
+
+	provider.ConfigureContextFunc = func(ctx context.Context, d *schema.ResourceData) (interface{}, diag.Diagnostics) {
+		// The request context ends once the provider is configured, whereas the client needs a context
+		// that lasts until Terraform is interrupted
+		stopCtx, ok := schema.StopContext(ctx)
+		if !ok {
+			stopCtx = context.Background()
+		}
+
+		return configureProvider(stopCtx, d)
+	}
+
+	return provider
 }
 
-func configureProvider(d *schema.ResourceData) (interface{}, error) {
-	baseURL, err := url.Parse(d.Get("host").(string))
-	return client.NewClient(nil, client.Config{
-		AccessToken:       d.Get("access_token").(string),
-		BasicAuthUsername: d.Get("basic_auth_username").(string),
-		BasicAuthPassword: d.Get("basic_auth_password").(string),
-		CustomTLSConfig: &tls.Config{
-			InsecureSkipVerify: d.Get("tls_insecure").(bool),
-		},
-		BaseURL: baseURL,
-	}), err
+// providerMeta is the configured client, shared by all resources, along with the provider settings that don't affect
+// the client itself
+type providerMeta struct {
+	client.BrokerAPI
+	validateReferences bool
+
+	mutex sync.Mutex
+	// planned are the resources being created in the current plan, by kind and name (see planPacticipant)
+	planned map[string]map[string]bool
+}
+
+// plan records a resource of the given kind that is being created in the current plan
+func (m *providerMeta) plan(kind referenceKind, id string) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if m.planned == nil {
+		m.planned = map[string]map[string]bool{}
+	}
+	if m.planned[kind.name] == nil {
+		m.planned[kind.name] = map[string]bool{}
+	}
+	m.planned[kind.name][id] = true
+}
+
+// isPlanned returns true if a resource of the given kind is being created in the current plan
+func (m *providerMeta) isPlanned(kind referenceKind, id string) bool {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	return m.planned[kind.name][id]
+}
+
+func configureProvider(ctx context.Context, d *schema.ResourceData) (interface{}, diag.Diagnostics) {
+	// Trailing slashes are removed, so that paths are appended correctly for brokers hosted under a sub-path
+	baseURL, err := url.Parse(strings.TrimRight(d.Get("host").(string), "/"))
+	if err != nil {
+		return nil, diag.Diagnostics{{
+			Severity:      diag.Error,
+			Summary:       "Invalid host",
+			Detail:        err.Error(),
+			AttributePath: cty.GetAttrPath("host"),
+		}}
+	}
+
+	tlsConfig, err := providerTLSConfig(d)
+	if err != nil {
+		return nil, diag.Diagnostics{{Severity: diag.Error, Summary: "Invalid TLS configuration", Detail: err.Error()}}
+	}
+
+	var proxyURL *url.URL
+	if proxy := d.Get("proxy_url").(string); proxy != "" {
+		proxyURL, err = url.Parse(proxy)
+		if err != nil {
+			return nil, diag.Diagnostics{{
+				Severity:      diag.Error,
+				Summary:       "Invalid proxy_url",
+				Detail:        err.Error(),
+				AttributePath: cty.GetAttrPath("proxy_url"),
+			}}
+		}
+	}
+
+	config := client.Config{
+		ReadOnly:              d.Get("read_only").(bool),
+		CustomTLSConfig:       tlsConfig,
+		ProxyURL:              proxyURL,
+		NoProxy:               d.Get("no_proxy").(string),
+		BaseURL:               baseURL,
+		MaxRetries:            d.Get("max_retries").(int),
+		RetryWaitMin:          time.Duration(d.Get("retry_wait_min").(int)) * time.Second,
+		RetryWaitMax:          time.Duration(d.Get("retry_wait_max").(int)) * time.Second,
+		RequestsPerSecond:     d.Get("requests_per_second").(float64),
+		MaxConcurrentRequests: d.Get("max_concurrent_requests").(int),
+		CacheReads:            d.Get("cache_reads").(bool),
+		BaseContext:           ctx,
+	}
+	providerCredentials(d, &config)
+
+	// Credentials may also come from the environment, so can't be fully validated by the schema
+	if err := config.Validate(); err != nil {
+		return nil, diag.Diagnostics{{
+			Severity: diag.Error,
+			Summary:  "Invalid provider credentials",
+			Detail:   fmt.Sprintf("%s: set either access_token (PACT_BROKER_TOKEN), or basic_auth_username (PACT_BROKER_USERNAME) and basic_auth_password (PACT_BROKER_PASSWORD)", err),
+		}}
+	}
+
+	c := client.NewClient(nil, config)
+
+	if wait := d.Get("wait_for_ready").(int); wait > 0 {
+		log.Println("[INFO] waiting up to", wait, "seconds for the broker to be ready")
+		if err := c.WaitForReady(time.Duration(wait) * time.Second); err != nil {
+			return nil, diag.Diagnostics{{
+				Severity:      diag.Error,
+				Summary:       "Broker is not ready",
+				Detail:        err.Error(),
+				AttributePath: cty.GetAttrPath("wait_for_ready"),
+			}}
+		}
+	}
+
+	// Locations of resources are discovered from the broker index, falling back to the defaults if it's unavailable.
+	// The index requires authentication, so is also used to validate the credentials
+	var diags diag.Diagnostics
+	validate := d.Get("validate_credentials").(bool)
+	if err := c.DiscoverWithContext(ctx); err != nil {
+		if validate {
+			credentials := "access_token, or basic_auth_username and basic_auth_password"
+			if config.ReadAccessToken != "" {
+				credentials = "read_access_token"
+			}
+			return nil, credentialsDiagnostics(baseURL, credentials, err)
+		}
+		log.Println("[WARN] unable to discover resources from the broker index, using default locations:", err)
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Warning,
+			Summary:  "Unable to read the broker index",
+			Detail:   fmt.Sprintf("The locations of resources could not be discovered, so the defaults will be used: %s", err),
+		})
+	}
+
+	// The index is read with the read_access_token if there is one, so the token used for writes is checked separately
+	if validate && config.ReadAccessToken != "" && (config.AccessToken != "" || config.TokenSource != nil) {
+		writeConfig := config
+		writeConfig.ReadAccessToken = ""
+		writeConfig.CacheReads = false
+		if err := client.NewClient(nil, writeConfig).DiscoverWithContext(ctx); err != nil {
+			return nil, credentialsDiagnostics(baseURL, "access_token", err)
+		}
+	}
+
+	return &providerMeta{BrokerAPI: c, validateReferences: d.Get("validate_references").(bool)}, diags
+}
+
+// credentialsDiagnostics describes an error validating the named credentials against the broker
+func credentialsDiagnostics(baseURL *url.URL, credentials string, err error) diag.Diagnostics {
+	if errors.Is(err, client.ErrUnauthorized) || errors.Is(err, client.ErrForbidden) {
+		return diag.Diagnostics{{
+			Severity: diag.Error,
+			Summary:  fmt.Sprintf("The credentials for %s were rejected", baseURL),
+			Detail:   fmt.Sprintf("Please check the %s: %s", credentials, err),
+		}}
+	}
+
+	return brokerDiagnostics(nil, fmt.Sprintf("Unable to validate the credentials for %s", baseURL), err)
+}
+
+// providerTLSConfig loads the CA bundle and client certificate for the broker connection
+func providerTLSConfig(d *schema.ResourceData) (*tls.Config, error) {
+	caCert := []byte(d.Get("ca_cert_pem").(string))
+	if file := d.Get("ca_cert_file").(string); file != "" {
+		var err error
+		caCert, err = os.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("unable to read ca_cert_file: %w", err)
+		}
+	}
+
+	return client.NewTLSConfig(client.TLSOptions{
+		Insecure:      d.Get("tls_insecure").(bool),
+		CACertPEM:     caCert,
+		ClientCertPEM: []byte(d.Get("client_cert_pem").(string)),
+		ClientKeyPEM:  []byte(d.Get("client_key_pem").(string)),
+	})
+}
+
+// providerTokenSource returns a token source for tokens obtained from a file or command, if configured
+// providerCredentials sets the credentials from the provider configuration. Credentials from the environment are only
+// used for an authentication scheme that isn't configured explicitly, so an access_token in the configuration isn't
+// rejected because PACT_BROKER_USERNAME happens to be set (and vice versa)
+func providerCredentials(d *schema.ResourceData, config *client.Config) {
+	config.TokenSource = providerTokenSource(d)
+	config.AccessToken = d.Get("access_token").(string)
+	config.ReadAccessToken = d.Get("read_access_token").(string)
+	config.BasicAuthUsername = d.Get("basic_auth_username").(string)
+	config.BasicAuthPassword = d.Get("basic_auth_password").(string)
+
+	token := configured(d, "access_token", "access_token_file", "access_token_command", "read_access_token")
+	basicAuth := configured(d, "basic_auth_username", "basic_auth_password")
+	switch {
+	case token && !basicAuth:
+		config.BasicAuthUsername = ""
+		config.BasicAuthPassword = ""
+	case basicAuth && !token:
+		config.AccessToken = ""
+	}
+}
+
+// configured returns whether any of the attributes are set in the provider configuration, rather than from the environment
+func configured(d *schema.ResourceData, attributes ...string) bool {
+	raw := d.GetRawConfig()
+	if raw.IsNull() || !raw.IsKnown() {
+		return false
+	}
+
+	for _, attribute := range attributes {
+		if !raw.GetAttr(attribute).IsNull() {
+			return true
+		}
+	}
+
+	return false
+}
+
+func providerTokenSource(d *schema.ResourceData) client.TokenSource {
+	if file := d.Get("access_token_file").(string); file != "" {
+		return client.FileTokenSource{Path: file}
+	}
+
+	if raw := d.Get("access_token_command").([]interface{}); len(raw) > 0 {
+		command := make([]string, len(raw))
+		for i, arg := range raw {
+			command[i], _ = arg.(string)
+		}
+		return client.NewCommandTokenSource(command)
+	}
+
+	return nil
 }
@@ -0,0 +1,144 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/ephemeral"
+	"github.com/hashicorp/terraform-plugin-framework/function"
+	"github.com/hashicorp/terraform-plugin-framework/list"
+	"github.com/hashicorp/terraform-plugin-framework/provider"
+	fwschema "github.com/hashicorp/terraform-plugin-framework/provider/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-go/tfprotov6"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/pactflow/terraform/client"
+)
+
+// frameworkProvider serves the features that are only available via the plugin framework, such as provider functions,
+// ephemeral resources and list resources. It is muxed with the SDK provider (see main.go), which continues to serve all
+// resources and data sources
+type frameworkProvider struct {
+	sdk *schema.Provider
+	// sdkServer is the SDK provider's server, which list resources use to read the SDK provider's resources
+	sdkServer tfprotov6.ProviderServer
+}
+
+var _ provider.ProviderWithFunctions = &frameworkProvider{}
+var _ provider.ProviderWithEphemeralResources = &frameworkProvider{}
+var _ provider.ProviderWithListResources = &frameworkProvider{}
+
+func newFrameworkProvider(sdk *schema.Provider, sdkServer tfprotov6.ProviderServer) func() provider.Provider {
+	return func() provider.Provider {
+		return &frameworkProvider{sdk: sdk, sdkServer: sdkServer}
+	}
+}
+
+func (p *frameworkProvider) Metadata(ctx context.Context, req provider.MetadataRequest, resp *provider.MetadataResponse) {
+	resp.TypeName = "pact"
+}
+
+// Schema mirrors the SDK provider's schema, as Terraform requires all muxed servers to have the same provider configuration
+func (p *frameworkProvider) Schema(ctx context.Context, req provider.SchemaRequest, resp *provider.SchemaResponse) {
+	attributes := make(map[string]fwschema.Attribute, len(p.sdk.Schema))
+	for k, s := range p.sdk.Schema {
+		attribute, err := frameworkAttribute(s)
+		if err != nil {
+			resp.Diagnostics.AddError("Unsupported provider attribute", fmt.Sprintf("%s: %s", k, err))
+			continue
+		}
+		attributes[k] = attribute
+	}
+
+	resp.Schema = fwschema.Schema{Attributes: attributes}
+}
+
+// Configure shares the SDK provider's client with the ephemeral and list resources. The mux configures the SDK provider
+// first, so the client has already been created with the same configuration
+func (p *frameworkProvider) Configure(ctx context.Context, req provider.ConfigureRequest, resp *provider.ConfigureResponse) {
+	if c, ok := p.sdk.Meta().(client.BrokerAPI); ok {
+		resp.EphemeralResourceData = c
+		resp.ListResourceData = c
+	}
+}
+
+func (p *frameworkProvider) Resources(ctx context.Context) []func() resource.Resource {
+	return nil
+}
+
+func (p *frameworkProvider) DataSources(ctx context.Context) []func() datasource.DataSource {
+	return nil
+}
+
+func (p *frameworkProvider) EphemeralResources(ctx context.Context) []func() ephemeral.EphemeralResource {
+	return []func() ephemeral.EphemeralResource{
+		newTokenEphemeral,
+		newSystemAccountTokenEphemeral,
+	}
+}
+
+func (p *frameworkProvider) ListResources(ctx context.Context) []func() list.ListResource {
+	return newListResources(p.sdkServer)
+}
+
+func (p *frameworkProvider) Functions(ctx context.Context) []func() function.Function {
+	return []func() function.Function{
+		newPacticipantURLFunction,
+		newBadgeURLFunction,
+		newEscapeNameFunction,
+		newWebhookPlaceholderFunction,
+	}
+}
+
+// frameworkAttribute converts an SDK provider attribute to the equivalent framework attribute
+func frameworkAttribute(s *schema.Schema) (fwschema.Attribute, error) {
+	// As for the SDK, an attribute that is required but has a default from the environment is optional when
+	// the environment provides a value
+	required, optional := s.Required, s.Optional
+	if required && s.DefaultFunc != nil {
+		if v, err := s.DefaultFunc(); err != nil || v != nil {
+			required, optional = false, true
+		}
+	}
+
+	switch s.Type {
+	case schema.TypeString:
+		return fwschema.StringAttribute{Required: required, Optional: optional, Sensitive: s.Sensitive, Description: s.Description}, nil
+	case schema.TypeBool:
+		return fwschema.BoolAttribute{Required: required, Optional: optional, Sensitive: s.Sensitive, Description: s.Description}, nil
+	case schema.TypeInt:
+		return fwschema.Int64Attribute{Required: required, Optional: optional, Sensitive: s.Sensitive, Description: s.Description}, nil
+	case schema.TypeFloat:
+		return fwschema.Float64Attribute{Required: required, Optional: optional, Sensitive: s.Sensitive, Description: s.Description}, nil
+	case schema.TypeList:
+		elem, ok := s.Elem.(*schema.Schema)
+		if !ok {
+			return nil, fmt.Errorf("lists of blocks are not supported")
+		}
+		elemType, err := frameworkType(elem.Type)
+		if err != nil {
+			return nil, err
+		}
+		return fwschema.ListAttribute{ElementType: elemType, Required: required, Optional: optional, Sensitive: s.Sensitive, Description: s.Description}, nil
+	}
+
+	return nil, fmt.Errorf("type %s is not supported", s.Type)
+}
+
+func frameworkType(t schema.ValueType) (attr.Type, error) {
+	switch t {
+	case schema.TypeString:
+		return types.StringType, nil
+	case schema.TypeBool:
+		return types.BoolType, nil
+	case schema.TypeInt:
+		return types.Int64Type, nil
+	case schema.TypeFloat:
+		return types.Float64Type, nil
+	}
+
+	return nil, fmt.Errorf("type %s is not supported", t)
+}
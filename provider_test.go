@@ -1,11 +1,159 @@
 package main
 
 import (
+	"context"
+	"fmt"
+	"net/http/httptest"
+	"os"
+	"os/exec"
 	"testing"
+
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-go/tfprotov6"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+	"github.com/pactflow/terraform/client"
+	"github.com/pactflow/terraform/client/clienttest"
+	"github.com/stretchr/testify/assert"
 )
 
+// testProviderFactories serve the provider to resource.UnitTest, as Terraform would run it
+var testProviderFactories = map[string]func() (tfprotov6.ProviderServer, error){
+	"pact": func() (tfprotov6.ProviderServer, error) {
+		server, err := providerServer(context.Background())
+		if err != nil {
+			return nil, err
+		}
+
+		return server(), nil
+	},
+}
+
+// fakeBroker starts a fake broker for the duration of the test, and configures the provider to use it via the environment.
+// Tests are skipped if the Terraform CLI isn't available, as it would otherwise be downloaded
+func fakeBroker(t *testing.T) *clienttest.Server {
+	if _, err := exec.LookPath("terraform"); err != nil && os.Getenv("TF_ACC_TERRAFORM_PATH") == "" {
+		t.Skip("terraform is not installed: add it to the PATH or set TF_ACC_TERRAFORM_PATH to run this test")
+	}
+
+	s := clienttest.NewServer()
+	t.Cleanup(s.Close)
+	t.Setenv(client.EnvBaseURL, s.URL)
+	t.Setenv(client.EnvToken, "test-token")
+
+	return s
+}
+
 func TestProvider(t *testing.T) {
 	if err := Provider().InternalValidate(); err != nil {
 		t.Fatalf("err: %s", err)
 	}
 }
+
+// configureTestProvider configures the provider as Terraform does, with the given attributes and all others null
+func configureTestProvider(t *testing.T, attributes map[string]cty.Value) (*client.Client, diag.Diagnostics) {
+	p := Provider()
+	block := schema.InternalMap(p.Schema).CoreConfigSchema()
+
+	values := make(map[string]cty.Value)
+	for k, typ := range block.ImpliedType().AttributeTypes() {
+		values[k] = cty.NullVal(typ)
+	}
+	for k, v := range attributes {
+		values[k] = v
+	}
+
+	// As the gRPC server does, the raw configuration is set so explicit configuration can be told apart from the environment
+	config := terraform.NewResourceConfigShimmed(cty.ObjectVal(values), block)
+	config.CtyValue = cty.ObjectVal(values)
+
+	diags := p.Configure(context.Background(), config)
+	if diags.HasError() {
+		return nil, diags
+	}
+
+	return p.Meta().(*providerMeta).BrokerAPI.(*client.Client), diags
+}
+
+func TestConfigureProvider(t *testing.T) {
+	server := clienttest.NewServer()
+	defer server.Close()
+
+	t.Run("uses an access token over basic auth credentials from the environment", func(t *testing.T) {
+		t.Setenv(client.EnvUsername, "user")
+		t.Setenv(client.EnvPassword, "pass")
+
+		c, diags := configureTestProvider(t, map[string]cty.Value{
+			"host":         cty.StringVal(server.URL),
+			"access_token": cty.StringVal("1234"),
+		})
+
+		assert.False(t, diags.HasError())
+		assert.Equal(t, "1234", c.Config.AccessToken)
+		assert.Empty(t, c.Config.BasicAuthUsername)
+		assert.Empty(t, c.Config.BasicAuthPassword)
+	})
+
+	t.Run("uses basic auth credentials over an access token from the environment", func(t *testing.T) {
+		t.Setenv(client.EnvToken, "1234")
+
+		c, diags := configureTestProvider(t, map[string]cty.Value{
+			"host":                cty.StringVal(server.URL),
+			"basic_auth_username": cty.StringVal("user"),
+			"basic_auth_password": cty.StringVal("pass"),
+		})
+
+		assert.False(t, diags.HasError())
+		assert.Empty(t, c.Config.AccessToken)
+		assert.Equal(t, "user", c.Config.BasicAuthUsername)
+		assert.Equal(t, "pass", c.Config.BasicAuthPassword)
+	})
+
+	t.Run("rejects an access token together with basic auth credentials from the environment", func(t *testing.T) {
+		t.Setenv(client.EnvToken, "1234")
+		t.Setenv(client.EnvUsername, "user")
+		t.Setenv(client.EnvPassword, "pass")
+
+		_, diags := configureTestProvider(t, map[string]cty.Value{
+			"host": cty.StringVal(server.URL),
+		})
+
+		assert.True(t, diags.HasError())
+		assert.Equal(t, "Invalid provider credentials", diags[0].Summary)
+		assert.Contains(t, diags[0].Detail, client.ErrConflictingAuth.Error())
+	})
+
+	t.Run("validates both the read and write access tokens", func(t *testing.T) {
+		broker := httptest.NewServer(clienttest.NewHandler(clienttest.NewFake(), "read-token"))
+		defer broker.Close()
+
+		_, diags := configureTestProvider(t, map[string]cty.Value{
+			"host":                 cty.StringVal(broker.URL),
+			"access_token":         cty.StringVal("expired-token"),
+			"read_access_token":    cty.StringVal("read-token"),
+			"validate_credentials": cty.True,
+			"max_retries":          cty.NumberIntVal(0),
+		})
+
+		assert.True(t, diags.HasError())
+		assert.Equal(t, fmt.Sprintf("The credentials for %s were rejected", broker.URL), diags[0].Summary)
+		assert.Contains(t, diags[0].Detail, "Please check the access_token:")
+	})
+
+	t.Run("names the read access token if it is rejected", func(t *testing.T) {
+		broker := httptest.NewServer(clienttest.NewHandler(clienttest.NewFake(), "write-token"))
+		defer broker.Close()
+
+		_, diags := configureTestProvider(t, map[string]cty.Value{
+			"host":                 cty.StringVal(broker.URL),
+			"access_token":         cty.StringVal("write-token"),
+			"read_access_token":    cty.StringVal("expired-token"),
+			"validate_credentials": cty.True,
+			"max_retries":          cty.NumberIntVal(0),
+		})
+
+		assert.True(t, diags.HasError())
+		assert.Contains(t, diags[0].Detail, "Please check the read_access_token:")
+	})
+}
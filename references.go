@@ -0,0 +1,133 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/pactflow/terraform/broker"
+	"github.com/pactflow/terraform/client"
+)
+
+// referenceKind is a type of broker resource that other resources refer to, by name or UUID
+type referenceKind struct {
+	name   string
+	exists func(ctx context.Context, c client.BrokerAPI, id string) error
+	// byName is set if resources are referred to by a name given in the configuration, rather than a generated UUID,
+	// so a reference may be to a resource that is created in the same configuration but not yet planned
+	byName bool
+}
+
+var (
+	pacticipantReference = referenceKind{"pacticipant", func(ctx context.Context, c client.BrokerAPI, name string) error {
+		_, err := c.ReadPacticipantWithContext(ctx, name)
+		return err
+	}, true}
+	teamReference = referenceKind{"team", func(ctx context.Context, c client.BrokerAPI, uuid string) error {
+		_, err := c.ReadTeamWithContext(ctx, broker.Team{UUID: uuid})
+		return err
+	}, false}
+	userReference = referenceKind{"user", func(ctx context.Context, c client.BrokerAPI, uuid string) error {
+		_, err := c.ReadUserWithContext(ctx, uuid)
+		return err
+	}, false}
+	roleReference = referenceKind{"role", func(ctx context.Context, c client.BrokerAPI, uuid string) error {
+		_, err := c.ReadRoleWithContext(ctx, uuid)
+		return err
+	}, false}
+)
+
+// reference is an attribute of a resource that refers to other broker resources
+type reference struct {
+	attribute string
+	kind      referenceKind
+}
+
+// validateReferences checks during plan that the resources referred to by the given attributes exist, when enabled by
+// the provider's validate_references setting. Only new or changed attributes are checked, and values that aren't known
+// until apply (such as the UUID of a team being created in the same plan) are skipped. All broken references are
+// reported together, except for missing resources referred to by name, which are only logged (see planPacticipant)
+func validateReferences(refs ...reference) schema.CustomizeDiffFunc {
+	return func(ctx context.Context, d *schema.ResourceDiff, meta interface{}) error {
+		m, ok := meta.(*providerMeta)
+		if !ok || !m.validateReferences {
+			return nil
+		}
+
+		var errs []error
+		for _, ref := range refs {
+			if d.Id() != "" && !d.HasChange(ref.attribute) {
+				continue
+			}
+
+			for _, id := range knownReferences(d.GetRawConfig().GetAttr(ref.attribute)) {
+				if m.isPlanned(ref.kind, id) {
+					continue
+				}
+
+				log.Println("[DEBUG] checking that", ref.kind.name, id, "referenced by", ref.attribute, "exists")
+				err := ref.kind.exists(ctx, m, id)
+				switch {
+				case errors.Is(err, client.ErrNotFound) && ref.kind.byName:
+					log.Printf("[WARN] %s: %s %q does not exist, unless it is created in the same configuration\n", ref.attribute, ref.kind.name, id)
+				case errors.Is(err, client.ErrNotFound):
+					errs = append(errs, fmt.Errorf("%s: %s %q does not exist", ref.attribute, ref.kind.name, id))
+				case err != nil:
+					errs = append(errs, fmt.Errorf("%s: unable to check that %s %q exists: %w", ref.attribute, ref.kind.name, id, err))
+				}
+			}
+		}
+
+		return errors.Join(errs...)
+	}
+}
+
+// planPacticipant records a pacticipant that is being created (or renamed) in the current plan, so that references to
+// it from other resources aren't looked up by validateReferences. Terraform plans a resource before the resources that
+// refer to it, but not before those that give its name literally, so a pacticipant that isn't found is never an error
+func planPacticipant(ctx context.Context, d *schema.ResourceDiff, meta interface{}) error {
+	m, ok := meta.(*providerMeta)
+	if !ok || !m.validateReferences || !d.NewValueKnown("name") {
+		return nil
+	}
+
+	if d.Id() == "" || d.HasChange("name") {
+		m.plan(pacticipantReference, d.Get("name").(string))
+	}
+
+	return nil
+}
+
+// knownReferences returns the known names or UUIDs in the configured value of an attribute, which may be a single
+// value, a set or list, or a map with a name (e.g. webhook_consumer)
+func knownReferences(v cty.Value) []string {
+	if v.IsNull() || !v.IsKnown() {
+		return nil
+	}
+
+	t := v.Type()
+	switch {
+	case t == cty.String:
+		if v.AsString() == "" {
+			return nil
+		}
+		return []string{v.AsString()}
+	case t.IsSetType() || t.IsListType():
+		var ids []string
+		for it := v.ElementIterator(); it.Next(); {
+			_, e := it.Element()
+			ids = append(ids, knownReferences(e)...)
+		}
+		return ids
+	case t.IsMapType():
+		if !v.HasIndex(cty.StringVal("name")).True() {
+			return nil
+		}
+		return knownReferences(v.Index(cty.StringVal("name")))
+	}
+
+	return nil
+}
@@ -0,0 +1,152 @@
+package main
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-go/tfprotov5"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/pactflow/terraform/broker"
+	"github.com/pactflow/terraform/client"
+	"github.com/pactflow/terraform/client/clienttest"
+	"github.com/stretchr/testify/assert"
+)
+
+// planResource plans the creation of a resource with the given configuration, returning the diagnostics
+func planResource(t *testing.T, meta *providerMeta, resource string, config map[string]tftypes.Value) []*tfprotov5.Diagnostic {
+	ctx := context.Background()
+	p := Provider()
+	p.SetMeta(meta)
+	server := schema.NewGRPCProviderServer(p)
+
+	schemaResp, err := server.GetProviderSchema(ctx, &tfprotov5.GetProviderSchemaRequest{})
+	assert.NoError(t, err)
+	objectType := schemaResp.ResourceSchemas[resource].ValueType().(tftypes.Object)
+
+	values := make(map[string]tftypes.Value, len(objectType.AttributeTypes))
+	for k, typ := range objectType.AttributeTypes {
+		values[k] = tftypes.NewValue(typ, nil)
+	}
+	for k, v := range config {
+		values[k] = v
+	}
+
+	configValue, err := tfprotov5.NewDynamicValue(objectType, tftypes.NewValue(objectType, values))
+	assert.NoError(t, err)
+	priorState, err := tfprotov5.NewDynamicValue(objectType, tftypes.NewValue(objectType, nil))
+	assert.NoError(t, err)
+
+	resp, err := server.PlanResourceChange(ctx, &tfprotov5.PlanResourceChangeRequest{
+		TypeName:         resource,
+		PriorState:       &priorState,
+		ProposedNewState: &configValue,
+		Config:           &configValue,
+	})
+	assert.NoError(t, err)
+
+	return resp.Diagnostics
+}
+
+func stringSet(values ...tftypes.Value) tftypes.Value {
+	return tftypes.NewValue(tftypes.Set{ElementType: tftypes.String}, values)
+}
+
+func TestValidateReferences(t *testing.T) {
+	setup := func(t *testing.T) (*providerMeta, *broker.User) {
+		f := clienttest.NewFake()
+		jane, err := f.CreateUser(broker.User{Name: "Jane", Email: "jane@example.com"})
+		assert.NoError(t, err)
+
+		return &providerMeta{BrokerAPI: f, validateReferences: true}, jane
+	}
+
+	t.Run("reports all broken references at once", func(t *testing.T) {
+		meta, jane := setup(t)
+
+		diags := planResource(t, meta, "pact_team", map[string]tftypes.Value{
+			"name":           tftypes.NewValue(tftypes.String, "Platform"),
+			"users":          stringSet(tftypes.NewValue(tftypes.String, "5678"), tftypes.NewValue(tftypes.String, jane.UUID)),
+			"administrators": stringSet(tftypes.NewValue(tftypes.String, "1234")),
+		})
+
+		assert.Len(t, diags, 1)
+		assert.Contains(t, diags[0].Summary, `users: user "5678" does not exist`)
+		assert.Contains(t, diags[0].Summary, `administrators: user "1234" does not exist`)
+		assert.NotContains(t, diags[0].Summary, jane.UUID)
+	})
+
+	t.Run("skips references that are unknown until apply", func(t *testing.T) {
+		meta, _ := setup(t)
+
+		diags := planResource(t, meta, "pact_user", map[string]tftypes.Value{
+			"name":  tftypes.NewValue(tftypes.String, "CI"),
+			"type":  tftypes.NewValue(tftypes.String, "system"),
+			"roles": stringSet(tftypes.NewValue(tftypes.String, tftypes.UnknownValue)),
+		})
+
+		assert.Empty(t, diags)
+	})
+
+	t.Run("doesn't look up pacticipants created in the same plan", func(t *testing.T) {
+		meta, _ := setup(t)
+		f := meta.BrokerAPI.(*clienttest.Fake)
+
+		diags := planResource(t, meta, "pact_pacticipant", map[string]tftypes.Value{
+			"name": tftypes.NewValue(tftypes.String, "Product API"),
+		})
+		assert.Empty(t, diags)
+
+		diags = planResource(t, meta, "pact_team", map[string]tftypes.Value{
+			"name":         tftypes.NewValue(tftypes.String, "Platform"),
+			"pacticipants": stringSet(tftypes.NewValue(tftypes.String, "Product API")),
+		})
+
+		assert.Empty(t, diags)
+		assert.NotContains(t, f.Calls, "ReadPacticipant")
+	})
+
+	t.Run("accepts pacticipants that may be planned later", func(t *testing.T) {
+		meta, _ := setup(t)
+		pacticipant := func(name string) tftypes.Value {
+			return tftypes.NewValue(tftypes.Map{ElementType: tftypes.String}, map[string]tftypes.Value{"name": tftypes.NewValue(tftypes.String, name)})
+		}
+
+		// A pacticipant given by a literal name, rather than a reference, isn't necessarily planned first
+		diags := planResource(t, meta, "pact_webhook", map[string]tftypes.Value{
+			"webhook_consumer": pacticipant("Product API"),
+			"webhook_provider": pacticipant("Order API"),
+		})
+		assert.Empty(t, diags)
+
+		diags = planResource(t, meta, "pact_pacticipant", map[string]tftypes.Value{
+			"name": tftypes.NewValue(tftypes.String, "Order API"),
+		})
+		assert.Empty(t, diags)
+	})
+
+	t.Run("reports errors checking references", func(t *testing.T) {
+		meta, _ := setup(t)
+		meta.BrokerAPI.(*clienttest.Fake).Errors["ReadTeam"] = client.ErrForbidden
+
+		diags := planResource(t, meta, "pact_environment", map[string]tftypes.Value{
+			"name":  tftypes.NewValue(tftypes.String, "production"),
+			"teams": stringSet(tftypes.NewValue(tftypes.String, "1234")),
+		})
+
+		assert.Len(t, diags, 1)
+		assert.Contains(t, diags[0].Summary, `teams: unable to check that team "1234" exists`)
+	})
+
+	t.Run("is disabled by default", func(t *testing.T) {
+		meta, _ := setup(t)
+		meta.validateReferences = false
+
+		diags := planResource(t, meta, "pact_environment", map[string]tftypes.Value{
+			"name":  tftypes.NewValue(tftypes.String, "production"),
+			"teams": stringSet(tftypes.NewValue(tftypes.String, "1234")),
+		})
+
+		assert.Empty(t, diags)
+	})
+}
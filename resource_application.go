@@ -1,21 +1,25 @@
 package main
 
 import (
-	"fmt"
+	"context"
 	"log"
 
-	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/pactflow/terraform/broker"
 	"github.com/pactflow/terraform/client"
 )
 
 func application() *schema.Resource {
 	return &schema.Resource{
-		Create:   applicationCreate,
-		Update:   applicationUpdate,
-		Read:     applicationRead,
-		Delete:   applicationDelete,
-		Importer: &schema.ResourceImporter{State: schema.ImportStatePassthrough},
+		CreateContext: stoppable(applicationCreate),
+		UpdateContext: stoppable(applicationUpdate),
+		ReadContext:   stoppable(applicationRead),
+		DeleteContext: stoppable(applicationDelete),
+		CustomizeDiff: planPacticipant,
+		Timeouts:      defaultTimeouts(),
+		Identity:      resourceIdentity(),
+		Importer:      &schema.ResourceImporter{StateContext: schema.ImportStatePassthroughWithIdentity("id")},
 		Schema: map[string]*schema.Schema{
 			"name": {
 				Type:        schema.TypeString,
@@ -42,8 +46,8 @@ func application() *schema.Resource {
 	}
 }
 
-func applicationCreate(d *schema.ResourceData, meta interface{}) error {
-	client := meta.(*client.Client)
+func applicationCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	client := meta.(client.BrokerAPI)
 	name := d.Get("name").(string)
 	url := d.Get("repository_url").(string)
 	branch := d.Get("main_branch").(string)
@@ -57,10 +61,10 @@ func applicationCreate(d *schema.ResourceData, meta interface{}) error {
 		MainBranch:    branch,
 		DisplayName:   displayName,
 	}
-	_, err := client.CreatePacticipant(pacticipant)
+	_, err := client.CreatePacticipantWithContext(ctx, pacticipant)
 
 	if err != nil {
-		return fmt.Errorf("error creating application: %w", err)
+		return brokerDiagnostics(d, "Unable to create application", err)
 	}
 
 	d.SetId(name)
@@ -72,8 +76,8 @@ func applicationCreate(d *schema.ResourceData, meta interface{}) error {
 	return nil
 }
 
-func applicationUpdate(d *schema.ResourceData, meta interface{}) error {
-	client := meta.(*client.Client)
+func applicationUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	client := meta.(client.BrokerAPI)
 	name := d.Get("name").(string)
 	url := d.Get("repository_url").(string)
 	branch := d.Get("main_branch").(string)
@@ -87,10 +91,10 @@ func applicationUpdate(d *schema.ResourceData, meta interface{}) error {
 		MainBranch:    branch,
 		DisplayName:   displayName,
 	}
-	_, err := client.UpdatePacticipant(pacticipant)
+	_, err := client.UpdatePacticipantWithContext(ctx, pacticipant)
 
 	if err != nil {
-		return fmt.Errorf("error updating application: %w", err)
+		return brokerDiagnostics(d, "Unable to update application", err)
 	}
 
 	d.SetId(name)
@@ -102,16 +106,20 @@ func applicationUpdate(d *schema.ResourceData, meta interface{}) error {
 	return nil
 }
 
-func applicationRead(d *schema.ResourceData, meta interface{}) error {
-	client := meta.(*client.Client)
+func applicationRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	client := meta.(client.BrokerAPI)
 	log.Println("[DEBUG] reading pacticipant", d.Id())
 
-	pacticipant, err := client.ReadPacticipant(d.Id())
+	pacticipant, err := client.ReadPacticipantWithContext(ctx, d.Id())
 
 	log.Println("[DEBUG] have pacticipant for READ", pacticipant)
 
+	if removeIfNotFound(d, "pacticipant", err) {
+		return nil
+	}
+
 	if err != nil {
-		return fmt.Errorf("error reading application: %w", err)
+		return brokerDiagnostics(d, "Unable to read application", err)
 	}
 
 	d.SetId(pacticipant.Name)
@@ -123,19 +131,19 @@ func applicationRead(d *schema.ResourceData, meta interface{}) error {
 	return nil
 }
 
-func applicationDelete(d *schema.ResourceData, meta interface{}) error {
-	client := meta.(*client.Client)
+func applicationDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	client := meta.(client.BrokerAPI)
 	name := d.Get("name").(string)
 
 	log.Println("[DEBUG] deleting pacticipant", name)
 
-	err := client.DeletePacticipant(broker.Pacticipant{
+	err := client.DeletePacticipantWithContext(ctx, broker.Pacticipant{
 		Name: name,
 	})
 
 	if err != nil {
 		d.SetId("")
-		return fmt.Errorf("error deleting application: %w", err)
+		return brokerDiagnostics(d, "Unable to delete application", err)
 	}
 
 	return nil
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/terraform"
+	"github.com/pactflow/terraform/client"
+)
+
+func TestPacticipantLifecycle(t *testing.T) {
+	// pact_application is the original name of pact_pacticipant
+	for _, resourceType := range []string{"pact_pacticipant", "pact_application"} {
+		t.Run(resourceType, func(t *testing.T) {
+			testPacticipantLifecycle(t, resourceType)
+		})
+	}
+}
+
+func testPacticipantLifecycle(t *testing.T, resourceType string) {
+	broker := fakeBroker(t)
+	name := resourceType + ".admin"
+
+	resource.UnitTest(t, resource.TestCase{
+		ProtoV6ProviderFactories: testProviderFactories,
+		CheckDestroy: func(*terraform.State) error {
+			if _, err := broker.Fake.ReadPacticipant("AdminService"); !errors.Is(err, client.ErrNotFound) {
+				return errors.New("pacticipant AdminService still exists")
+			}
+			return nil
+		},
+		Steps: []resource.TestStep{
+			{
+				Config: `
+resource "` + resourceType + `" "admin" {
+  name           = "AdminService"
+  repository_url = "github.com/company/admin"
+}`,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(name, "id", "AdminService"),
+					func(*terraform.State) error {
+						p, err := broker.Fake.ReadPacticipant("AdminService")
+						if err != nil {
+							return err
+						}
+						if p.RepositoryURL != "github.com/company/admin" {
+							return errors.New("repository_url was not set in the broker: " + p.RepositoryURL)
+						}
+						return nil
+					},
+				),
+			},
+			{
+				Config: `
+resource "` + resourceType + `" "admin" {
+  name           = "AdminService"
+  repository_url = "github.com/company/admin-service"
+  main_branch    = "main"
+  display_name   = "Admin Service"
+}`,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(name, "main_branch", "main"),
+					func(*terraform.State) error {
+						p, err := broker.Fake.ReadPacticipant("AdminService")
+						if err != nil {
+							return err
+						}
+						if p.MainBranch != "main" || p.DisplayName != "Admin Service" {
+							return errors.New("the pacticipant was not updated in the broker")
+						}
+						return nil
+					},
+				),
+			},
+			{
+				ResourceName:      name,
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+		},
+	})
+}
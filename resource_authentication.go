@@ -1,21 +1,25 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"log"
 
-	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/pactflow/terraform/broker"
 	"github.com/pactflow/terraform/client"
 )
 
 func authentication() *schema.Resource {
 	return &schema.Resource{
-		Importer: &schema.ResourceImporter{State: schema.ImportStatePassthrough},
-		Create:   authenticationCreate,
-		Read:     authenticationRead,
-		Update:   authenticationUpdate,
-		Delete:   authenticationDelete,
+		Importer:      &schema.ResourceImporter{StateContext: schema.ImportStatePassthroughContext},
+		CreateContext: stoppable(authenticationCreate),
+		ReadContext:   stoppable(authenticationRead),
+		UpdateContext: stoppable(authenticationUpdate),
+		DeleteContext: stoppable(authenticationDelete),
+		CustomizeDiff: requirePactflow("pact_authentication"),
+		Timeouts:      defaultTimeouts(),
 		Schema: map[string]*schema.Schema{
 			"github_organizations": {
 				Type: schema.TypeSet,
@@ -69,53 +73,53 @@ func authenticationState(d *schema.ResourceData, r *broker.AuthenticationSetting
 	return nil
 }
 
-func authenticationCreate(d *schema.ResourceData, meta interface{}) error {
-	client := meta.(*client.Client)
+func authenticationCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	client := meta.(client.BrokerAPI)
 	authentication := authenticationFromState(d)
 
-	created, err := client.SetTenantAuthenticationSettings(authentication)
+	created, err := client.SetTenantAuthenticationSettingsWithContext(ctx, authentication)
 
 	if err != nil {
-		return fmt.Errorf("error setting authentication: %w", err)
+		return brokerDiagnostics(d, "Unable to set authentication settings", err)
 	}
 
-	d.SetId(client.Config.BaseURL.Host)
+	d.SetId(client.BaseURL().Host)
 
 	if err = authenticationState(d, created); err != nil {
-		return fmt.Errorf("error setting authentication state: %w", err)
+		return stateDiagnostics("authentication settings", err)
 	}
 
 	return nil
 }
 
-func authenticationRead(d *schema.ResourceData, meta interface{}) error {
-	client := meta.(*client.Client)
-	authentication, err := client.ReadTenantAuthenticationSettings()
+func authenticationRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	client := meta.(client.BrokerAPI)
+	authentication, err := client.ReadTenantAuthenticationSettingsWithContext(ctx)
 
 	if err != nil {
-		return fmt.Errorf("error reading authentication settings: %w", err)
+		return brokerDiagnostics(d, "Unable to read authentication settings", err)
 	}
 
 	if err = authenticationState(d, authentication); err != nil {
-		return fmt.Errorf("error setting authentication settings state: %w", err)
+		return stateDiagnostics("authentication settings", err)
 	}
 
 	return nil
 }
 
-func authenticationUpdate(d *schema.ResourceData, meta interface{}) error {
-	return authenticationCreate(d, meta)
+func authenticationUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	return authenticationCreate(ctx, d, meta)
 }
 
-func authenticationDelete(d *schema.ResourceData, meta interface{}) error {
-	client := meta.(*client.Client)
+func authenticationDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	client := meta.(client.BrokerAPI)
 
 	log.Println("[DEBUG] deleting (clearing) authentication settings")
 
-	_, err := client.SetTenantAuthenticationSettings(broker.AuthenticationSettings{})
+	_, err := client.SetTenantAuthenticationSettingsWithContext(ctx, broker.AuthenticationSettings{})
 
 	if err != nil {
-		return fmt.Errorf("error deleting authentication: %w", err)
+		return brokerDiagnostics(d, "Unable to clear authentication settings", err)
 	}
 
 	d.SetId("")
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/terraform"
+)
+
+func TestAuthenticationLifecycle(t *testing.T) {
+	fake := fakeBroker(t).Fake
+
+	checkSettings := func(organizations, domains []string) resource.TestCheckFunc {
+		return func(*terraform.State) error {
+			settings, err := fake.ReadTenantAuthenticationSettings()
+			if err != nil {
+				return err
+			}
+			github, google := settings.Providers.Github.Organizations, settings.Providers.Google.EmailDomains
+			slices.Sort(github)
+			slices.Sort(google)
+			if !slices.Equal(github, organizations) || !slices.Equal(google, domains) {
+				return fmt.Errorf("unexpected authentication settings in the broker: %v, %v", github, google)
+			}
+			return nil
+		}
+	}
+
+	resource.UnitTest(t, resource.TestCase{
+		ProtoV6ProviderFactories: testProviderFactories,
+		// The settings can't be deleted, so are cleared instead
+		CheckDestroy: checkSettings(nil, nil),
+		Steps: []resource.TestStep{
+			{
+				Config: `
+resource "pact_authentication" "authentication" {
+  github_organizations = ["pactflow"]
+}`,
+				Check: checkSettings([]string{"pactflow"}, nil),
+			},
+			{
+				Config: `
+resource "pact_authentication" "authentication" {
+  github_organizations = ["DiUS", "pactflow"]
+  google_domains       = ["dius.com.au"]
+}`,
+				Check: checkSettings([]string{"DiUS", "pactflow"}, []string{"dius.com.au"}),
+			},
+			{
+				ResourceName:      "pact_authentication.authentication",
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+		},
+	})
+}
@@ -1,24 +1,28 @@
 package main
 
 import (
-	"fmt"
+	"context"
 	"log"
 	"regexp"
 	"sort"
 
-	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
-	"github.com/hashicorp/terraform-plugin-sdk/helper/validation"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
 	"github.com/pactflow/terraform/broker"
 	"github.com/pactflow/terraform/client"
 )
 
 func environment() *schema.Resource {
 	return &schema.Resource{
-		Create:   environmentCreate,
-		Update:   environmentUpdate,
-		Read:     environmentRead,
-		Delete:   environmentDelete,
-		Importer: &schema.ResourceImporter{State: schema.ImportStatePassthrough},
+		CreateContext: stoppable(environmentCreate),
+		UpdateContext: stoppable(environmentUpdate),
+		ReadContext:   stoppable(environmentRead),
+		DeleteContext: stoppable(environmentDelete),
+		CustomizeDiff: validateReferences(reference{"teams", teamReference}),
+		Timeouts:      defaultTimeouts(),
+		Identity:      resourceIdentity(),
+		Importer:      naturalKeyImporter("pact_environment", findEnvironments, "name"),
 		Schema: map[string]*schema.Schema{
 			"name": {
 				Type:         schema.TypeString,
@@ -85,69 +89,81 @@ func environmentFromCRUD(environment broker.EnvironmentCreateOrUpdateResponse) b
 	}
 }
 
-func environmentCreate(d *schema.ResourceData, meta interface{}) error {
-	client := meta.(*client.Client)
+func environmentCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	client := meta.(client.BrokerAPI)
 	environment := getEnvironmentFromState(d)
 
 	teams := ExpandStringSet(d.Get("teams").(*schema.Set))
 	log.Println("[DEBUG] creating environment", environment, teams)
 
-	created, err := client.CreateEnvironment(environmentToCRUD(environment, teams))
+	created, err := client.CreateEnvironmentWithContext(ctx, environmentToCRUD(environment, teams))
 
 	if err != nil {
-		return err
+		return brokerDiagnostics(d, "Unable to create environment", err)
 	}
 
 	d.SetId(created.UUID)
-	setEnvironmentState(d, environmentFromCRUD(*created))
+	if err = setEnvironmentState(d, environmentFromCRUD(*created)); err != nil {
+		return stateDiagnostics("environment", err)
+	}
 
 	return nil
 }
 
-func environmentUpdate(d *schema.ResourceData, meta interface{}) error {
-	client := meta.(*client.Client)
+func environmentUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	client := meta.(client.BrokerAPI)
 	environment := getEnvironmentFromState(d)
 	teams := ExpandStringSet(d.Get("teams").(*schema.Set))
 
 	log.Println("[DEBUG] updating environment", environment)
 
-	updated, err := client.UpdateEnvironment(environmentToCRUD(environment, teams))
+	updated, err := client.UpdateEnvironmentWithContext(ctx, environmentToCRUD(environment, teams))
 
 	if err != nil {
-		return err
+		return brokerDiagnostics(d, "Unable to update environment", err)
 	}
 
-	setEnvironmentState(d, environmentFromCRUD(*updated))
+	if err = setEnvironmentState(d, environmentFromCRUD(*updated)); err != nil {
+		return stateDiagnostics("environment", err)
+	}
 
-	return err
+	return nil
 }
 
-func environmentRead(d *schema.ResourceData, meta interface{}) error {
-	client := meta.(*client.Client)
+func environmentRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	client := meta.(client.BrokerAPI)
 	uuid := d.Id()
 
 	log.Println("[DEBUG] reading environment", uuid)
 
-	environment, err := client.ReadEnvironment(uuid)
+	environment, err := client.ReadEnvironmentWithContext(ctx, uuid)
 
-	if err == nil {
-		d.SetId(environment.UUID)
-		setEnvironmentState(d, *environment)
+	if removeIfNotFound(d, "environment", err) {
+		return nil
 	}
 
-	return err
+	if err != nil {
+		return brokerDiagnostics(d, "Unable to read environment", err)
+	}
+
+	d.SetId(environment.UUID)
+	if err = setEnvironmentState(d, *environment); err != nil {
+		return stateDiagnostics("environment", err)
+	}
+
+	return nil
 }
 
-func environmentDelete(d *schema.ResourceData, meta interface{}) error {
-	client := meta.(*client.Client)
+func environmentDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	client := meta.(client.BrokerAPI)
 
 	log.Println("[DEBUG] deleting environment", d.Id())
 
-	err := client.DeleteEnvironment(getEnvironmentFromState(d))
+	err := client.DeleteEnvironmentWithContext(ctx, getEnvironmentFromState(d))
 
 	if err != nil {
 		d.SetId("")
-		return fmt.Errorf("unable to delete environment %s: %w", d.Id(), err)
+		return brokerDiagnostics(d, "Unable to delete environment", err)
 	}
 
 	return nil
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/terraform"
+	"github.com/pactflow/terraform/client"
+)
+
+func TestEnvironmentLifecycle(t *testing.T) {
+	fake := fakeBroker(t).Fake
+	var uuid string
+
+	resource.UnitTest(t, resource.TestCase{
+		ProtoV6ProviderFactories: testProviderFactories,
+		CheckDestroy: func(*terraform.State) error {
+			if _, err := fake.ReadEnvironment(uuid); !errors.Is(err, client.ErrNotFound) {
+				return fmt.Errorf("environment %s still exists", uuid)
+			}
+			return nil
+		},
+		Steps: []resource.TestStep{
+			{
+				Config: `
+resource "pact_team" "platform" {
+  name = "Platform"
+}
+
+resource "pact_environment" "uat" {
+  name         = "uat"
+  display_name = "User Acceptance Testing"
+  teams        = [pact_team.platform.uuid]
+}`,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("pact_environment.uat", "production", "false"),
+					resource.TestCheckResourceAttr("pact_environment.uat", "teams.#", "1"),
+					func(s *terraform.State) error {
+						uuid = s.RootModule().Resources["pact_environment.uat"].Primary.ID
+						environment, err := fake.ReadEnvironment(uuid)
+						if err != nil {
+							return err
+						}
+						if environment.DisplayName != "User Acceptance Testing" || len(environment.Embedded.Teams) != 1 {
+							return fmt.Errorf("unexpected environment in the broker: %+v", environment)
+						}
+						return nil
+					},
+				),
+			},
+			{
+				Config: `
+resource "pact_team" "platform" {
+  name = "Platform"
+}
+
+resource "pact_environment" "uat" {
+  name         = "uat"
+  display_name = "UAT"
+  production   = true
+}`,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("pact_environment.uat", "teams.#", "0"),
+					func(*terraform.State) error {
+						environment, err := fake.ReadEnvironment(uuid)
+						if err != nil {
+							return err
+						}
+						if environment.DisplayName != "UAT" || !environment.Production || len(environment.Embedded.Teams) != 0 {
+							return fmt.Errorf("the environment was not updated in the broker: %+v", environment)
+						}
+						return nil
+					},
+				),
+			},
+			{
+				ResourceName:      "pact_environment.uat",
+				ImportState:       true,
+				ImportStateId:     "name=uat",
+				ImportStateVerify: true,
+			},
+		},
+	})
+}
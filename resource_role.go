@@ -1,22 +1,27 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"sort"
 
-	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/pactflow/terraform/broker"
 	"github.com/pactflow/terraform/client"
 )
 
 func role() *schema.Resource {
 	return &schema.Resource{
-		Importer: &schema.ResourceImporter{State: schema.ImportStatePassthrough},
-		Create:   roleCreate,
-		Read:     roleRead,
-		Update:   roleUpdate,
-		Delete:   roleDelete,
+		Identity:      resourceIdentity(),
+		Importer:      naturalKeyImporter("pact_role", findRoles, "name"),
+		CreateContext: stoppable(roleCreate),
+		ReadContext:   stoppable(roleRead),
+		UpdateContext: stoppable(roleUpdate),
+		DeleteContext: stoppable(roleDelete),
+		CustomizeDiff: requirePactflow("pact_role"),
+		Timeouts:      defaultTimeouts(),
 		Schema: map[string]*schema.Schema{
 			"name": {
 				Type:        schema.TypeString,
@@ -101,68 +106,72 @@ func setRoleState(d *schema.ResourceData, r *broker.Role) error {
 	return nil
 }
 
-func roleCreate(d *schema.ResourceData, meta interface{}) error {
-	client := meta.(*client.Client)
+func roleCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	client := meta.(client.BrokerAPI)
 	role := getRoleFromState(d)
 
-	created, err := client.CreateRole(role)
+	created, err := client.CreateRoleWithContext(ctx, role)
 
 	if err != nil {
-		return fmt.Errorf("error creating role: %w", err)
+		return brokerDiagnostics(d, "Unable to create role", err)
 	}
 
 	d.SetId(created.UUID)
 
 	if err = setRoleState(d, created); err != nil {
-		return fmt.Errorf("error setting role state: %w", err)
+		return stateDiagnostics("role", err)
 	}
 
 	return nil
 }
 
-func roleRead(d *schema.ResourceData, meta interface{}) error {
-	client := meta.(*client.Client)
-	role, err := client.ReadRole(d.Id())
+func roleRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	client := meta.(client.BrokerAPI)
+	role, err := client.ReadRoleWithContext(ctx, d.Id())
+
+	if removeIfNotFound(d, "role", err) {
+		return nil
+	}
 
 	if err != nil {
-		return fmt.Errorf("error reading role: %w", err)
+		return brokerDiagnostics(d, "Unable to read role", err)
 	}
 
 	if err = setRoleState(d, role); err != nil {
-		return fmt.Errorf("error setting role state: %w", err)
+		return stateDiagnostics("role", err)
 	}
 
 	return nil
 }
 
-func roleUpdate(d *schema.ResourceData, meta interface{}) error {
-	client := meta.(*client.Client)
+func roleUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	client := meta.(client.BrokerAPI)
 	role := getRoleFromState(d)
-	updated, err := client.UpdateRole(role)
+	updated, err := client.UpdateRoleWithContext(ctx, role)
 
 	if err != nil {
-		return fmt.Errorf("error updating role: %w", err)
+		return brokerDiagnostics(d, "Unable to update role", err)
 	}
 
 	if err = setRoleState(d, updated); err != nil {
-		return fmt.Errorf("error setting role state: %w", err)
+		return stateDiagnostics("role", err)
 	}
 
 	return nil
 }
 
-func roleDelete(d *schema.ResourceData, meta interface{}) error {
-	client := meta.(*client.Client)
+func roleDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	client := meta.(client.BrokerAPI)
 	uuid := d.Get("uuid").(string)
 
 	log.Println("[DEBUG] deleting role for user with UUID:", uuid)
 
-	err := client.DeleteRole(broker.Role{
+	err := client.DeleteRoleWithContext(ctx, broker.Role{
 		UUID: uuid,
 	})
 
 	if err != nil {
-		return fmt.Errorf("error deleting role: %w", err)
+		return brokerDiagnostics(d, "Unable to delete role", err)
 	}
 
 	d.SetId("")
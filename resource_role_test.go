@@ -0,0 +1,80 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/terraform"
+	"github.com/pactflow/terraform/client"
+)
+
+func TestRoleLifecycle(t *testing.T) {
+	fake := fakeBroker(t).Fake
+	var uuid string
+
+	resource.UnitTest(t, resource.TestCase{
+		ProtoV6ProviderFactories: testProviderFactories,
+		CheckDestroy: func(*terraform.State) error {
+			if _, err := fake.ReadRole(uuid); !errors.Is(err, client.ErrNotFound) {
+				return fmt.Errorf("role %s still exists", uuid)
+			}
+			return nil
+		},
+		Steps: []resource.TestStep{
+			{
+				Config: `
+resource "pact_role" "maintainer" {
+  name   = "CI Maintainer"
+  scopes = ["contract_data:manage:*"]
+}`,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("pact_role.maintainer", "scopes.#", "1"),
+					func(s *terraform.State) error {
+						uuid = s.RootModule().Resources["pact_role.maintainer"].Primary.ID
+						role, err := fake.ReadRole(uuid)
+						if err != nil {
+							return err
+						}
+						if role.Name != "CI Maintainer" || len(role.Permissions) != 1 {
+							return fmt.Errorf("unexpected role in the broker: %+v", role)
+						}
+						return nil
+					},
+				),
+			},
+			{
+				Config: `
+resource "pact_role" "maintainer" {
+  name   = "CI Administrator"
+  scopes = ["contract_data:manage:*", "team:read:*"]
+}`,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("pact_role.maintainer", "scopes.#", "2"),
+					func(*terraform.State) error {
+						role, err := fake.ReadRole(uuid)
+						if err != nil {
+							return err
+						}
+						scopes := make([]string, len(role.Permissions))
+						for i, p := range role.Permissions {
+							scopes[i] = p.Scope
+						}
+						if role.Name != "CI Administrator" || !slices.Contains(scopes, "team:read:*") {
+							return fmt.Errorf("the role was not updated in the broker: %+v", role)
+						}
+						return nil
+					},
+				),
+			},
+			{
+				ResourceName:      "pact_role.maintainer",
+				ImportState:       true,
+				ImportStateId:     "name=CI Administrator",
+				ImportStateVerify: true,
+			},
+		},
+	})
+}
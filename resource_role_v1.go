@@ -1,10 +1,12 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"log"
 
-	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/pactflow/terraform/broker"
 	"github.com/pactflow/terraform/client"
 )
@@ -31,9 +33,14 @@ func validateRoles(val interface{}, key string) (warns []string, errs []error) {
 func roleV1() *schema.Resource {
 	return &schema.Resource{
 		DeprecationMessage: "This resource is deprecated. Please update to the newer 'pact_role' resource",
-		Create:             roleV1Create,
-		Read:               roleV1Read,
-		Delete:             roleV1Delete,
+		CreateContext:      stoppable(roleV1Create),
+		ReadContext:        stoppable(roleV1Read),
+		DeleteContext:      stoppable(roleV1Delete),
+		CustomizeDiff:      requirePactflow("pact_role_v1"),
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(defaultTimeout),
+			Delete: schema.DefaultTimeout(defaultTimeout),
+		},
 		Schema: map[string]*schema.Schema{
 			"role": {
 				Type:         schema.TypeString,
@@ -62,41 +69,44 @@ func roleV1() *schema.Resource {
 	}
 }
 
-func roleV1Create(d *schema.ResourceData, meta interface{}) error {
-	client := meta.(*client.Client)
+func roleV1Create(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	client := meta.(client.BrokerAPI)
 	userUUID := d.Get("user").(string)
 
 	// NOTE: we only support the admin role at this time
 	log.Println("[DEBUG] creating role for user with UUID:", userUUID)
-	_, err := client.AddAdminRoleToUser(broker.User{
+	_, err := client.AddAdminRoleToUserWithContext(ctx, broker.User{
 		UUID: userUUID,
 	})
 
-	if err == nil {
-		d.SetId(allowedRoles["administrator"])
-		d.Set("name", "Administrator")
+	if err != nil {
+		return brokerDiagnostics(d, "Unable to add the administrator role to user", err)
 	}
 
-	return err
+	d.SetId(allowedRoles["administrator"])
+	d.Set("name", "Administrator")
+
+	return nil
 }
 
-func roleV1Read(d *schema.ResourceData, meta interface{}) error {
+func roleV1Read(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	return nil
 }
 
-func roleV1Delete(d *schema.ResourceData, meta interface{}) error {
-	client := meta.(*client.Client)
+func roleV1Delete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	client := meta.(client.BrokerAPI)
 	userUUID := d.Get("user").(string)
 
 	log.Println("[DEBUG] deleting role for user with UUID:", userUUID)
 
-	_, err := client.RemoveAdminRoleFromUser(broker.User{
+	_, err := client.RemoveAdminRoleFromUserWithContext(ctx, broker.User{
 		UUID: userUUID,
 	})
 
 	if err != nil {
 		d.SetId("")
+		return brokerDiagnostics(d, "Unable to remove the administrator role from user", err)
 	}
 
-	return err
+	return nil
 }
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/terraform"
+	"github.com/pactflow/terraform/client/clienttest"
+)
+
+// pact_role_v1 can't be updated (any change replaces it) or imported, so only creation, replacement and
+// destruction are tested. The role it adds is also seen by pact_user, so the users ignore changes to their roles
+func TestRoleV1Lifecycle(t *testing.T) {
+	fake := fakeBroker(t).Fake
+
+	isAdministrator := func(s *terraform.State, user string) (bool, error) {
+		u, err := fake.ReadUser(s.RootModule().Resources[user].Primary.ID)
+		if err != nil {
+			return false, err
+		}
+		for _, role := range u.Embedded.Roles {
+			if role.UUID == clienttest.AdministratorRoleUUID {
+				return true, nil
+			}
+		}
+		return false, nil
+	}
+	checkAdministrator := func(user string, expected bool) resource.TestCheckFunc {
+		return func(s *terraform.State) error {
+			admin, err := isAdministrator(s, user)
+			if err != nil {
+				return err
+			}
+			if admin != expected {
+				return fmt.Errorf("expected %s to be an administrator: %t", user, expected)
+			}
+			return nil
+		}
+	}
+
+	users := `
+resource "pact_user" "billy" {
+  name  = "Billy Sampson"
+  email = "billy@sampson.co"
+
+  lifecycle {
+    ignore_changes = [roles]
+  }
+}
+
+resource "pact_user" "jane" {
+  name  = "Jane Sampson"
+  email = "jane@sampson.co"
+
+  lifecycle {
+    ignore_changes = [roles]
+  }
+}
+`
+
+	resource.UnitTest(t, resource.TestCase{
+		ProtoV6ProviderFactories: testProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: users + `
+resource "pact_role_v1" "admin" {
+  role = "administrator"
+  user = pact_user.billy.uuid
+}`,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("pact_role_v1.admin", "name", "Administrator"),
+					checkAdministrator("pact_user.billy", true),
+				),
+			},
+			{
+				Config: users + `
+resource "pact_role_v1" "admin" {
+  role = "administrator"
+  user = pact_user.jane.uuid
+}`,
+				Check: resource.ComposeTestCheckFunc(
+					checkAdministrator("pact_user.billy", false),
+					checkAdministrator("pact_user.jane", true),
+				),
+			},
+			{
+				Config: users,
+				Check:  checkAdministrator("pact_user.jane", false),
+			},
+		},
+	})
+}
@@ -1,12 +1,14 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"regexp"
 	"strings"
 
-	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/pactflow/terraform/broker"
 	"github.com/pactflow/terraform/client"
 )
@@ -14,16 +16,19 @@ import (
 var secretType = &schema.Schema{
 	Type:     schema.TypeMap,
 	Optional: true,
-	Elem:     &schema.Resource{},
+	Elem:     &schema.Schema{Type: schema.TypeString},
 }
 
 func secret() *schema.Resource {
 	return &schema.Resource{
-		Create:   secretCreate,
-		Update:   secretUpdate,
-		Read:     secretRead,
-		Delete:   secretDelete,
-		Importer: &schema.ResourceImporter{State: schema.ImportStatePassthrough},
+		CreateContext: stoppable(secretCreate),
+		UpdateContext: stoppable(secretUpdate),
+		ReadContext:   stoppable(secretRead),
+		DeleteContext: stoppable(secretDelete),
+		CustomizeDiff: requirePactflow("pact_secret"),
+		Timeouts:      defaultTimeouts(),
+		Identity:      resourceIdentity(),
+		Importer:      naturalKeyImporter("pact_secret", findSecrets, "name", "team"),
 		Schema: map[string]*schema.Schema{
 			"name": {
 				Type:         schema.TypeString,
@@ -65,7 +70,6 @@ func validateName(val interface{}, key string) (warns []string, errs []error) {
 }
 
 func parseSecret(d *schema.ResourceData, meta interface{}) (broker.Secret, error) {
-	log.Printf("[DEBUG] create or update secret with data %+v \n", d)
 	name := d.Get("name").(string)
 	description := d.Get("description").(string)
 	value := d.Get("value").(string)
@@ -86,67 +90,81 @@ func parseSecret(d *schema.ResourceData, meta interface{}) (broker.Secret, error
 	return secret, nil
 }
 
-func secretCreate(d *schema.ResourceData, meta interface{}) error {
-	client := meta.(*client.Client)
+func secretCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	client := meta.(client.BrokerAPI)
 	secret, _ := parseSecret(d, meta)
-	log.Println("[DEBUG] creating secret", secret)
+	log.Println("[DEBUG] creating secret", secret.Name)
 
-	res, err := client.CreateSecret(secret)
+	res, err := client.CreateSecretWithContext(ctx, secret)
 
-	if err == nil {
-		items := strings.Split(res.Links["self"].Href, "/")
-		id := items[len(items)-1]
-		d.SetId(id)
-
-		return setSecretState(d, secret)
+	if err != nil {
+		return brokerDiagnostics(d, "Unable to create secret", err)
 	}
 
-	return err
+	items := strings.Split(res.Links["self"].Href, "/")
+	id := items[len(items)-1]
+	d.SetId(id)
+
+	secret.UUID = id
+	setSecretState(d, secret)
+
+	return nil
 }
 
-func secretUpdate(d *schema.ResourceData, meta interface{}) error {
-	client := meta.(*client.Client)
+func secretUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	client := meta.(client.BrokerAPI)
 	secret, _ := parseSecret(d, meta)
 
-	log.Println("[DEBUG] updatding secret", secret)
+	log.Println("[DEBUG] updating secret", secret.UUID, secret.Name)
 
-	_, err := client.UpdateSecret(secret)
+	_, err := client.UpdateSecretWithContext(ctx, secret)
 
-	if err == nil {
-		return setSecretState(d, secret)
+	if err != nil {
+		return brokerDiagnostics(d, "Unable to update secret", err)
 	}
 
-	return err
+	setSecretState(d, secret)
+
+	return nil
 }
 
-func secretRead(d *schema.ResourceData, meta interface{}) error {
-	httpClient := meta.(*client.Client)
+func secretRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	httpClient := meta.(client.BrokerAPI)
 
-	secret, err := httpClient.ReadSecret(d.Id())
+	secret, err := httpClient.ReadSecretWithContext(ctx, d.Id())
+	if removeIfNotFound(d, "secret", err) {
+		return nil
+	}
 	if err != nil {
-		return err
+		return brokerDiagnostics(d, "Unable to read secret", err)
 	}
 
-	return setSecretState(d, secret.Secret)
+	// The UUID is only given by the self link, and is the ID of the resource
+	secret.Secret.UUID = d.Id()
+	setSecretState(d, secret.Secret)
+
+	return nil
 }
 
-func secretDelete(d *schema.ResourceData, meta interface{}) error {
-	client := meta.(*client.Client)
+func secretDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	client := meta.(client.BrokerAPI)
 	secret, _ := parseSecret(d, meta)
 
-	log.Println("[DEBUG] deleting secret", secret)
+	log.Println("[DEBUG] deleting secret", secret.UUID)
 
-	err := client.DeleteSecret(secret)
+	err := client.DeleteSecretWithContext(ctx, secret)
 
-	if err == nil {
-		d.SetId("")
+	if err != nil {
+		return brokerDiagnostics(d, "Unable to delete secret", err)
 	}
 
-	return err
+	d.SetId("")
+
+	return nil
 }
 
 func setSecretState(d *schema.ResourceData, secret broker.Secret) error {
-	log.Printf("[DEBUG] setting secret state: %+v \n", secret)
+	log.Println("[DEBUG] setting secret state", secret.UUID, secret.Name)
 
 	d.Set("name", secret.Name)
 	d.Set("uuid", secret.UUID)
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/terraform"
+	"github.com/pactflow/terraform/client"
+)
+
+func TestSecretLifecycle(t *testing.T) {
+	fake := fakeBroker(t).Fake
+	var uuid string
+
+	resource.UnitTest(t, resource.TestCase{
+		ProtoV6ProviderFactories: testProviderFactories,
+		CheckDestroy: func(*terraform.State) error {
+			if _, err := fake.ReadSecret(uuid); !errors.Is(err, client.ErrNotFound) {
+				return fmt.Errorf("secret %s still exists", uuid)
+			}
+			return nil
+		},
+		Steps: []resource.TestStep{
+			{
+				Config: `
+resource "pact_secret" "jenkins" {
+  name        = "JenkinsToken"
+  description = "A token for jenkins webhooks"
+  value       = "super secret thing"
+}`,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("pact_secret.jenkins", "value", "super secret thing"),
+					resource.TestCheckResourceAttrPair("pact_secret.jenkins", "uuid", "pact_secret.jenkins", "id"),
+					func(s *terraform.State) error {
+						uuid = s.RootModule().Resources["pact_secret.jenkins"].Primary.ID
+						secret, err := fake.ReadSecret(uuid)
+						if err != nil {
+							return err
+						}
+						if secret.Name != "JenkinsToken" || secret.Description != "A token for jenkins webhooks" {
+							return fmt.Errorf("unexpected secret in the broker: %+v", secret)
+						}
+						return nil
+					},
+				),
+			},
+			{
+				Config: `
+resource "pact_team" "platform" {
+  name = "Platform"
+}
+
+resource "pact_secret" "jenkins" {
+  name        = "JenkinsToken"
+  description = "The token for the platform team's jenkins webhooks"
+  value       = "an even more secret thing"
+  team        = pact_team.platform.uuid
+}`,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttrPair("pact_secret.jenkins", "team", "pact_team.platform", "uuid"),
+					func(*terraform.State) error {
+						secret, err := fake.ReadSecret(uuid)
+						if err != nil {
+							return err
+						}
+						if secret.Description != "The token for the platform team's jenkins webhooks" || secret.TeamUUID == "" {
+							return fmt.Errorf("the secret was not updated in the broker: %+v", secret)
+						}
+						if stored, _ := fake.Secret(uuid); stored.Value != "an even more secret thing" {
+							return errors.New("the value of the secret was not updated in the broker")
+						}
+						return nil
+					},
+				),
+			},
+			{
+				ResourceName:      "pact_secret.jenkins",
+				ImportState:       true,
+				ImportStateId:     "name=JenkinsToken",
+				ImportStateVerify: true,
+				// As with the broker, the fake never returns the value of a secret
+				ImportStateVerifyIgnore: []string{"value"},
+			},
+		},
+	})
+}
@@ -1,21 +1,33 @@
 package main
 
 import (
-	"fmt"
+	"context"
 	"log"
 
-	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/customdiff"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/pactflow/terraform/broker"
 	"github.com/pactflow/terraform/client"
 )
 
 func team() *schema.Resource {
 	return &schema.Resource{
-		Create:   teamCreate,
-		Update:   teamUpdate,
-		Read:     teamRead,
-		Delete:   teamDelete,
-		Importer: &schema.ResourceImporter{State: schema.ImportStatePassthrough},
+		CreateContext: stoppable(teamCreate),
+		UpdateContext: stoppable(teamUpdate),
+		ReadContext:   stoppable(teamRead),
+		DeleteContext: stoppable(teamDelete),
+		CustomizeDiff: customdiff.All(
+			requirePactflow("pact_team"),
+			validateReferences(
+				reference{"pacticipants", pacticipantReference},
+				reference{"users", userReference},
+				reference{"administrators", userReference},
+			),
+		),
+		Timeouts: defaultTimeouts(),
+		Identity: resourceIdentity(),
+		Importer: naturalKeyImporter("pact_team", findTeams, "name"),
 		Schema: map[string]*schema.Schema{
 			"name": {
 				Type:        schema.TypeString,
@@ -90,8 +102,8 @@ func getTeamFromResourceData(d *schema.ResourceData) broker.Team {
 }
 
 // Removes any users from the team that shouldn't be there, and adds those that should
-func assignTeamUsers(d *schema.ResourceData, meta interface{}) error {
-	client := meta.(*client.Client)
+func assignTeamUsers(ctx context.Context, d *schema.ResourceData, meta interface{}) error {
+	client := meta.(client.BrokerAPI)
 	uuid := d.Id()
 
 	log.Println("[DEBUG] assigning users to team", uuid)
@@ -108,7 +120,7 @@ func assignTeamUsers(d *schema.ResourceData, meta interface{}) error {
 			Users: usersToAdd,
 		}
 
-		res, err := client.UpdateTeamAssignments(req)
+		res, err := client.UpdateTeamAssignmentsWithContext(ctx, req)
 
 		if err != nil {
 			return err
@@ -139,75 +151,85 @@ func teamToCRUDRequest(t broker.Team) broker.TeamCreateOrUpdateRequest {
 	}
 }
 
-func teamCreate(d *schema.ResourceData, meta interface{}) error {
-	client := meta.(*client.Client)
+func teamCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	client := meta.(client.BrokerAPI)
 	team := getTeamFromResourceData(d)
 	create := teamToCRUDRequest(team)
 
 	log.Println("[DEBUG] creating team", team)
 
-	created, err := client.CreateTeam(create)
+	created, err := client.CreateTeamWithContext(ctx, create)
 
 	if err != nil {
-		return fmt.Errorf("error creating team: %w", err)
+		return brokerDiagnostics(d, "Unable to create team", err)
 	}
 
 	team.UUID = created.UUID
 	d.SetId(created.UUID)
 	setTeamState(d, *created)
 
-	err = assignTeamUsers(d, meta)
+	err = assignTeamUsers(ctx, d, meta)
 	if err != nil {
 		d.Partial(true)
 		log.Printf("\n\n[DEBUG] error assigning team users: %v \n\n", err)
-		return fmt.Errorf("error assigning team users: %w", err)
+		return brokerDiagnostics(d, "Unable to assign users to team", err)
 	}
 
-	return err
+	return nil
 }
 
-func teamUpdate(d *schema.ResourceData, meta interface{}) error {
-	client := meta.(*client.Client)
+func teamUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	client := meta.(client.BrokerAPI)
 	team := getTeamFromResourceData(d)
 	update := teamToCRUDRequest(team)
 
 	log.Println("[DEBUG] updating team", team)
 
-	updated, err := client.UpdateTeam(update)
+	updated, err := client.UpdateTeamWithContext(ctx, update)
 
-	if err == nil {
-		setTeamState(d, *updated)
+	if err != nil {
+		return brokerDiagnostics(d, "Unable to update team", err)
 	}
 
-	err = assignTeamUsers(d, meta)
+	setTeamState(d, *updated)
+
+	err = assignTeamUsers(ctx, d, meta)
 	if err != nil {
 		d.Partial(true)
-		return fmt.Errorf("error assigning team users: %w", err)
+		return brokerDiagnostics(d, "Unable to assign users to team", err)
 	}
 
-	return err
+	return nil
 }
 
-func teamRead(d *schema.ResourceData, meta interface{}) error {
-	client := meta.(*client.Client)
+func teamRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	client := meta.(client.BrokerAPI)
 	read := getTeamFromResourceData(d)
 
 	log.Println("[DEBUG] reading team", read)
 
-	team, err := client.ReadTeam(read)
+	team, err := client.ReadTeamWithContext(ctx, read)
 
 	log.Println("[DEBUG] have team for READ", team)
 
-	if err == nil {
-		d.SetId(team.UUID)
-		setTeamState(d, *team)
+	if removeIfNotFound(d, "team", err) {
+		return nil
 	}
 
-	return err
+	if err != nil {
+		return brokerDiagnostics(d, "Unable to read team", err)
+	}
+
+	d.SetId(team.UUID)
+	if err = setTeamState(d, *team); err != nil {
+		return stateDiagnostics("team", err)
+	}
+
+	return nil
 }
 
-func teamDelete(d *schema.ResourceData, meta interface{}) error {
-	client := meta.(*client.Client)
+func teamDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	client := meta.(client.BrokerAPI)
 	uuid := d.Id()
 	team := broker.Team{
 		UUID: uuid,
@@ -215,13 +237,14 @@ func teamDelete(d *schema.ResourceData, meta interface{}) error {
 
 	log.Println("[DEBUG] deleting team", team)
 
-	err := client.DeleteTeam(team)
+	err := client.DeleteTeamWithContext(ctx, team)
 
 	if err != nil {
 		d.SetId("")
+		return brokerDiagnostics(d, "Unable to delete team", err)
 	}
 
-	return err
+	return nil
 }
 
 func setTeamState(d *schema.ResourceData, team broker.Team) error {
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/terraform"
+	"github.com/pactflow/terraform/broker"
+	"github.com/pactflow/terraform/client"
+)
+
+func TestTeamLifecycle(t *testing.T) {
+	fake := fakeBroker(t).Fake
+	var uuid string
+
+	resource.UnitTest(t, resource.TestCase{
+		ProtoV6ProviderFactories: testProviderFactories,
+		CheckDestroy: func(*terraform.State) error {
+			if _, err := fake.ReadTeam(broker.Team{UUID: uuid}); !errors.Is(err, client.ErrNotFound) {
+				return fmt.Errorf("team %s still exists", uuid)
+			}
+			return nil
+		},
+		Steps: []resource.TestStep{
+			{
+				Config: `
+resource "pact_pacticipant" "admin" {
+  name = "AdminService"
+}
+
+resource "pact_user" "bender" {
+  name  = "Bender"
+  email = "bender@example.com"
+}
+
+resource "pact_team" "futurama" {
+  name         = "Futurama"
+  pacticipants = [pact_pacticipant.admin.name]
+  users        = [pact_user.bender.uuid]
+}`,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("pact_team.futurama", "name", "Futurama"),
+					resource.TestCheckResourceAttrPair("pact_team.futurama", "uuid", "pact_team.futurama", "id"),
+					func(s *terraform.State) error {
+						uuid = s.RootModule().Resources["pact_team.futurama"].Primary.ID
+						team, err := fake.ReadTeam(broker.Team{UUID: uuid})
+						if err != nil {
+							return err
+						}
+						if len(team.Embedded.Pacticipants) != 1 || team.Embedded.Pacticipants[0].Name != "AdminService" {
+							return fmt.Errorf("expected the team to have the AdminService pacticipant, got %v", team.Embedded.Pacticipants)
+						}
+						members, err := fake.ReadTeamAssignments(*team)
+						if err != nil {
+							return err
+						}
+						if len(members.Embedded.Users) != 1 {
+							return fmt.Errorf("expected the team to have 1 user, got %d", len(members.Embedded.Users))
+						}
+						return nil
+					},
+				),
+			},
+			{
+				Config: `
+resource "pact_pacticipant" "admin" {
+  name = "AdminService"
+}
+
+resource "pact_user" "bender" {
+  name  = "Bender"
+  email = "bender@example.com"
+}
+
+resource "pact_team" "futurama" {
+  name         = "Planet Express"
+  pacticipants = [pact_pacticipant.admin.name]
+}`,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("pact_team.futurama", "name", "Planet Express"),
+					func(*terraform.State) error {
+						team, err := fake.ReadTeam(broker.Team{UUID: uuid})
+						if err != nil {
+							return err
+						}
+						if team.Name != "Planet Express" {
+							return fmt.Errorf("expected the team to be renamed, got %s", team.Name)
+						}
+						members, err := fake.ReadTeamAssignments(*team)
+						if err != nil {
+							return err
+						}
+						if len(members.Embedded.Users) != 0 {
+							return fmt.Errorf("expected the team to have no users, got %d", len(members.Embedded.Users))
+						}
+						return nil
+					},
+				),
+			},
+			{
+				ResourceName:      "pact_team.futurama",
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+			{
+				ResourceName:      "pact_team.futurama",
+				ImportState:       true,
+				ImportStateId:     "name=Planet Express",
+				ImportStateVerify: true,
+			},
+		},
+	})
+}
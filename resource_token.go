@@ -1,10 +1,12 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"log"
 
-	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/pactflow/terraform/broker"
 	"github.com/pactflow/terraform/client"
 )
@@ -14,6 +16,12 @@ const (
 	readWriteTokenType = "read-write"
 )
 
+// Warns that the provider's own token was regenerated, but could not be updated to match
+const (
+	staleTokenSummary = "Provider credentials are out of date"
+	staleTokenDetail  = "The read-write token was regenerated, but the provider's token could not be updated to match (e.g. it is read from access_token_file or access_token_command). Update the token the provider is configured with before the next plan or apply."
+)
+
 var allowedTokenTypes = map[string]string{
 	readOnlyTokenType:  "Read only token (developer)",
 	readWriteTokenType: "Read/write token (CI)",
@@ -22,7 +30,7 @@ var allowedTokenTypes = map[string]string{
 var tokenType = &schema.Schema{
 	Type:     schema.TypeMap,
 	Optional: true,
-	Elem:     &schema.Resource{},
+	Elem:     &schema.Schema{Type: schema.TypeString},
 }
 
 // Used to convert from TF configuration to a broker.APIToken
@@ -37,11 +45,13 @@ type apiTokenDefinition struct {
 func token() *schema.Resource {
 	return &schema.Resource{
 		DeprecationMessage: "This resource is deprecated and will soon be removed",
-		Create:             tokenCreate,
-		Update:             tokenUpdate,
-		Read:               tokenRead,
-		Delete:             tokenDelete,
-		Importer:           &schema.ResourceImporter{State: schema.ImportStatePassthrough},
+		CreateContext:      stoppable(tokenCreate),
+		UpdateContext:      stoppable(tokenUpdate),
+		ReadContext:        stoppable(tokenRead),
+		DeleteContext:      stoppable(tokenDelete),
+		CustomizeDiff:      requirePactflow("pact_token"),
+		Timeouts:           defaultTimeouts(),
+		Importer:           &schema.ResourceImporter{StateContext: schema.ImportStatePassthroughContext},
 		Schema: map[string]*schema.Schema{
 			"name": {
 				Type:        schema.TypeString,
@@ -83,7 +93,6 @@ func validateTokenType(val interface{}, key string) (warns []string, errs []erro
 }
 
 func parseToken(d *schema.ResourceData, meta interface{}) (apiTokenDefinition, error) {
-	log.Printf("[DEBUG] create or update token with data %+v \n", d)
 	name := d.Get("name").(string)
 	tokenType := d.Get("type").(string)
 	description := d.Get("description").(string)
@@ -97,22 +106,22 @@ func parseToken(d *schema.ResourceData, meta interface{}) (apiTokenDefinition, e
 		Value:       value,
 	}
 
-	log.Printf("[DEBUG] Have a parsed token %+v \n", token)
+	log.Println("[DEBUG] have a parsed token", token.UUID, token.Type)
 
 	return token, nil
 }
 
 // Basically just does a regenerate
-func tokenCreate(d *schema.ResourceData, meta interface{}) error {
-	client := meta.(*client.Client)
+func tokenCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	client := meta.(client.BrokerAPI)
 	token, _ := parseToken(d, meta)
 
 	// If token UUID is empty, read from remote
 	if token.UUID == "" {
 		log.Println("[DEBUG] importing resource as no existing UUID was found")
-		t, err := client.FindTokenByType(token.Type)
+		t, err := client.FindTokenByTypeWithContext(ctx, token.Type)
 		if err != nil {
-			return err
+			return brokerDiagnostics(d, "Unable to find token", err)
 		}
 		token.UUID = t.UUID
 		// TF definition specific fields
@@ -121,53 +130,64 @@ func tokenCreate(d *schema.ResourceData, meta interface{}) error {
 		d.Set("name", token.Name)
 
 		// Set the (remote state) resource specific fields
-		return setTokenState(d, *t)
+		setTokenState(d, *t)
 	}
 
 	return nil
 }
 
 // Regenerate
-func tokenUpdate(d *schema.ResourceData, meta interface{}) error {
-	client := meta.(*client.Client)
+func tokenUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	client := meta.(client.BrokerAPI)
 	token, _ := parseToken(d, meta)
 
-	log.Println("[DEBUG] updating (regenearting) token", token)
+	log.Println("[DEBUG] updating (regenerating) token", token.UUID)
+
+	updatedToken, err := client.RegenerateTokenWithContext(ctx, broker.APIToken{UUID: token.UUID})
+
+	if err != nil {
+		return brokerDiagnostics(d, "Unable to regenerate token", err)
+	}
 
-	updatedToken, err := client.RegenerateToken(broker.APIToken{UUID: token.UUID})
+	var diags diag.Diagnostics
 
 	// At the moment, if you regenerate the access token - you need to use it for new requests!
 	if token.Type == readWriteTokenType {
-		if client.Config.AccessToken != "" {
+		if client.RotateAccessToken(updatedToken.Value) {
 			log.Println("[INFO] updating access token as read-write token was re-generated")
-			client.Config.AccessToken = updatedToken.Value
+		} else {
+			log.Println("[WARN] read-write token was re-generated, the provider must be configured with the new token")
+			diags = append(diags, diag.Diagnostic{Severity: diag.Warning, Summary: staleTokenSummary, Detail: staleTokenDetail})
 		}
 	}
 
-	if err != nil {
-		return err
-	}
-
 	// TF definition specific fields
 	d.Set("type", token.Type)
 	d.Set("name", token.Name)
+	setTokenState(d, updatedToken.APIToken)
 
-	return setTokenState(d, updatedToken.APIToken)
+	return diags
 }
 
-func tokenRead(d *schema.ResourceData, meta interface{}) error {
-	httpClient := meta.(*client.Client)
+func tokenRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	httpClient := meta.(client.BrokerAPI)
 	uuid := d.Id()
 
-	token, err := httpClient.ReadToken(uuid)
+	token, err := httpClient.ReadTokenWithContext(ctx, uuid)
+	if removeIfNotFound(d, "token", err) {
+		return nil
+	}
 	if err != nil {
-		return err
+		return brokerDiagnostics(d, "Unable to read token", err)
 	}
-	return setTokenState(d, *token)
+
+	setTokenState(d, *token)
+
+	return nil
 }
 
 // Uncouples from broker
-func tokenDelete(d *schema.ResourceData, meta interface{}) error {
+func tokenDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 
 	log.Println("[INFO] Deleting API token is currently a no-op, setting id to ''")
 	d.SetId("")
@@ -176,7 +196,7 @@ func tokenDelete(d *schema.ResourceData, meta interface{}) error {
 }
 
 func setTokenState(d *schema.ResourceData, token broker.APIToken) error {
-	log.Printf("[DEBUG] setting token state: %+v \n", token)
+	log.Println("[DEBUG] setting token state", token.UUID)
 
 	d.SetId(token.UUID)
 	d.Set("description", token.Description)
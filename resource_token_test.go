@@ -0,0 +1,68 @@
+package main
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/terraform"
+)
+
+// Tokens can't be created or deleted, so pact_token adopts the token of the given type, and regenerates it on update
+func TestTokenLifecycle(t *testing.T) {
+	fake := fakeBroker(t).Fake
+	var original string
+
+	resource.UnitTest(t, resource.TestCase{
+		ProtoV6ProviderFactories: testProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+resource "pact_token" "ci" {
+  type = "read-only"
+  name = "CI token"
+}`,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("pact_token.ci", "description", "Read only token (developer)"),
+					func(s *terraform.State) error {
+						state := s.RootModule().Resources["pact_token.ci"].Primary
+						token, err := fake.ReadToken(state.ID)
+						if err != nil {
+							return err
+						}
+						if token.Value != state.Attributes["value"] {
+							return fmt.Errorf("expected the value of token %s to be adopted", state.ID)
+						}
+						original = token.Value
+						return nil
+					},
+				),
+			},
+			{
+				Config: `
+resource "pact_token" "ci" {
+  type = "read-only"
+  name = "Local dev token"
+}`,
+				Check: func(s *terraform.State) error {
+					state := s.RootModule().Resources["pact_token.ci"].Primary
+					token, err := fake.ReadToken(state.ID)
+					if err != nil {
+						return err
+					}
+					if token.Value == original || token.Value != state.Attributes["value"] {
+						return fmt.Errorf("expected token %s to be regenerated", state.ID)
+					}
+					return nil
+				},
+			},
+			{
+				ResourceName:      "pact_token.ci",
+				ImportState:       true,
+				ImportStateVerify: true,
+				// The name and type are only known to the configuration
+				ImportStateVerifyIgnore: []string{"name", "type"},
+			},
+		},
+	})
+}
@@ -1,21 +1,30 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"log"
 
-	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/customdiff"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/pactflow/terraform/broker"
 	"github.com/pactflow/terraform/client"
 )
 
 func user() *schema.Resource {
 	return &schema.Resource{
-		Create:   userCreate,
-		Update:   userUpdate,
-		Read:     userRead,
-		Delete:   userDelete,
-		Importer: &schema.ResourceImporter{State: schema.ImportStatePassthrough},
+		CreateContext: stoppable(userCreate),
+		UpdateContext: stoppable(userUpdate),
+		ReadContext:   stoppable(userRead),
+		DeleteContext: stoppable(userDelete),
+		CustomizeDiff: customdiff.All(
+			requirePactflow("pact_user"),
+			validateReferences(reference{"roles", roleReference}),
+		),
+		Timeouts: defaultTimeouts(),
+		Identity: resourceIdentity(),
+		Importer: naturalKeyImporter("pact_user", findUsers, "email", "name"),
 		Schema: map[string]*schema.Schema{
 			"name": {
 				Type:        schema.TypeString,
@@ -41,7 +50,7 @@ func user() *schema.Resource {
 			},
 			"type": {
 				Type:         schema.TypeString,
-				Default:      allowedUserTypes[userType],
+				Default:      userType,
 				Description:  "The type of user (regular/system)",
 				Optional:     true,
 				ValidateFunc: validateUserType,
@@ -91,8 +100,8 @@ func validateUserType(val interface{}, key string) (warns []string, errs []error
 	return
 }
 
-func userCreate(d *schema.ResourceData, meta interface{}) error {
-	client := meta.(*client.Client)
+func userCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	client := meta.(client.BrokerAPI)
 	user := getUserFromState(d)
 
 	roles := ExpandStringSet(d.Get("roles").(*schema.Set))
@@ -101,13 +110,13 @@ func userCreate(d *schema.ResourceData, meta interface{}) error {
 	var created *broker.User
 	var err error
 	if user.Type == broker.SystemAccount {
-		created, err = client.CreateSystemAccount(user)
+		created, err = client.CreateSystemAccountWithContext(ctx, user)
 	} else {
-		created, err = client.CreateUser(user)
+		created, err = client.CreateUserWithContext(ctx, user)
 	}
 
 	if err != nil {
-		return err
+		return brokerDiagnostics(d, "Unable to create user", err)
 	}
 
 	d.SetId(created.UUID)
@@ -116,7 +125,7 @@ func userCreate(d *schema.ResourceData, meta interface{}) error {
 
 	log.Println("[DEBUG] updating user roles", d.Id(), roles)
 
-	err = client.SetUserRoles(d.Id(), broker.SetUserRolesRequest{
+	err = client.SetUserRolesWithContext(ctx, d.Id(), broker.SetUserRolesRequest{
 		Roles: roles,
 	})
 
@@ -124,7 +133,7 @@ func userCreate(d *schema.ResourceData, meta interface{}) error {
 		// Creating a user is a non-atomic transaction, because roles is a separate API call
 		d.Partial(true)
 		log.Println("[ERROR] error updating user roles", err)
-		return fmt.Errorf("error updating roles for user (%s / %s): %w", d.Id(), created.Email, err)
+		return brokerDiagnostics(d, fmt.Sprintf("Unable to set the roles of user %s", created.Email), err)
 	}
 
 	d.Set("roles", roles)
@@ -132,67 +141,77 @@ func userCreate(d *schema.ResourceData, meta interface{}) error {
 	return nil
 }
 
-func userUpdate(d *schema.ResourceData, meta interface{}) error {
-	client := meta.(*client.Client)
+func userUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	client := meta.(client.BrokerAPI)
 	user := getUserFromState(d)
+	// The roles are read before the state is set from the updated user, which would replace them with the current roles
+	rolesChanged := d.HasChange("roles")
+	roles := rolesFromStateChange(d)
 
 	log.Println("[DEBUG] updating user", user)
 
-	updated, err := client.UpdateUser(user)
+	updated, err := client.UpdateUserWithContext(ctx, user)
 
 	if err != nil {
-		return err
+		return brokerDiagnostics(d, "Unable to update user", err)
 	}
 
 	setUserState(d, *updated)
 
-	if d.HasChange("roles") {
-		roles := rolesFromStateChange(d)
+	if rolesChanged {
 		log.Println("[DEBUG] updating user roles", roles)
 
-		err = client.SetUserRoles(d.Id(), broker.SetUserRolesRequest{
+		err = client.SetUserRolesWithContext(ctx, d.Id(), broker.SetUserRolesRequest{
 			Roles: roles,
 		})
 
 		if err != nil {
 			d.Partial(true) // updating users is non-atomic, let the diff applier know this
 			log.Println("[ERROR] error updating user roles", err)
-			return fmt.Errorf("error updating roles for user (%s): %s", d.Id(), err)
+			return brokerDiagnostics(d, fmt.Sprintf("Unable to set the roles of user %s", updated.Email), err)
 		}
 
 		d.Set("roles", roles)
 	}
 
-	return err
+	return nil
 }
 
-func userRead(d *schema.ResourceData, meta interface{}) error {
-	client := meta.(*client.Client)
+func userRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	client := meta.(client.BrokerAPI)
 	uuid := d.Id()
 
 	log.Println("[DEBUG] reading user", uuid)
 
-	user, err := client.ReadUser(uuid)
+	user, err := client.ReadUserWithContext(ctx, uuid)
 
-	if err == nil {
-		d.SetId(user.UUID)
-		setUserState(d, *user)
+	if removeIfNotFound(d, "user", err) {
+		return nil
 	}
 
-	return err
+	if err != nil {
+		return brokerDiagnostics(d, "Unable to read user", err)
+	}
+
+	d.SetId(user.UUID)
+	if err = setUserState(d, *user); err != nil {
+		return stateDiagnostics("user", err)
+	}
+
+	return nil
 }
 
-func userDelete(d *schema.ResourceData, meta interface{}) error {
-	client := meta.(*client.Client)
+func userDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	client := meta.(client.BrokerAPI)
 	uuid := d.Id()
 
 	log.Println("[DEBUG] deleting user", d.Id())
 
 	// TODO: Delete attached resources Roles and Teams, because a Users aren't deleted, but simply disabled
-	user, err := client.ReadUser(uuid)
+	user, err := client.ReadUserWithContext(ctx, uuid)
 	if err != nil {
 		log.Println("[ERROR] unable to fetch user for delete", user)
-		return fmt.Errorf("unable to fetch user for delete: %w", err)
+		return brokerDiagnostics(d, "Unable to read user for delete", err)
 	}
 	log.Println("[DEBUG] have user for delete", user)
 
@@ -201,28 +220,28 @@ func userDelete(d *schema.ResourceData, meta interface{}) error {
 		rolesToRemove[i] = r.UUID
 	}
 
-	err = client.SetUserRoles(uuid, broker.SetUserRolesRequest{
+	err = client.SetUserRolesWithContext(ctx, uuid, broker.SetUserRolesRequest{
 		Roles: []string{},
 	})
 
 	if err != nil {
-		return fmt.Errorf("unable to remove roles from user when deleting (disabling) user %s: %w", d.Id(), err)
+		return brokerDiagnostics(d, fmt.Sprintf("Unable to remove roles from user %s when deleting (disabling) them", user.Email), err)
 	}
 
 	for _, t := range user.Embedded.Teams {
-		err = client.DeleteTeamAssignment(t, *user)
+		err = client.DeleteTeamAssignmentWithContext(ctx, t, *user)
 		if err != nil {
-			return fmt.Errorf("unable to remove user %s (%s) from team %s (%s): %w", d.Id(), user.Email, t.UUID, t.Name, err)
+			return brokerDiagnostics(d, fmt.Sprintf("Unable to remove user %s from team %s", user.Email, t.Name), err)
 		}
 	}
 	user.Embedded.Roles = nil
 	user.Embedded.Teams = nil
 
-	err = client.DeleteUser(*user)
+	err = client.DeleteUserWithContext(ctx, *user)
 
 	if err != nil {
 		d.SetId("")
-		return fmt.Errorf("unable to delete (disable) user %s: %w", d.Id(), err)
+		return brokerDiagnostics(d, fmt.Sprintf("Unable to delete (disable) user %s", user.Email), err)
 	}
 
 	return nil
@@ -247,7 +266,7 @@ func setUserState(d *schema.ResourceData, user broker.User) error {
 		log.Println("[ERROR] error setting key 'uuid'", err)
 		return err
 	}
-	if err := d.Set("type", user.Type); err != nil {
+	if err := d.Set("type", userTypeAsString(user.Type)); err != nil {
 		log.Println("[ERROR] error setting key 'type'", err)
 		return err
 	}
@@ -0,0 +1,146 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/terraform"
+	"github.com/pactflow/terraform/broker"
+	"github.com/pactflow/terraform/client/clienttest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUserDelete(t *testing.T) {
+	setup := func(t *testing.T) (*clienttest.Fake, *schema.ResourceData, *broker.Team) {
+		f := clienttest.NewFake()
+		role, _ := f.CreateRole(broker.Role{Name: "Test Maintainer"})
+		jane, _ := f.CreateUser(broker.User{Name: "Jane", Email: "jane@example.com", Active: true})
+		assert.NoError(t, f.SetUserRoles(jane.UUID, broker.SetUserRolesRequest{Roles: []string{role.UUID}}))
+		team, _ := f.CreateTeam(broker.TeamCreateOrUpdateRequest{Name: "Team A"})
+		_, err := f.UpdateTeamAssignments(broker.TeamsAssignmentRequest{UUID: team.UUID, Users: []string{jane.UUID}})
+		assert.NoError(t, err)
+
+		d := schema.TestResourceDataRaw(t, user().Schema, map[string]interface{}{
+			"name":  "Jane",
+			"email": "jane@example.com",
+		})
+		d.SetId(jane.UUID)
+
+		return f, d, team
+	}
+
+	t.Run("removes roles and teams, then disables the user", func(t *testing.T) {
+		f, d, team := setup(t)
+
+		diags := userDelete(context.Background(), d, f)
+
+		assert.False(t, diags.HasError())
+		res, _ := f.ReadUser(d.Id())
+		assert.False(t, res.Active)
+		assert.Empty(t, res.Embedded.Roles)
+		assert.Empty(t, res.Embedded.Teams)
+		members, _ := f.ReadTeamAssignments(*team)
+		assert.Empty(t, members.Embedded.Users)
+	})
+
+	t.Run("does not disable the user if their roles can't be removed", func(t *testing.T) {
+		f, d, _ := setup(t)
+		f.Errors["SetUserRoles"] = errors.New("forbidden")
+
+		diags := userDelete(context.Background(), d, f)
+
+		assert.True(t, diags.HasError())
+		assert.Contains(t, diags[0].Summary, "Unable to remove roles")
+		assert.Equal(t, "forbidden", diags[0].Detail)
+		res, _ := f.ReadUser(d.Id())
+		assert.True(t, res.Active)
+		assert.NotContains(t, f.Calls, "DeleteUser")
+	})
+}
+
+func TestUserLifecycle(t *testing.T) {
+	fake := fakeBroker(t).Fake
+	var uuid string
+
+	resource.UnitTest(t, resource.TestCase{
+		ProtoV6ProviderFactories: testProviderFactories,
+		// Users can't be deleted, so are disabled instead
+		CheckDestroy: func(*terraform.State) error {
+			user, err := fake.ReadUser(uuid)
+			if err != nil {
+				return err
+			}
+			if user.Active {
+				return fmt.Errorf("user %s is still active", uuid)
+			}
+			return nil
+		},
+		Steps: []resource.TestStep{
+			{
+				Config: `
+resource "pact_role" "maintainer" {
+  name   = "CI Maintainer"
+  scopes = ["contract_data:manage:*"]
+}
+
+resource "pact_user" "billy" {
+  name  = "Billy Sampson"
+  email = "billy@sampson.co"
+  roles = [pact_role.maintainer.uuid]
+}`,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("pact_user.billy", "type", "user"),
+					resource.TestCheckResourceAttr("pact_user.billy", "active", "true"),
+					func(s *terraform.State) error {
+						uuid = s.RootModule().Resources["pact_user.billy"].Primary.ID
+						user, err := fake.ReadUser(uuid)
+						if err != nil {
+							return err
+						}
+						if len(user.Embedded.Roles) != 1 || user.Embedded.Roles[0].Name != "CI Maintainer" {
+							return fmt.Errorf("expected the user to have the CI Maintainer role, got %+v", user.Embedded.Roles)
+						}
+						return nil
+					},
+				),
+			},
+			{
+				Config: `
+resource "pact_role" "maintainer" {
+  name   = "CI Maintainer"
+  scopes = ["contract_data:manage:*"]
+}
+
+resource "pact_user" "billy" {
+  name   = "Billy Sampson-Smith"
+  email  = "billy@sampson.co"
+  active = false
+  roles  = ["` + clienttest.AdministratorRoleUUID + `"]
+}`,
+				Check: func(*terraform.State) error {
+					user, err := fake.ReadUser(uuid)
+					if err != nil {
+						return err
+					}
+					if user.Name != "Billy Sampson-Smith" || user.Active {
+						return fmt.Errorf("the user was not updated in the broker: %+v", user)
+					}
+					if len(user.Embedded.Roles) != 1 || user.Embedded.Roles[0].UUID != clienttest.AdministratorRoleUUID {
+						return fmt.Errorf("expected the user to have the Administrator role, got %+v", user.Embedded.Roles)
+					}
+					return nil
+				},
+			},
+			{
+				ResourceName:      "pact_user.billy",
+				ImportState:       true,
+				ImportStateId:     "email=billy@sampson.co",
+				ImportStateVerify: true,
+			},
+		},
+	})
+}
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"log"
@@ -10,7 +11,9 @@ import (
 	"sort"
 	"strings"
 
-	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
 	"github.com/mitchellh/mapstructure"
 	"github.com/pactflow/terraform/broker"
 	"github.com/pactflow/terraform/client"
@@ -30,16 +33,9 @@ var pacticipantType = &schema.Schema{
 	Optional: true,
 	Computed: true,
 	ForceNew: true,
-	Elem: &schema.Resource{
-		Schema: map[string]*schema.Schema{
-			"name": {
-				Type:        schema.TypeString,
-				Required:    true,
-				ForceNew:    true,
-				Description: "A short description of the webhook",
-			},
-		},
-	},
+	Elem:     &schema.Schema{Type: schema.TypeString},
+	// The pacticipant is given by name, e.g. { name = "my-provider" }
+	ValidateDiagFunc: validation.MapKeyMatch(regexp.MustCompile(`^name$`), "only the name of the pacticipant may be given"),
 }
 
 var eventsType = &schema.Schema{
@@ -130,11 +126,18 @@ func validateMethod(val interface{}, key string) (warns []string, errs []error)
 
 func webhook() *schema.Resource {
 	return &schema.Resource{
-		Create:   webhookCreate,
-		Update:   webhookUpdate,
-		Read:     webhookRead,
-		Delete:   webhookDelete,
-		Importer: &schema.ResourceImporter{State: schema.ImportStatePassthrough},
+		CreateContext: stoppable(webhookCreate),
+		UpdateContext: stoppable(webhookUpdate),
+		ReadContext:   stoppable(webhookRead),
+		DeleteContext: stoppable(webhookDelete),
+		CustomizeDiff: validateReferences(
+			reference{"webhook_provider", pacticipantReference},
+			reference{"webhook_consumer", pacticipantReference},
+			reference{"team", teamReference},
+		),
+		Timeouts: defaultTimeouts(),
+		Identity: resourceIdentity(),
+		Importer: naturalKeyImporter("pact_webhook", findWebhooks, "description", "consumer", "provider", "events", "team"),
 		Schema: map[string]*schema.Schema{
 			"description": {
 				Type:     schema.TypeString,
@@ -165,8 +168,6 @@ func parseWebhook(d *schema.ResourceData, meta interface{}) (broker.Webhook, err
 		Events:  []broker.WebhookEvent{},
 	}
 
-	log.Printf("[DEBUG] create or update webhook with data %+v \n", d)
-
 	webhook.Description = d.Get("description").(string)
 
 	// Team
@@ -220,11 +221,8 @@ func parseWebhook(d *schema.ResourceData, meta interface{}) (broker.Webhook, err
 	// Request
 	log.Println("[DEBUG] checking request")
 	if rawRequest, ok := d.GetOk("request"); ok {
-		log.Printf("[DEBUG] have raw request of %+v \n", rawRequest)
-
 		rawRequestList := rawRequest.([]interface{})
 		requestMap := rawRequestList[0].(map[string]interface{})
-		log.Printf("[DEBUG] have converted request %+v \n", requestMap)
 
 		// Method
 		if method, ok := requestMap["method"]; ok {
@@ -251,7 +249,7 @@ func parseWebhook(d *schema.ResourceData, meta interface{}) (broker.Webhook, err
 			request.Headers = make(map[string]string)
 			if headers, ok := headers.(map[string]interface{}); ok {
 				for k, v := range headers {
-					fmt.Println("[DEBUG] Key", k, "Value", v, "Type", reflect.TypeOf(v))
+					log.Println("[DEBUG] have webhook header", k, "of type", reflect.TypeOf(v))
 					request.Headers[k] = v.(string)
 				}
 			} else {
@@ -278,8 +276,6 @@ func parseWebhook(d *schema.ResourceData, meta interface{}) (broker.Webhook, err
 			}
 		}
 
-		log.Printf("[DEBUG] have fully serialised request %+v \n", request)
-
 		webhook.Request = *request
 	} else {
 		log.Println("[ERROR] request attribute not found")
@@ -295,7 +291,7 @@ func parseWebhook(d *schema.ResourceData, meta interface{}) (broker.Webhook, err
 }
 
 func setWebhookState(d *schema.ResourceData, webhook broker.Webhook) error {
-	log.Printf("[DEBUG] setting webhook state: %+v \n", webhook)
+	log.Println("[DEBUG] setting webhook state", webhook.ID, webhook.Description)
 	if err := d.Set("description", webhook.Description); err != nil {
 		log.Println("[ERROR] error setting key 'description'", err)
 		return err
@@ -402,77 +398,84 @@ func mapStringStringToMapStringInterface(in map[string]string) map[string]interf
 	return out
 }
 
-func webhookCreate(d *schema.ResourceData, meta interface{}) error {
-	httpClient := meta.(*client.Client)
+func webhookCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	httpClient := meta.(client.BrokerAPI)
 	webhook, err := parseWebhook(d, meta)
 	if err != nil {
-		return err
+		return brokerDiagnostics(d, "Invalid webhook configuration", err)
 	}
 
-	res, err := httpClient.CreateWebhook(webhook)
-	log.Printf("[DEBUG] response from creating webhook %+v\n", res)
+	res, err := httpClient.CreateWebhookWithContext(ctx, webhook)
+	if err != nil {
+		log.Println("[ERROR] webhook creation failed", err)
+		d.SetId("")
+		return brokerDiagnostics(d, "Unable to create webhook", err)
+	}
 
-	if err == nil {
-		items := strings.Split(res.Links["self"].Href, "/")
-		id := items[len(items)-1]
-		d.SetId(id)
+	items := strings.Split(res.Links["self"].Href, "/")
+	id := items[len(items)-1]
+	d.SetId(id)
 
-		return setWebhookState(d, webhook)
+	if err = setWebhookState(d, webhook); err != nil {
+		return stateDiagnostics("webhook", err)
 	}
 
-	log.Println("[ERROR] webhook creation failed", err)
-	d.SetId("")
-	return err
+	return nil
 }
 
-func webhookUpdate(d *schema.ResourceData, meta interface{}) error {
-	httpClient := meta.(*client.Client)
+func webhookUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	httpClient := meta.(client.BrokerAPI)
 	webhook, err := parseWebhook(d, meta)
 	if err != nil {
-		return err
+		return brokerDiagnostics(d, "Invalid webhook configuration", err)
 	}
 
-	res, err := httpClient.UpdateWebhook(webhook)
-	log.Printf("[DEBUG] response from updating webhook %+v\n", res)
-
+	res, err := httpClient.UpdateWebhookWithContext(ctx, webhook)
 	if err != nil {
-		log.Println("[ERROR] webhook creation failed", err)
-		d.SetId("")
+		log.Println("[ERROR] webhook update failed", err)
+		return brokerDiagnostics(d, "Unable to update webhook", err)
 	}
 	d.Set("webhook", res)
 
 	return nil
 }
 
-func webhookRead(d *schema.ResourceData, meta interface{}) error {
-	httpClient := meta.(*client.Client)
-	res, err := httpClient.ReadWebhook(d.Id())
-	log.Printf("[DEBUG] response from reading webhook %+v\n", res)
+func webhookRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	httpClient := meta.(client.BrokerAPI)
+	res, err := httpClient.ReadWebhookWithContext(ctx, d.Id())
+	if removeIfNotFound(d, "webhook", err) {
+		return nil
+	}
 
 	if err != nil {
 		log.Println("[ERROR] webhook read failed", err)
-		d.SetId("")
-		return nil
+		return brokerDiagnostics(d, "Unable to read webhook", err)
+	}
+
+	if err = setWebhookState(d, *res); err != nil {
+		return stateDiagnostics("webhook", err)
 	}
-	return setWebhookState(d, *res)
+
+	return nil
 }
 
-func webhookDelete(d *schema.ResourceData, meta interface{}) error {
-	log.Printf("[DEBUG] deleting webhook with data %+v\n", d)
-	httpClient := meta.(*client.Client)
+func webhookDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	httpClient := meta.(client.BrokerAPI)
 	webhook, err := parseWebhook(d, meta)
 	if err != nil {
-		return err
+		return brokerDiagnostics(d, "Invalid webhook configuration", err)
 	}
 
-	log.Println("[DEBUG] deleting webhook", webhook)
+	log.Println("[DEBUG] deleting webhook", webhook.ID)
 
-	err = httpClient.DeleteWebhook(webhook)
-	if err == nil {
-		d.SetId("")
+	err = httpClient.DeleteWebhookWithContext(ctx, webhook)
+	if err != nil {
+		return brokerDiagnostics(d, "Unable to delete webhook", err)
 	}
 
-	return err
+	d.SetId("")
+
+	return nil
 }
 
 func tryParseJSONObject(s string) interface{} {
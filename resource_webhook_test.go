@@ -0,0 +1,117 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/terraform"
+	"github.com/pactflow/terraform/client"
+)
+
+func TestWebhookLifecycle(t *testing.T) {
+	fake := fakeBroker(t).Fake
+	var id string
+
+	pacticipants := `
+resource "pact_pacticipant" "admin" {
+  name = "AdminService"
+}
+
+resource "pact_pacticipant" "product" {
+  name = "ProductService"
+}
+`
+
+	resource.UnitTest(t, resource.TestCase{
+		ProtoV6ProviderFactories: testProviderFactories,
+		CheckDestroy: func(*terraform.State) error {
+			if _, err := fake.ReadWebhook(id); !errors.Is(err, client.ErrNotFound) {
+				return fmt.Errorf("webhook %s still exists", id)
+			}
+			return nil
+		},
+		Steps: []resource.TestStep{
+			{
+				Config: pacticipants + `
+resource "pact_webhook" "product_events" {
+  description = "Trigger a build of ProductService"
+  webhook_provider = {
+    name = pact_pacticipant.product.name
+  }
+  webhook_consumer = {
+    name = pact_pacticipant.admin.name
+  }
+  request {
+    url    = "https://ci.example.com/builds"
+    method = "POST"
+    headers = {
+      "Content-Type" = "application/json"
+    }
+    body = jsonencode({ pact = "$${pactbroker.pactUrl}" })
+  }
+  events = ["contract_published"]
+}`,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("pact_webhook.product_events", "request.0.headers.Content-Type", "application/json"),
+					func(s *terraform.State) error {
+						id = s.RootModule().Resources["pact_webhook.product_events"].Primary.ID
+						webhook, err := fake.ReadWebhook(id)
+						if err != nil {
+							return err
+						}
+						if webhook.Provider == nil || webhook.Provider.Name != "ProductService" || webhook.Consumer == nil || webhook.Consumer.Name != "AdminService" {
+							return fmt.Errorf("unexpected pacticipants in the broker: %+v, %+v", webhook.Provider, webhook.Consumer)
+						}
+						return nil
+					},
+				),
+			},
+			{
+				Config: pacticipants + `
+resource "pact_webhook" "product_events" {
+  description = "Trigger builds of ProductService"
+  webhook_provider = {
+    name = pact_pacticipant.product.name
+  }
+  webhook_consumer = {
+    name = pact_pacticipant.admin.name
+  }
+  request {
+    url      = "https://ci.example.com/builds/product"
+    method   = "PUT"
+    username = "ci"
+    password = "password1"
+    headers = {
+      "Content-Type" = "application/json"
+      "X-Build"      = "$${pactbroker.consumerVersionNumber}"
+    }
+  }
+  events = ["contract_published", "contract_content_changed"]
+}`,
+				Check: func(*terraform.State) error {
+					webhook, err := fake.ReadWebhook(id)
+					if err != nil {
+						return err
+					}
+					if webhook.Description != "Trigger builds of ProductService" {
+						return fmt.Errorf("the webhook was not updated in the broker: %+v", webhook)
+					}
+					if webhook.Request.Method != "PUT" || webhook.Request.URL != "https://ci.example.com/builds/product" || len(webhook.Events) != 2 {
+						return fmt.Errorf("the webhook request was not updated in the broker: %+v", webhook.Request)
+					}
+					return nil
+				},
+			},
+			{
+				ResourceName:      "pact_webhook.product_events",
+				ImportState:       true,
+				ImportStateId:     "description=Trigger builds of ProductService",
+				ImportStateVerify: true,
+				// As with the broker, the fake never returns the password of a webhook
+				ImportStateVerifyIgnore: []string{"request.0.password"},
+			},
+		},
+	})
+}